@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -14,14 +15,43 @@ type Config struct {
 }
 
 type TelegramConfig struct {
-	Debug                bool   `envconfig:"DEBUG" default:"false"`
-	APIToken             string `envconfig:"API_TOKEN" required:"true"`
-	AdminID              int64  `envconfig:"ADMIN_ID"`
-	AdminUsername        string `envconfig:"ADMIN_USERNAME"`
-	ChiefPasswordHash    string `envconfig:"CHIEF_PASSWORD_HASH"`
-	ExecutorPasswordHash string `envconfig:"EXECUTOR_PASSWORD_HASH"`
-	ObserverPasswordHash string `envconfig:"OBSERVER_PASSWORD_HASH"`
-	AdminPasswordHash    string `envconfig:"ADMIN_PASSWORD_HASH"`
+	Debug                bool          `envconfig:"DEBUG" default:"false"`
+	APIToken             string        `envconfig:"API_TOKEN" required:"true"`
+	AdminID              int64         `envconfig:"ADMIN_ID"`
+	AdminUsername        string        `envconfig:"ADMIN_USERNAME"`
+	ChiefPasswordHash    string        `envconfig:"CHIEF_PASSWORD_HASH"`
+	ExecutorPasswordHash string        `envconfig:"EXECUTOR_PASSWORD_HASH"`
+	ObserverPasswordHash string        `envconfig:"OBSERVER_PASSWORD_HASH"`
+	AdminPasswordHash    string        `envconfig:"ADMIN_PASSWORD_HASH"`
+	RequestContact       bool          `envconfig:"REQUEST_CONTACT" default:"false"`
+	InviteTTL            time.Duration `envconfig:"INVITE_TTL" default:"168h"`
+	PasswordMaxAttempts  int           `envconfig:"PASSWORD_MAX_ATTEMPTS" default:"5"`
+	PasswordLockout      time.Duration `envconfig:"PASSWORD_LOCKOUT" default:"1m"`
+	PasswordMaxLockout   time.Duration `envconfig:"PASSWORD_MAX_LOCKOUT" default:"24h"`
+	// TaskDoneMode is "any" when task is done after any of its executors finishes it and "all" when every executor has to
+	TaskDoneMode string `envconfig:"TASK_DONE_MODE" default:"any"`
+	// CommandPermissions overrides roles allowed to use commands, e.g. "add_task:chief|admin,get_role:guest|executor"
+	CommandPermissions map[string]string `envconfig:"COMMAND_PERMISSIONS"`
+	// PrioritySLA is the default deadline of new tasks by their priority
+	PrioritySLA map[string]time.Duration `envconfig:"PRIORITY_SLA" default:"low:168h,normal:72h,high:24h,critical:4h"`
+	// ReminderIntervals is how often executors are reminded about open tasks by priority, 0s disables reminders
+	ReminderIntervals map[string]time.Duration `envconfig:"REMINDER_INTERVALS" default:"low:0s,normal:24h,high:8h,critical:2h"`
+	// ExpiredDigestSchedule is when observers get the digest of expired low priority tasks, e.g. "daily 09:00"
+	ExpiredDigestSchedule string `envconfig:"EXPIRED_DIGEST_SCHEDULE" default:"daily 09:00"`
+	// FilesDir keeps local copies of task attachments, empty disables the cache
+	FilesDir string `envconfig:"FILES_DIR"`
+	// DashboardInterval is how often pinned dashboards are refreshed besides task changes, 0s disables the refresh
+	DashboardInterval time.Duration `envconfig:"DASHBOARD_INTERVAL" default:"1m"`
+	// WebhookURL turns on the webhook mode instead of long polling, Telegram sends updates to it
+	WebhookURL string `envconfig:"WEBHOOK_URL"`
+	// WebhookListen is the address the webhook server listens on, the path is taken from WebhookURL
+	WebhookListen string `envconfig:"WEBHOOK_LISTEN" default:":8080"`
+	// WebhookSecret is compared with X-Telegram-Bot-Api-Secret-Token header of webhook requests, it is required in the webhook mode
+	WebhookSecret string `envconfig:"WEBHOOK_SECRET"`
+	// SendRate is the most requests per second sent to Telegram, the limit of Telegram is about 30
+	SendRate int `envconfig:"SEND_RATE" default:"30"`
+	// ChatSendInterval is the least interval between requests to one chat, 0s disables the limit
+	ChatSendInterval time.Duration `envconfig:"CHAT_SEND_INTERVAL" default:"1s"`
 }
 
 type PostgresConfig struct {
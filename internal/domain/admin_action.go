@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	SetRoleAction    = "set_role"
+	RevokeRoleAction = "revoke_role"
+	BanAction        = "ban"
+	UnbanAction      = "unban"
+	ResetStageAction = "reset_stage"
+	UnlockAction     = "unlock"
+	SetChiefAction   = "set_chief"
+)
+
+// AdminAction is an audit record of a change made by admin to another chat.
+type AdminAction struct {
+	ID           int
+	TeamID       int64
+	AdminChatID  int64
+	TargetChatID int64
+	Action       string
+	Details      string
+	CreatedAt    time.Time
+}
+
+func (a AdminAction) String() string {
+	details := ""
+	if a.Details != "" {
+		details = fmt.Sprintf(" (%s)", a.Details)
+	}
+	return fmt.Sprintf("%s: %d → %s %d%s",
+		a.CreatedAt.Format(DeadlineLayout),
+		a.AdminChatID,
+		a.Action,
+		a.TargetChatID,
+		details,
+	)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type AttachmentKind int
+
+const (
+	UnknownAttachment AttachmentKind = iota
+	PhotoAttachment
+	DocumentAttachment
+)
+
+// Attachment is a file of the task kept in Telegram, TaskID is zero while the task it is sent for is being created.
+type Attachment struct {
+	ID     int
+	TaskID int
+	ChatID int64
+	Kind   AttachmentKind
+	// FileID is used to send the file again, FileUniqueID stays the same for the file across bots
+	FileID       string
+	FileUniqueID string
+	FileName     string
+	MimeType     string
+	FileSize     int
+	// LocalPath is the copy of the file in the local cache, empty when the cache is turned off
+	LocalPath string
+	CreatedAt time.Time
+}
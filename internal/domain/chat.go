@@ -1,9 +1,65 @@
 package domain
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Chat struct {
-	ID       int64
-	Username string
-	Phone    string
-	Stage    Stage
-	Role     Role
+	ID             int64
+	TeamID         int64
+	Username       string
+	Phone          string
+	Stage          Stage
+	Role           Role
+	Banned         bool
+	LastActivityAt time.Time
+}
+
+// ChatStats is a chat with aggregated information for admins.
+type ChatStats struct {
+	Chat
+	ManagerID int64
+	OpenTasks int
+}
+
+func (c ChatStats) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("<b>%d</b>", c.ID))
+	if c.Username != "" {
+		builder.WriteString(fmt.Sprintf(" @%s", c.Username))
+	}
+	if c.Phone != "" {
+		builder.WriteString(fmt.Sprintf(" %s", c.Phone))
+	}
+	if c.Banned {
+		builder.WriteString(" 🚫")
+	}
+
+	lastActivity := "нет"
+	if !c.LastActivityAt.IsZero() {
+		lastActivity = c.LastActivityAt.Format(DeadlineLayout)
+	}
+	builder.WriteString(fmt.Sprintf("\n<b>Роль:</b> %s", c.Role))
+	if c.ManagerID != 0 {
+		builder.WriteString(fmt.Sprintf("\n<b>Шеф:</b> %d", c.ManagerID))
+	}
+	builder.WriteString(fmt.Sprintf("\n<b>Активность:</b> %s\n<b>Открытых задач:</b> %d",
+		lastActivity,
+		c.OpenTasks,
+	))
+	return builder.String()
+}
+
+// Mention returns short human readable reference to the chat.
+func (c Chat) Mention() string {
+	switch {
+	case c.Username != "":
+		return fmt.Sprintf("@%s", c.Username)
+	case c.Phone != "":
+		return c.Phone
+	default:
+		return fmt.Sprintf("%d", c.ID)
+	}
 }
@@ -0,0 +1,32 @@
+package domain
+
+import "fmt"
+
+// ChecklistItem is a step of the task ticked off by executors.
+type ChecklistItem struct {
+	ID     int
+	TaskID int
+	Title  string
+	Done   bool
+}
+
+func (i ChecklistItem) String() string {
+	mark := "⬜"
+	if i.Done {
+		mark = "✅"
+	}
+	return fmt.Sprintf("%s %s", mark, i.Title)
+}
+
+// TaskProgress counts finished subtasks and checklist items of the task.
+type TaskProgress struct {
+	Subtasks     int
+	SubtasksDone int
+	Items        int
+	ItemsDone    int
+}
+
+// HasOpenSubtasks reports whether some of subtasks are neither done nor closed.
+func (p TaskProgress) HasOpenSubtasks() bool {
+	return p.SubtasksDone < p.Subtasks
+}
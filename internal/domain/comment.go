@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+// Comment is a message of the task discussion.
+type Comment struct {
+	ID     int
+	TaskID int
+	ChatID int64
+	// Author is the name of the chat shown with the comment, it is kept as it was when the comment was written
+	Author    string
+	Text      string
+	CreatedAt time.Time
+}
+
+func (c Comment) String() string {
+	return fmt.Sprintf("<b>%s</b> %s\n%s", html.EscapeString(c.Author), c.CreatedAt.Format(DeadlineLayout), html.EscapeString(c.Text))
+}
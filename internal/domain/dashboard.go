@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// dashboardGroupSize is the most tasks listed in each group of the dashboard, the rest are only counted
+	dashboardGroupSize = 5
+	// dashboardExecutors is the most executors listed on the dashboard, the most loaded go first
+	dashboardExecutors = 10
+)
+
+// Dashboard is the pinned message of the chat with the summary of tasks of the team, it is edited as tasks change.
+type Dashboard struct {
+	ChatID    int64
+	TeamID    int64
+	MessageID int
+}
+
+// executorLoad counts open tasks of one executor on the dashboard.
+type executorLoad struct {
+	contact string
+	open    int
+	overdue int
+}
+
+// DashboardText summarises tasks: counts by status, the most urgent overdue tasks and tasks due today,
+// open tasks by executor.
+func DashboardText(tasks []Task, now time.Time) string {
+	var overdue, today []Task
+	var done, closed int
+	loads := make(map[string]*executorLoad)
+	for _, task := range tasks {
+		switch {
+		case task.Closed:
+			closed++
+			continue
+		case task.Done:
+			done++
+			continue
+		}
+
+		load, ok := loads[task.ExecutorContact]
+		if !ok {
+			load = &executorLoad{contact: task.ExecutorContact}
+			loads[task.ExecutorContact] = load
+		}
+		load.open++
+		if task.Deadline.Before(now) {
+			load.overdue++
+			overdue = append(overdue, task)
+			continue
+		}
+		if year, month, day := task.Deadline.Date(); year == now.Year() && month == now.Month() && day == now.Day() {
+			today = append(today, task)
+		}
+	}
+	open := 0
+	for _, load := range loads {
+		open += load.open
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("📊 <b>Доска задач</b>, обновлено %s\n", now.Format(DeadlineLayout)))
+	builder.WriteString(fmt.Sprintf("Открыто: %d, просрочено: %d, на сегодня: %d, выполнено: %d, закрыто: %d", open, len(overdue), len(today), done, closed))
+
+	writeDashboardGroup(&builder, "⌛ Просрочено", overdue)
+	writeDashboardGroup(&builder, "📅 На сегодня", today)
+
+	if len(loads) > 0 {
+		executors := make([]*executorLoad, 0, len(loads))
+		for _, load := range loads {
+			executors = append(executors, load)
+		}
+		slices.SortFunc(executors, func(a, b *executorLoad) int {
+			return cmp.Or(cmp.Compare(b.overdue, a.overdue), cmp.Compare(b.open, a.open), strings.Compare(a.contact, b.contact))
+		})
+		builder.WriteString("\n\n<b>👤 По исполнителям</b>")
+		for _, load := range executors[:min(len(executors), dashboardExecutors)] {
+			builder.WriteString(fmt.Sprintf("\n%s: открыто %d", html.EscapeString(formatExecutorContact(load.contact)), load.open))
+			if load.overdue > 0 {
+				builder.WriteString(fmt.Sprintf(", просрочено %d", load.overdue))
+			}
+		}
+		if rest := len(executors) - dashboardExecutors; rest > 0 {
+			builder.WriteString(fmt.Sprintf("\n…и ещё %d", rest))
+		}
+	}
+	return builder.String()
+}
+
+// writeDashboardGroup lists the most important tasks of the group.
+func writeDashboardGroup(builder *strings.Builder, title string, tasks []Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	SortByPriority(tasks)
+	builder.WriteString(fmt.Sprintf("\n\n<b>%s (%d)</b>", title, len(tasks)))
+	for _, task := range tasks[:min(len(tasks), dashboardGroupSize)] {
+		builder.WriteString(fmt.Sprintf(
+			"\n%s №%d %s, %s, %s",
+			task.Priority.Emoji(), task.ID, html.EscapeString(task.Title), html.EscapeString(formatExecutorContact(task.ExecutorContact)), task.Deadline.Format(DeadlineLayout),
+		))
+	}
+	if rest := len(tasks) - dashboardGroupSize; rest > 0 {
+		builder.WriteString(fmt.Sprintf("\n…и ещё %d", rest))
+	}
+}
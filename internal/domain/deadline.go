@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/errs"
+	"time"
+)
+
+// weekdays are Russian names of days of week in nominative and accusative ("на пятницу") cases.
+var weekdays = map[string]time.Weekday{
+	"понедельник": time.Monday, "пн": time.Monday,
+	"вторник": time.Tuesday, "вт": time.Tuesday,
+	"среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+	"четверг": time.Thursday, "чт": time.Thursday,
+	"пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+	"воскресенье": time.Sunday, "вс": time.Sunday,
+}
+
+// relativeDays are days counted from today.
+var relativeDays = map[string]int{
+	"сегодня":     0,
+	"завтра":      1,
+	"послезавтра": 2,
+}
+
+// ParseDeadline parses the new deadline written by people, the time of day is kept from the current deadline
+// unless it is given:
+//
+//	21.12.2024 12:20:00, 21.12.2024 12:20, 21.12.2024, 21.12
+//	завтра, послезавтра 18:00, пятницу, на пн 10:00
+//	через 3 дня, через 2 часа, через неделю
+func ParseDeadline(raw string, current, now time.Time) (time.Time, error) {
+	fields := strings.Fields(strings.ToLower(raw))
+	if len(fields) > 0 && (fields[0] == "на" || fields[0] == "до") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return time.Time{}, fmt.Errorf("empty deadline: %w", errs.ErrInvalidInput)
+	}
+	text := strings.Join(fields, " ")
+
+	for _, layout := range []string{DeadlineLayout, "02.01.2006 15:04"} {
+		if deadline, err := time.ParseInLocation(layout, text, now.Location()); err == nil {
+			return deadline, nil
+		}
+	}
+
+	if fields[0] == "через" {
+		return parseDeadlineAfter(fields[1:], current, now)
+	}
+
+	day, clock := fields[0], current
+	switch len(fields) {
+	case 1:
+	case 2:
+		parsed, err := time.Parse("15:04", fields[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time of day %q: %w", fields[1], errs.ErrInvalidInput)
+		}
+		clock = parsed
+	default:
+		return time.Time{}, fmt.Errorf("unknown deadline %q: %w", raw, errs.ErrInvalidInput)
+	}
+
+	if days, ok := relativeDays[day]; ok {
+		return atClock(now.AddDate(0, 0, days), clock), nil
+	}
+	if weekday, ok := weekdays[day]; ok {
+		// the nearest such day after today, "на пятницу" in friday means the next one
+		days := (int(weekday)-int(now.Weekday())+6)%7 + 1
+		return atClock(now.AddDate(0, 0, days), clock), nil
+	}
+	if date, err := time.ParseInLocation("02.01.2006", day, now.Location()); err == nil {
+		return atClock(date, clock), nil
+	}
+	if date, err := time.ParseInLocation("02.01", day, now.Location()); err == nil {
+		deadline := atClock(time.Date(now.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location()), clock)
+		// date without year is the nearest one, in december "на 10.01" means the next year
+		if deadline.Before(now) {
+			deadline = deadline.AddDate(1, 0, 0)
+		}
+		return deadline, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown deadline %q: %w", raw, errs.ErrInvalidInput)
+}
+
+// parseDeadlineAfter parses "через" deadlines: hours are counted from now, days and weeks keep the time of day.
+func parseDeadlineAfter(fields []string, current, now time.Time) (time.Time, error) {
+	count := 1
+	if len(fields) == 2 {
+		var err error
+		if count, err = strconv.Atoi(fields[0]); err != nil || count <= 0 {
+			return time.Time{}, fmt.Errorf("invalid count %q: %w", fields[0], errs.ErrInvalidInput)
+		}
+		fields = fields[1:]
+	}
+	if len(fields) != 1 {
+		return time.Time{}, fmt.Errorf("unknown deadline %q: %w", strings.Join(fields, " "), errs.ErrInvalidInput)
+	}
+	switch fields[0] {
+	case "час", "часа", "часов":
+		return now.Add(time.Duration(count) * time.Hour).Truncate(time.Minute), nil
+	case "день", "дня", "дней":
+		return atClock(now.AddDate(0, 0, count), current), nil
+	case "неделю", "недели", "недель":
+		return atClock(now.AddDate(0, 0, 7*count), current), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown unit %q: %w", fields[0], errs.ErrInvalidInput)
+	}
+}
+
+// atClock returns the date with the time of day of the clock.
+func atClock(date, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, date.Location())
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"tasks_bot/internal/errs"
+	"unicode"
+)
+
+// TaskFilter selects tasks of the team, every set condition must hold.
+type TaskFilter struct {
+	TeamID int64
+	// ManagerID keeps only tasks of the chief's subordinates
+	ManagerID int64
+	// Executor keeps only tasks the chat executes, as the main executor or a co-executor
+	Executor *Chat
+	// Tags the task must have all of
+	Tags []string
+	// Priorities the task must have any of
+	Priorities []Priority
+	Open       bool
+	Done       bool
+	Closed     bool
+	// Overdue are unfinished tasks after their deadline, even if they are not marked expired yet
+	Overdue bool
+}
+
+// ParseTaskFilter parses filter query like "#склад open overdue high my". Hashtags are tags,
+// other words are statuses, priorities or "my" for the tasks of the chat.
+func ParseTaskFilter(query string, chat Chat) (TaskFilter, error) {
+	var filter TaskFilter
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(word, "#") {
+			tag := NormalizeTag(word)
+			if tag == "" {
+				return TaskFilter{}, fmt.Errorf("empty tag %q: %w", word, errs.ErrInvalidInput)
+			}
+			if !slices.Contains(filter.Tags, tag) {
+				filter.Tags = append(filter.Tags, tag)
+			}
+			continue
+		}
+		switch word {
+		case "open", "открытые":
+			filter.Open = true
+		case "done", "выполненные":
+			filter.Done = true
+		case "closed", "закрытые":
+			filter.Closed = true
+		case "overdue", "expired", "просроченные":
+			filter.Overdue = true
+		case "my", "мои":
+			filter.Executor = &chat
+		default:
+			priority, err := ParsePriority(word)
+			if err != nil {
+				return TaskFilter{}, fmt.Errorf("unknown filter word %q: %w", word, errs.ErrInvalidInput)
+			}
+			if !slices.Contains(filter.Priorities, priority) {
+				filter.Priorities = append(filter.Priorities, priority)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// NormalizeTag lowercases tag and strips leading # and trailing punctuation.
+func NormalizeTag(raw string) string {
+	tag := strings.TrimLeft(strings.ToLower(raw), "#")
+	return strings.TrimRightFunc(tag, func(r rune) bool { return !isTagRune(r) })
+}
+
+// ParseTags returns normalized hashtags of the text without duplicates, e.g. "Инвентаризация #склад" gives "склад".
+func ParseTags(text string) []string {
+	var tags []string
+	for _, word := range strings.Fields(text) {
+		if !strings.HasPrefix(word, "#") {
+			continue
+		}
+		tag := NormalizeTag(word)
+		if tag == "" || strings.ContainsFunc(tag, func(r rune) bool { return !isTagRune(r) }) {
+			continue
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+// FormatTags writes tags as hashtags separated by spaces.
+func FormatTags(tags []string) string {
+	hashtags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		hashtags = append(hashtags, "#"+tag)
+	}
+	return strings.Join(hashtags, " ")
+}
+
+// SavedView is a named filter query of the chat, the query is parsed every time the view is used,
+// so "my" always means the chat using the view.
+type SavedView struct {
+	TeamID int64
+	ChatID int64
+	Name   string
+	Query  string
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Invite is a single-use code granting a role. If Username is set, only that user can redeem it.
+type Invite struct {
+	Code       string
+	TeamID     int64
+	Role       Role
+	Username   string
+	CreatedBy  int64
+	RedeemedBy int64
+	RedeemedAt time.Time
+	ExpiresAt  time.Time
+	Revoked    bool
+}
+
+func (i Invite) IsActive(now time.Time) bool {
+	if i.Revoked || i.RedeemedBy != 0 {
+		return false
+	}
+	return i.ExpiresAt.IsZero() || now.Before(i.ExpiresAt)
+}
+
+// CanBeRedeemedBy reports whether the user may redeem the invite, Telegram usernames are case-insensitive.
+func (i Invite) CanBeRedeemedBy(username string) bool {
+	return i.Username == "" || strings.EqualFold(i.Username, username)
+}
+
+func (i Invite) String() string {
+	expiresAt := "бессрочно"
+	if !i.ExpiresAt.IsZero() {
+		expiresAt = i.ExpiresAt.Format(DeadlineLayout)
+	}
+	username := "любой"
+	if i.Username != "" {
+		username = "@" + i.Username
+	}
+	return fmt.Sprintf("<b>Код:</b> <code>%s</code>\n<b>Роль:</b> %s\n<b>Пользователь:</b> %s\n<b>Действует до:</b> %s",
+		i.Code,
+		i.Role,
+		username,
+		expiresAt,
+	)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"tasks_bot/internal/errs"
+)
+
+type ParticipantRole int
+
+const (
+	UnknownParticipant ParticipantRole = iota
+	ExecutorParticipant
+	WatcherParticipant
+)
+
+func (r ParticipantRole) String() string {
+	switch r {
+	case ExecutorParticipant:
+		return "исполнитель"
+	case WatcherParticipant:
+		return "наблюдатель"
+	default:
+		return "неизвестно"
+	}
+}
+
+// ParseParticipantRole parses participant role from its english or russian name.
+func ParseParticipantRole(name string) (ParticipantRole, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "executor", ExecutorParticipant.String():
+		return ExecutorParticipant, nil
+	case "watcher", WatcherParticipant.String():
+		return WatcherParticipant, nil
+	default:
+		return UnknownParticipant, fmt.Errorf("unknown participant role %q: %w", name, errs.ErrInvalidInput)
+	}
+}
+
+// TaskParticipant is a chat working on the task or following its updates.
+type TaskParticipant struct {
+	TaskID int
+	ChatID int64
+	Role   ParticipantRole
+	Done   bool
+}
+
+// DoneMode defines when task with several executors becomes done.
+type DoneMode string
+
+const (
+	// DoneByAnyExecutor marks task as done as soon as one of executors finishes it.
+	DoneByAnyExecutor DoneMode = "any"
+	// DoneByAllExecutors marks task as done when every executor finishes it.
+	DoneByAllExecutors DoneMode = "all"
+)
+
+func ParseDoneMode(raw string) (DoneMode, error) {
+	switch mode := DoneMode(strings.ToLower(strings.TrimSpace(raw))); mode {
+	case DoneByAnyExecutor, DoneByAllExecutors:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown done mode %q: %w", raw, errs.ErrInvalidInput)
+	}
+}
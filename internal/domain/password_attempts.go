@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// PasswordAttempts tracks failed role password attempts of the chat.
+type PasswordAttempts struct {
+	ChatID      int64
+	Failures    int
+	Lockouts    int
+	LockedUntil time.Time
+}
+
+func (a PasswordAttempts) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// ExceedsFailures reports whether the chat should be locked after the failed attempt.
+func (a PasswordAttempts) ExceedsFailures(maxFailures int) bool {
+	return a.Failures >= maxFailures
+}
+
+// Lockout returns the duration of the next lockout of the chat,
+// every next lockout is twice as long as previous one, but not longer than maxLockout.
+func (a PasswordAttempts) Lockout(baseLockout, maxLockout time.Duration) time.Duration {
+	lockout := baseLockout
+	for range a.Lockouts {
+		if lockout >= maxLockout {
+			break
+		}
+		lockout *= 2
+	}
+	return min(lockout, maxLockout)
+}
+
+func (a PasswordAttempts) String() string {
+	lockedUntil := "нет"
+	if !a.LockedUntil.IsZero() {
+		lockedUntil = a.LockedUntil.Format(DeadlineLayout)
+	}
+	return fmt.Sprintf("<b>%d</b>\n<b>Неверных попыток:</b> %d\n<b>Блокировок:</b> %d\n<b>Заблокирован до:</b> %s",
+		a.ChatID,
+		a.Failures,
+		a.Lockouts,
+		lockedUntil,
+	)
+}
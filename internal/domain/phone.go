@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"tasks_bot/internal/errs"
+)
+
+const (
+	minPhoneDigits = 10
+	maxPhoneDigits = 15
+)
+
+// NormalizePhone converts phone number to E.164 format (+79991234567).
+// Local russian numbers starting with 8 are converted to +7.
+func NormalizePhone(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("empty phone: %w", errs.ErrInvalidInput)
+	}
+
+	digits := strings.Builder{}
+	for i, char := range raw {
+		switch {
+		case char >= '0' && char <= '9':
+			digits.WriteRune(char)
+		case char == '+' && i == 0:
+		case char == ' ', char == '-', char == '(', char == ')':
+		default:
+			return "", fmt.Errorf("unexpected symbol %q in phone: %w", char, errs.ErrInvalidInput)
+		}
+	}
+
+	number := digits.String()
+	if len(number) == 11 && number[0] == '8' && !strings.HasPrefix(raw, "+") {
+		number = "7" + number[1:]
+	}
+	if len(number) < minPhoneDigits || len(number) > maxPhoneDigits {
+		return "", fmt.Errorf("phone has %d digits: %w", len(number), errs.ErrInvalidInput)
+	}
+
+	return "+" + number, nil
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"tasks_bot/internal/errs"
+	"time"
+)
+
+// Priority of the task, greater is more important. Zero value is normal priority,
+// so tasks created before priorities were introduced stay normal.
+type Priority int
+
+const (
+	LowPriority      Priority = -1
+	NormalPriority   Priority = 0
+	HighPriority     Priority = 1
+	CriticalPriority Priority = 2
+)
+
+// Priorities lists all priorities from the most important one.
+var Priorities = []Priority{CriticalPriority, HighPriority, NormalPriority, LowPriority}
+
+func (p Priority) String() string {
+	switch p {
+	case LowPriority:
+		return "низкий"
+	case HighPriority:
+		return "высокий"
+	case CriticalPriority:
+		return "критический"
+	default:
+		return "обычный"
+	}
+}
+
+func (p Priority) Emoji() string {
+	switch p {
+	case LowPriority:
+		return "🟢"
+	case HighPriority:
+		return "🟠"
+	case CriticalPriority:
+		return "🔴"
+	default:
+		return "🟡"
+	}
+}
+
+// Label is the emoji and the name of priority, it is used for keyboard buttons as well.
+func (p Priority) Label() string {
+	return fmt.Sprintf("%s %s", p.Emoji(), p)
+}
+
+// ParsePriority parses priority from its english or russian name or its label.
+func ParsePriority(name string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low", LowPriority.String(), LowPriority.Label():
+		return LowPriority, nil
+	case "normal", NormalPriority.String(), NormalPriority.Label():
+		return NormalPriority, nil
+	case "high", HighPriority.String(), HighPriority.Label():
+		return HighPriority, nil
+	case "critical", CriticalPriority.String(), CriticalPriority.Label():
+		return CriticalPriority, nil
+	default:
+		return NormalPriority, fmt.Errorf("unknown priority %q: %w", name, errs.ErrInvalidInput)
+	}
+}
+
+// SortByPriority orders tasks from the most important one, tasks of the same priority go by deadline.
+func SortByPriority(tasks []Task) {
+	slices.SortStableFunc(tasks, func(a, b Task) int {
+		if a.Priority != b.Priority {
+			return cmp.Compare(b.Priority, a.Priority)
+		}
+		return a.Deadline.Compare(b.Deadline)
+	})
+}
+
+// PriorityPolicy holds the settings which depend on task priority.
+type PriorityPolicy struct {
+	// SLA is the default deadline of new tasks relative to their creation
+	SLA map[Priority]time.Duration
+	// Reminders is how often executors are reminded about open tasks, zero disables reminders
+	Reminders map[Priority]time.Duration
+	// Digest is the schedule of the digest of expired low priority tasks, they are not escalated at once
+	Digest Schedule
+}
+
+// NewPriorityPolicy parses settings keyed by priority names, priorities missing in sla get normal SLA.
+func NewPriorityPolicy(sla, reminders map[string]time.Duration, digest string) (PriorityPolicy, error) {
+	policy := PriorityPolicy{
+		SLA:       make(map[Priority]time.Duration, len(Priorities)),
+		Reminders: make(map[Priority]time.Duration, len(Priorities)),
+	}
+	for name, deadline := range sla {
+		priority, err := ParsePriority(name)
+		if err != nil {
+			return PriorityPolicy{}, fmt.Errorf("sla: %w", err)
+		}
+		policy.SLA[priority] = deadline
+	}
+	if policy.SLA[NormalPriority] <= 0 {
+		return PriorityPolicy{}, fmt.Errorf("sla of normal priority is required: %w", errs.ErrInvalidInput)
+	}
+	for _, priority := range Priorities {
+		if policy.SLA[priority] <= 0 {
+			policy.SLA[priority] = policy.SLA[NormalPriority]
+		}
+	}
+	for name, interval := range reminders {
+		priority, err := ParsePriority(name)
+		if err != nil {
+			return PriorityPolicy{}, fmt.Errorf("reminders: %w", err)
+		}
+		policy.Reminders[priority] = interval
+	}
+
+	var err error
+	if policy.Digest, err = ParseSchedule(digest); err != nil {
+		return PriorityPolicy{}, fmt.Errorf("digest: %w", err)
+	}
+	return policy, nil
+}
+
+// Deadline returns the default deadline of the task of given priority created at the moment.
+func (p PriorityPolicy) Deadline(priority Priority, from time.Time) time.Time {
+	return from.Add(p.SLA[priority])
+}
+
+// TaskReminder is the open task with the moment of its reminder, zero when the reminder is not scheduled yet.
+type TaskReminder struct {
+	Task Task
+	At   time.Time
+}
+
+// NextReminder returns the moment of the next reminder about the task. It is the deadline itself
+// when reminders are disabled for the task priority or the deadline comes earlier, expired tasks are not reminded.
+func (p PriorityPolicy) NextReminder(task Task, now time.Time) time.Time {
+	interval := p.Reminders[task.Priority]
+	if interval <= 0 || now.Add(interval).After(task.Deadline) {
+		return task.Deadline
+	}
+	return now.Add(interval)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+type ProofKind int
+
+const (
+	UnknownProof ProofKind = iota
+	PhotoProof
+	DocumentProof
+	LocationProof
+	TextProof
+)
+
+func (k ProofKind) String() string {
+	switch k {
+	case PhotoProof:
+		return "фото"
+	case DocumentProof:
+		return "документ"
+	case LocationProof:
+		return "геопозиция"
+	case TextProof:
+		return "отчёт"
+	default:
+		return "неизвестно"
+	}
+}
+
+// Proof is what the executor sent to confirm the task is done, it is kept for audit.
+type Proof struct {
+	ID     int
+	TaskID int
+	ChatID int64
+	Author string
+	Kind   ProofKind
+	// FileID is the photo or document in Telegram
+	FileID string
+	// Text is the report or the caption of the file
+	Text      string
+	Latitude  float64
+	Longitude float64
+	CreatedAt time.Time
+}
+
+func (p Proof) String() string {
+	text := fmt.Sprintf("<b>%s</b> %s, %s", html.EscapeString(p.Author), p.CreatedAt.Format(DeadlineLayout), p.Kind)
+	if p.Text != "" {
+		text += "\n" + html.EscapeString(p.Text)
+	}
+	return text
+}
@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/errs"
+	"time"
+)
+
+// Recurrence is a definition of the task which is created again and again by its schedule.
+type Recurrence struct {
+	ID              int64
+	TeamID          int64
+	Title           string
+	ExecutorContact string
+	ExecutorChatID  int64
+	Schedule        Schedule
+	// DeadlineIn is the deadline of each occurrence relative to the time it is created for
+	DeadlineIn time.Duration
+	NextRun    time.Time
+	Paused     bool
+	CreatedBy  int64
+}
+
+// Occurrence returns the task to be created for the run at the given time.
+func (r Recurrence) Occurrence(at time.Time) Task {
+	return Task{
+		TeamID:          r.TeamID,
+		Title:           r.Title,
+		ExecutorContact: r.ExecutorContact,
+		ExecutorChatID:  r.ExecutorChatID,
+		Deadline:        at.Add(r.DeadlineIn),
+		CreatedBy:       r.CreatedBy,
+	}
+}
+
+func (r Recurrence) String() string {
+	nextRun := r.NextRun.Format(DeadlineLayout)
+	if r.Paused {
+		nextRun = "на паузе"
+	}
+	return fmt.Sprintf(
+		"<b>Повторение №%d:</b> %s\n<b>Расписание:</b> %s\n<b>Исполнитель:</b> %s\n<b>Срок:</b> %s\n<b>Следующий запуск:</b> %s",
+		r.ID,
+		r.Title,
+		r.Schedule,
+		formatExecutorContact(r.ExecutorContact),
+		formatDuration(r.DeadlineIn),
+		nextRun,
+	)
+}
+
+// formatDuration prints whole days as 7d, like they are entered, and other durations as is.
+func formatDuration(d time.Duration) string {
+	if day := 24 * time.Hour; d >= day && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
+
+// cronField is a set of allowed values of one schedule field, bit i stands for value i.
+type cronField uint64
+
+func (f cronField) has(value int) bool {
+	return f&(1<<uint(value)) != 0
+}
+
+// Schedule defines moments of recurrence runs with minute precision in local time.
+// All kinds of schedules are kept as cron fields, so days of month and week restrict each other
+// in cron manner: when both are set, the day matching any of them fits.
+type Schedule struct {
+	spec     string
+	minutes  cronField
+	hours    cronField
+	days     cronField
+	months   cronField
+	weekdays cronField
+	anyDay   bool
+	anyWeek  bool
+}
+
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// ParseSchedule parses one of:
+//
+//	daily 09:00
+//	weekly mon,fri 09:00
+//	monthly 15 09:00
+//	cron 0 9 * * 1-5
+//
+// Russian names (ежедневно, еженедельно, ежемесячно, пн-вс) are accepted as well.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.Join(strings.Fields(spec), " ")
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 {
+		return Schedule{}, fmt.Errorf("empty schedule: %w", errs.ErrInvalidInput)
+	}
+
+	var cron []string
+	switch kind, args := fields[0], fields[1:]; {
+	case kind == "daily" || kind == "ежедневно":
+		if len(args) != 1 {
+			return Schedule{}, fmt.Errorf("daily schedule %q: %w", spec, errs.ErrInvalidInput)
+		}
+		hour, minute, err := parseClock(args[0])
+		if err != nil {
+			return Schedule{}, err
+		}
+		cron = []string{minute, hour, "*", "*", "*"}
+	case kind == "weekly" || kind == "еженедельно":
+		if len(args) != 2 {
+			return Schedule{}, fmt.Errorf("weekly schedule %q: %w", spec, errs.ErrInvalidInput)
+		}
+		hour, minute, err := parseClock(args[1])
+		if err != nil {
+			return Schedule{}, err
+		}
+		cron = []string{minute, hour, "*", "*", args[0]}
+	case kind == "monthly" || kind == "ежемесячно":
+		if len(args) != 2 {
+			return Schedule{}, fmt.Errorf("monthly schedule %q: %w", spec, errs.ErrInvalidInput)
+		}
+		hour, minute, err := parseClock(args[1])
+		if err != nil {
+			return Schedule{}, err
+		}
+		cron = []string{minute, hour, args[0], "*", "*"}
+	case kind == "cron":
+		cron = args
+	default:
+		return Schedule{}, fmt.Errorf("unknown schedule kind %q: %w", kind, errs.ErrInvalidInput)
+	}
+	if len(cron) != 5 {
+		return Schedule{}, fmt.Errorf("cron expression %q must have 5 fields: %w", spec, errs.ErrInvalidInput)
+	}
+
+	schedule := Schedule{
+		spec:    spec,
+		anyDay:  cron[2] == "*",
+		anyWeek: cron[4] == "*",
+	}
+	var err error
+	if schedule.minutes, err = parseCronField(cron[0], 0, 59); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.hours, err = parseCronField(cron[1], 0, 23); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.days, err = parseCronField(cron[2], 1, 31); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.months, err = parseCronField(cron[3], 1, 12); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.weekdays, err = parseCronField(cron[4], 0, 7); err != nil {
+		return Schedule{}, err
+	}
+	// both 0 and 7 mean sunday
+	if schedule.weekdays.has(7) {
+		schedule.weekdays |= 1
+	}
+
+	if schedule.Next(time.Now()).IsZero() {
+		return Schedule{}, fmt.Errorf("schedule %q never runs: %w", spec, errs.ErrInvalidInput)
+	}
+	return schedule, nil
+}
+
+// Next returns the first run strictly after the moment or zero time if there is none in the next years.
+func (s Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case !s.months.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hours.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.minutes.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(t time.Time) bool {
+	day, weekday := s.days.has(t.Day()), s.weekdays.has(int(t.Weekday()))
+	switch {
+	case s.anyDay && s.anyWeek:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeek:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+var weekdayNames = map[string]string{
+	"sun": "0", "mon": "1", "tue": "2", "wed": "3", "thu": "4", "fri": "5", "sat": "6",
+	"вс": "0", "пн": "1", "вт": "2", "ср": "3", "чт": "4", "пт": "5", "сб": "6",
+}
+
+// parseCronField parses comma separated list of values, ranges and steps: "*", "*/15", "1-5", "mon,wed".
+func parseCronField(raw string, min, max int) (cronField, error) {
+	var field cronField
+	for _, part := range strings.Split(raw, ",") {
+		rawRange, rawStep, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(rawStep); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q: %w", raw, errs.ErrInvalidInput)
+			}
+		}
+
+		from, to := min, max
+		if rawRange != "*" {
+			rawFrom, rawTo, isRange := strings.Cut(rawRange, "-")
+			var err error
+			if from, err = parseCronValue(rawFrom, min, max); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = parseCronValue(rawTo, min, max); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = max
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid range %q: %w", rawRange, errs.ErrInvalidInput)
+			}
+		}
+		for i := from; i <= to; i += step {
+			field |= 1 << uint(i)
+		}
+	}
+	return field, nil
+}
+
+func parseCronValue(raw string, min, max int) (int, error) {
+	if name, ok := weekdayNames[raw]; ok && max == 7 {
+		raw = name
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min || value > max {
+		return 0, fmt.Errorf("value %q is out of range %d-%d: %w", raw, min, max, errs.ErrInvalidInput)
+	}
+	return value, nil
+}
+
+// parseClock splits time of day like 09:30 into cron hour and minute fields.
+func parseClock(raw string) (string, string, error) {
+	clock, err := time.Parse("15:04", raw)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid time of day %q: %w", raw, errs.ErrInvalidInput)
+	}
+	return strconv.Itoa(clock.Hour()), strconv.Itoa(clock.Minute()), nil
+}
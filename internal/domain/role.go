@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"fmt"
+	"strings"
+	"tasks_bot/internal/errs"
+)
+
 type Role int
 
 const (
@@ -26,3 +32,19 @@ func (r Role) String() string {
 		return "неизвестно"
 	}
 }
+
+// ParseRole parses role from its english or russian name.
+func ParseRole(name string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "executor", Executor.String():
+		return Executor, nil
+	case "observer", Observer.String():
+		return Observer, nil
+	case "chief", Chief.String():
+		return Chief, nil
+	case "admin", Admin.String():
+		return Admin, nil
+	default:
+		return UnknownRole, fmt.Errorf("unknown role %q: %w", name, errs.ErrInvalidInput)
+	}
+}
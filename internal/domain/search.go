@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// russianEndings are inflection endings cut off by SearchTerms, the longest go first.
+var russianEndings = []string{
+	"иями", "ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими", "иях", "ией",
+	"ах", "ях", "ов", "ев", "ей", "ой", "ый", "ий", "ая", "яя", "ое", "ее", "ые", "ие",
+	"ую", "юю", "ом", "ем", "ам", "ям", "ию", "ия",
+	"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й",
+}
+
+// minStemLength keeps short words like "акт" from being cut to meaningless prefixes.
+const minStemLength = 3
+
+// NormalizeSearchText lowercases text and replaces "ё" with "е", so both spellings match.
+func NormalizeSearchText(text string) string {
+	return strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+}
+
+// SearchTerms splits the search query into word stems, so "принтеры" and "принтера" both give "принтер".
+// Backends without russian morphology match the stems as prefixes of words.
+func SearchTerms(query string) []string {
+	words := strings.FieldsFunc(NormalizeSearchText(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if term := stem(word); !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func stem(word string) string {
+	for _, ending := range russianEndings {
+		if base, ok := strings.CutSuffix(word, ending); ok && utf8.RuneCountInString(base) >= minStemLength {
+			return base
+		}
+	}
+	return word
+}
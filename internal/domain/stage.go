@@ -17,4 +17,6 @@ const (
 	ChangeDeadline
 	ContactRequest
 	DeleteTask
+	AddTaskPriority
+	MarkTaskProof
 )
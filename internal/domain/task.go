@@ -2,6 +2,8 @@ package domain
 
 import (
 	"fmt"
+	"html"
+	"strings"
 	"time"
 )
 
@@ -19,6 +21,7 @@ type TaskStatus int
 
 type Task struct {
 	ID              int
+	TeamID          int64
 	Title           string
 	ExecutorContact string
 	ExecutorChatID  int64
@@ -26,47 +29,92 @@ type Task struct {
 	Done            bool
 	Expired         bool
 	Closed          bool
+	// ParentID is the number of parent task for subtasks
+	ParentID    int
+	Description string
+	Priority    Priority
+	// CreatedBy is the chat which created the task, zero for tasks created before it was stored
+	CreatedBy int64
+	// ProofRequired tasks are done only after the executor sends a proof of completion
+	ProofRequired bool
+	// Progress, BlockedBy and Tags are filled only when task is shown to user
+	Progress TaskProgress
+	// BlockedBy are numbers of unfinished tasks this task depends on
+	BlockedBy []int
+	Tags      []string
 }
 
 func (t Task) String() string {
-	if t.Done || t.Closed {
-		return fmt.Sprintf("<b>Задача №%d</b>\n<b>Название:</b> %s\n<b>Дедлайн %s</b>\n<b>Статус:</b> %s\n<b>Исполнитель:</b> %s",
-			t.ID,
-			t.Title,
-			t.Deadline.Format(DeadlineLayout),
-			t.GetStatus(),
-			formatExecutorContact(t.ExecutorContact),
-		)
+	status := t.GetStatus()
+	deadlineFormat := "\n<b>Дедлайн %s</b>"
+	if !t.Done && !t.Closed && time.Now().After(t.Deadline) {
+		status, deadlineFormat = ExpiredTask, "\n<b>Дедлайн:</b> %s"
 	}
 
-	if time.Now().After(t.Deadline) {
-		return fmt.Sprintf("<b>Задача №%d</b>\n<b>Название:</b> %s\n<b>Дедлайн:</b> %s\n<b>Статус:</b> %s\n<b>Исполнитель:</b> %s",
-			t.ID,
-			t.Title,
-			t.Deadline.Format(DeadlineLayout),
-			ExpiredTask,
-			formatExecutorContact(t.ExecutorContact),
-		)
+	builder := strings.Builder{}
+	// the card is sent as HTML, so the text entered by users is escaped
+	builder.WriteString(fmt.Sprintf("<b>Задача №%d</b>\n<b>Название:</b> %s", t.ID, html.EscapeString(t.Title)))
+	if t.Description != "" {
+		builder.WriteString(fmt.Sprintf("\n<b>Описание:</b> %s", html.EscapeString(t.Description)))
 	}
-	return fmt.Sprintf("<b>Задача №%d</b>\n<b>Название:</b> %s\n<b>Дедлайн %s</b>\n<b>Статус:</b> %s\n<b>Исполнитель:</b> %s",
-		t.ID,
-		t.Title,
-		t.Deadline.Format(DeadlineLayout),
-		t.GetStatus(),
-		formatExecutorContact(t.ExecutorContact),
-	)
+	builder.WriteString(fmt.Sprintf(deadlineFormat, t.Deadline.Format(DeadlineLayout)))
+	builder.WriteString(fmt.Sprintf("\n<b>Статус:</b> %s\n<b>Исполнитель:</b> %s", status, formatExecutorContact(t.ExecutorContact)))
+	builder.WriteString(fmt.Sprintf("\n<b>Приоритет:</b> %s", t.Priority.Label()))
+	if t.ProofRequired {
+		builder.WriteString("\n<b>Подтверждение выполнения:</b> обязательно")
+	}
+	if len(t.Tags) > 0 {
+		builder.WriteString(fmt.Sprintf("\n<b>Теги:</b> %s", FormatTags(t.Tags)))
+	}
+	if t.ParentID != 0 {
+		builder.WriteString(fmt.Sprintf("\n<b>Родительская задача:</b> №%d", t.ParentID))
+	}
+	if t.Progress.Subtasks > 0 {
+		builder.WriteString(fmt.Sprintf("\n<b>Подзадачи:</b> %d/%d", t.Progress.SubtasksDone, t.Progress.Subtasks))
+	}
+	if t.Progress.Items > 0 {
+		builder.WriteString(fmt.Sprintf("\n<b>Чек-лист:</b> %d/%d", t.Progress.ItemsDone, t.Progress.Items))
+	}
+	if len(t.BlockedBy) > 0 {
+		builder.WriteString(fmt.Sprintf("\n<b>⛔ Заблокирована задачами:</b> %s", formatTaskNumbers(t.BlockedBy)))
+	}
+	return builder.String()
 }
 
-// TODO fix
-func formatExecutorContact(contact string) string {
-	isNumber := true
-	for _, char := range contact {
-		if char < '0' || char > '9' {
-			isNumber = false
+// IsExecutedBy reports whether the chat is the executor of the task.
+func (t Task) IsExecutedBy(chat Chat) bool {
+	return (t.ExecutorChatID != 0 && t.ExecutorChatID == chat.ID) ||
+		(chat.Username != "" && t.ExecutorContact == chat.Username) ||
+		(chat.Phone != "" && t.ExecutorContact == chat.Phone)
+}
+
+// IsFinished reports whether nothing has to be done on the task anymore.
+func (t Task) IsFinished() bool {
+	return t.Done || t.Closed
+}
+
+// OpenBlockers returns numbers of the blockers which are not finished yet.
+func OpenBlockers(blockers []Task) []int {
+	var open []int
+	for _, blocker := range blockers {
+		if !blocker.IsFinished() {
+			open = append(open, blocker.ID)
 		}
 	}
-	if !isNumber {
-		return fmt.Sprintf("@%s", contact)
+	return open
+}
+
+func formatTaskNumbers(taskIDs []int) string {
+	numbers := make([]string, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		numbers = append(numbers, fmt.Sprintf("№%d", taskID))
+	}
+	return strings.Join(numbers, ", ")
+}
+
+func formatExecutorContact(contact string) string {
+	if _, err := NormalizePhone(contact); err != nil {
+		return fmt.Sprintf("@%s", html.EscapeString(contact))
 	}
 	return contact
 }
@@ -0,0 +1,16 @@
+package domain
+
+// DefaultTeamID is the team which existed before teams were introduced.
+// All chats and tasks created by previous versions belong to it.
+const DefaultTeamID int64 = 1
+
+type Team struct {
+	ID   int64
+	Name string
+}
+
+// TeamMembership is a team with a role of the chat in it.
+type TeamMembership struct {
+	Team
+	Role Role
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskTemplate is a named preset for the tasks of the same kind.
+type TaskTemplate struct {
+	TeamID int64
+	Name   string
+	// TitlePattern may contain {date}, {week}, {month} and {year} placeholders
+	TitlePattern    string
+	ExecutorContact string
+	// DeadlineIn is zero when the deadline is taken from SLA of the priority
+	DeadlineIn  time.Duration
+	Priority    Priority
+	Description string
+	Checklist   []string
+	CreatedBy   int64
+	// ProofRequired is copied to tasks created by the template
+	ProofRequired bool
+}
+
+// Title expands placeholders of the title pattern with the moment the task is created at.
+func (t TaskTemplate) Title(now time.Time) string {
+	_, week := now.ISOWeek()
+	return strings.NewReplacer(
+		"{date}", now.Format("02.01.2006"),
+		"{week}", strconv.Itoa(week),
+		"{month}", now.Format("01.2006"),
+		"{year}", now.Format("2006"),
+	).Replace(t.TitlePattern)
+}
+
+func (t TaskTemplate) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("<b>Шаблон:</b> %s\n<b>Название:</b> %s", html.EscapeString(t.Name), html.EscapeString(t.TitlePattern)))
+	executor := "не задан"
+	if t.ExecutorContact != "" {
+		executor = formatExecutorContact(t.ExecutorContact)
+	}
+	deadline := "по SLA приоритета"
+	if t.DeadlineIn > 0 {
+		deadline = formatDuration(t.DeadlineIn)
+	}
+	builder.WriteString(fmt.Sprintf("\n<b>Исполнитель:</b> %s\n<b>Срок:</b> %s\n<b>Приоритет:</b> %s", executor, deadline, t.Priority.Label()))
+	if t.Description != "" {
+		builder.WriteString(fmt.Sprintf("\n<b>Описание:</b> %s", html.EscapeString(t.Description)))
+	}
+	if len(t.Checklist) > 0 {
+		builder.WriteString(fmt.Sprintf("\n<b>Чек-лист:</b> %s", html.EscapeString(strings.Join(t.Checklist, "; "))))
+	}
+	if t.ProofRequired {
+		builder.WriteString("\n<b>Подтверждение выполнения:</b> обязательно")
+	}
+	return builder.String()
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -12,16 +15,47 @@ import (
 )
 
 const (
-	queueSize = 1000
+	queueSize       = 1000
+	defaultTeamName = "default"
 )
 
 type MemoryStorage struct {
 	mu *sync.RWMutex
 
-	chats           map[int64]*domain.Chat
-	tasks           []domain.Task
-	tasksInProgress map[int64]domain.Task
-	messageQueue    []domain.Message
+	chats            map[int64]*domain.Chat
+	teams            []domain.Team
+	members          map[int64]map[int64]domain.Role
+	managers         map[int64]map[int64]int64
+	bans             map[int64]map[int64]bool
+	tasks            []domain.Task
+	lastTaskID       int
+	participants     map[int]map[int64]domain.TaskParticipant
+	checklists       map[int][]domain.ChecklistItem
+	dependencies     map[int][]int
+	reminders        map[int]time.Time
+	tags             map[int][]string
+	views            map[viewKey]map[string]domain.SavedView
+	comments         map[int][]domain.Comment
+	lastCommentID    int
+	taskMessages     map[taskMessageKey]int
+	taskCards        map[int]map[int64]int
+	dashboards       map[int64]domain.Dashboard
+	inactiveChats    map[int64]string
+	handledUpdates   map[int]time.Time
+	lastUpdateID     int
+	attachments      []domain.Attachment
+	lastAttachmentID int
+	proofs           map[int][]domain.Proof
+	lastProofID      int
+	lastItemID       int
+	recurrences      []domain.Recurrence
+	templates        map[int64]map[string]domain.TaskTemplate
+	lastRecurrence   int64
+	tasksInProgress  map[int64]domain.Task
+	messageQueue     []domain.Message
+	invites          map[string]*domain.Invite
+	adminActions     []domain.AdminAction
+	passwords        map[int64]domain.PasswordAttempts
 
 	closed atomic.Bool
 }
@@ -30,60 +64,227 @@ func NewMemoryStorage(_ context.Context) (*MemoryStorage, error) {
 	return &MemoryStorage{
 		mu:              &sync.RWMutex{},
 		chats:           make(map[int64]*domain.Chat),
+		teams:           []domain.Team{{ID: domain.DefaultTeamID, Name: defaultTeamName}},
+		members:         make(map[int64]map[int64]domain.Role),
+		managers:        make(map[int64]map[int64]int64),
+		bans:            make(map[int64]map[int64]bool),
 		tasks:           make([]domain.Task, 0, queueSize),
+		participants:    make(map[int]map[int64]domain.TaskParticipant),
+		checklists:      make(map[int][]domain.ChecklistItem),
+		dependencies:    make(map[int][]int),
+		reminders:       make(map[int]time.Time),
+		tags:            make(map[int][]string),
+		views:           make(map[viewKey]map[string]domain.SavedView),
+		comments:        make(map[int][]domain.Comment),
+		taskMessages:    make(map[taskMessageKey]int),
+		taskCards:       make(map[int]map[int64]int),
+		dashboards:      make(map[int64]domain.Dashboard),
+		inactiveChats:   make(map[int64]string),
+		handledUpdates:  make(map[int]time.Time),
+		proofs:          make(map[int][]domain.Proof),
+		templates:       make(map[int64]map[string]domain.TaskTemplate),
 		tasksInProgress: make(map[int64]domain.Task, queueSize),
 		messageQueue:    make([]domain.Message, 0, queueSize),
+		invites:         make(map[string]*domain.Invite),
+		adminActions:    make([]domain.AdminAction, 0),
+		passwords:       make(map[int64]domain.PasswordAttempts),
 		closed:          atomic.Bool{},
 	}, nil
 }
 
-func (ms *MemoryStorage) GetRole(ctx context.Context, chatID int64) (domain.Role, error) {
+func (ms *MemoryStorage) GetRole(ctx context.Context, teamID, chatID int64) (domain.Role, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	chat, ok := ms.chats[chatID]
+	role, ok := ms.members[teamID][chatID]
 	if !ok {
 		return domain.UnknownRole, errs.ErrNotFound
 	}
 
-	return chat.Role, nil
+	return role, nil
 }
 
-func (ms *MemoryStorage) SetRole(ctx context.Context, chatID int64, role domain.Role) error {
+func (ms *MemoryStorage) SetRole(ctx context.Context, teamID, chatID int64, role domain.Role) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	chat, ok := ms.chats[chatID]
-	if !ok {
+	if _, ok := ms.chats[chatID]; !ok {
+		return errs.ErrNotFound
+	}
+	if _, ok := ms.members[teamID]; !ok {
+		ms.members[teamID] = make(map[int64]domain.Role)
+	}
+	ms.members[teamID][chatID] = role
+
+	return nil
+}
+
+func (ms *MemoryStorage) SetManager(ctx context.Context, teamID, chatID, managerID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.members[teamID][chatID]; !ok {
 		return errs.ErrNotFound
 	}
-	chat.Role = role
+	if _, ok := ms.managers[teamID]; !ok {
+		ms.managers[teamID] = make(map[int64]int64)
+	}
+	if managerID == 0 {
+		delete(ms.managers[teamID], chatID)
+		return nil
+	}
+	ms.managers[teamID][chatID] = managerID
 
 	return nil
 }
 
-func (ms *MemoryStorage) AddChat(ctx context.Context, chatID int64, username, phone string, role domain.Role) error {
+func (ms *MemoryStorage) GetSubordinates(ctx context.Context, teamID, managerID int64) ([]domain.Chat, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.subordinates(teamID, managerID), nil
+}
+
+func (ms *MemoryStorage) subordinates(teamID, managerID int64) []domain.Chat {
+	subordinates := make([]domain.Chat, 0)
+	for chatID, chatManagerID := range ms.managers[teamID] {
+		chat, ok := ms.chats[chatID]
+		if ok && chatManagerID == managerID {
+			subordinates = append(subordinates, *ms.chatInTeam(chat, teamID))
+		}
+	}
+	return subordinates
+}
+
+func (ms *MemoryStorage) GetSubordinatesTasks(ctx context.Context, teamID, managerID int64) ([]domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	subordinates := ms.subordinates(teamID, managerID)
+	tasks := make([]domain.Task, 0)
+	for _, task := range ms.tasks {
+		if task.TeamID != teamID {
+			continue
+		}
+		for _, subordinate := range subordinates {
+			if task.IsExecutedBy(subordinate) {
+				tasks = append(tasks, task)
+				break
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (ms *MemoryStorage) GetExecutorManager(ctx context.Context, teamID, executorChatID int64, executorContact string) (int64, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	task := domain.Task{ExecutorChatID: executorChatID, ExecutorContact: executorContact}
+	for chatID, managerID := range ms.managers[teamID] {
+		chat, ok := ms.chats[chatID]
+		if ok && task.IsExecutedBy(*chat) {
+			return managerID, nil
+		}
+	}
+	return 0, errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) AddChat(ctx context.Context, chatID int64, username, phone string) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
+	if chat, ok := ms.chats[chatID]; ok {
+		if username != "" {
+			chat.Username = username
+		}
+		if phone != "" {
+			chat.Phone = phone
+		}
+		return nil
+	}
+
 	ms.chats[chatID] = &domain.Chat{
+		ID:       chatID,
+		TeamID:   domain.DefaultTeamID,
 		Username: username,
 		Phone:    phone,
 		Stage:    domain.Default,
-		Role:     role,
 	}
 
 	return nil
 }
 
-func (ms *MemoryStorage) GetObservers(ctx context.Context) (map[int64]*domain.Chat, error) {
+func (ms *MemoryStorage) AddTeam(ctx context.Context, name string) (domain.Team, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, team := range ms.teams {
+		if team.Name == name {
+			return domain.Team{}, fmt.Errorf("team %s already exists: %w", name, errs.ErrInvalidInput)
+		}
+	}
+	team := domain.Team{ID: int64(len(ms.teams) + 1), Name: name}
+	ms.teams = append(ms.teams, team)
+
+	return team, nil
+}
+
+func (ms *MemoryStorage) GetTeamByName(ctx context.Context, name string) (domain.Team, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for _, team := range ms.teams {
+		if team.Name == name {
+			return team, nil
+		}
+	}
+	return domain.Team{}, errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) GetChatTeams(ctx context.Context, chatID int64) ([]domain.TeamMembership, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	memberships := make([]domain.TeamMembership, 0)
+	for _, team := range ms.teams {
+		if role, ok := ms.members[team.ID][chatID]; ok {
+			memberships = append(memberships, domain.TeamMembership{Team: team, Role: role})
+		}
+	}
+	return memberships, nil
+}
+
+func (ms *MemoryStorage) SetActiveTeam(ctx context.Context, chatID, teamID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	chat, ok := ms.chats[chatID]
+	if !ok {
+		return errs.ErrNotFound
+	}
+	chat.TeamID = teamID
+
+	return nil
+}
+
+// chatInTeam returns copy of the chat with its role in the team.
+func (ms *MemoryStorage) chatInTeam(chat *domain.Chat, teamID int64) *domain.Chat {
+	result := *chat
+	result.Role = ms.members[teamID][chat.ID]
+	result.Banned = ms.bans[teamID][chat.ID]
+	return &result
+}
+
+func (ms *MemoryStorage) GetObservers(ctx context.Context, teamID int64) (map[int64]*domain.Chat, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
 	observers := make(map[int64]*domain.Chat, 1)
-	for chatID, chat := range ms.chats {
-		if chat.Role == domain.Observer {
-			observers[chatID] = chat
+	for chatID, role := range ms.members[teamID] {
+		chat, ok := ms.chats[chatID]
+		if ok && role == domain.Observer {
+			observers[chatID] = ms.chatInTeam(chat, teamID)
 		}
 	}
 
@@ -147,23 +348,27 @@ func (ms *MemoryStorage) SetHandledMessage(ctx context.Context, messageID int) e
 	return nil
 }
 
-func (ms *MemoryStorage) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
+func (ms *MemoryStorage) GetAllTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	tasks := make([]domain.Task, len(ms.tasks))
-	copy(tasks, ms.tasks)
+	tasks := make([]domain.Task, 0, len(ms.tasks))
+	for _, task := range ms.tasks {
+		if task.TeamID == teamID {
+			tasks = append(tasks, task)
+		}
+	}
 
 	return tasks, nil
 }
 
-func (ms *MemoryStorage) GetExpiredTasks(ctx context.Context) ([]domain.Task, error) {
+func (ms *MemoryStorage) GetExpiredTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
 	tasks := make([]domain.Task, 0)
 	for _, task := range ms.tasks {
-		if !task.Done && task.Expired {
+		if task.TeamID == teamID && !task.Done && task.Expired {
 			tasks = append(tasks, task)
 		}
 	}
@@ -185,13 +390,13 @@ func (ms *MemoryStorage) GetExpiredTasksToMark(ctx context.Context) ([]domain.Ta
 	return tasks, nil
 }
 
-func (ms *MemoryStorage) GetOpenTasks(ctx context.Context) ([]domain.Task, error) {
+func (ms *MemoryStorage) GetOpenTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
 	tasks := make([]domain.Task, 0, len(ms.tasks))
 	for _, task := range ms.tasks {
-		if task.Closed {
+		if task.TeamID != teamID || task.Closed {
 			continue
 		}
 		tasks = append(tasks, task)
@@ -199,38 +404,41 @@ func (ms *MemoryStorage) GetOpenTasks(ctx context.Context) ([]domain.Task, error
 	return tasks, nil
 }
 
-func (ms *MemoryStorage) GetDoneTasks(ctx context.Context) ([]domain.Task, error) {
+func (ms *MemoryStorage) GetDoneTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
 	tasks := make([]domain.Task, 0, len(ms.tasks))
 	for _, task := range ms.tasks {
-		if task.Done {
+		if task.TeamID == teamID && task.Done {
 			tasks = append(tasks, task)
 		}
 	}
 	return tasks, nil
 }
 
-func (ms *MemoryStorage) GetClosedTasks(ctx context.Context) ([]domain.Task, error) {
+func (ms *MemoryStorage) GetClosedTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
 	tasks := make([]domain.Task, 0, len(ms.tasks))
 	for _, task := range ms.tasks {
-		if task.Closed {
+		if task.TeamID == teamID && task.Closed {
 			tasks = append(tasks, task)
 		}
 	}
 	return tasks, nil
 }
 
-func (ms *MemoryStorage) GetUserTasks(ctx context.Context, username, phone string) ([]domain.Task, error) {
+func (ms *MemoryStorage) GetUserTasks(ctx context.Context, teamID int64, username, phone string) ([]domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
 	tasks := make([]domain.Task, 0, len(ms.tasks))
 	for _, task := range ms.tasks {
+		if task.TeamID != teamID {
+			continue
+		}
 		if task.ExecutorContact == username || task.ExecutorContact == phone {
 			tasks = append(tasks, task)
 		}
@@ -242,126 +450,1176 @@ func (ms *MemoryStorage) AddTask(ctx context.Context, task domain.Task) (int, er
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
+	ms.lastTaskID++
+	task.ID = ms.lastTaskID
 	ms.tasks = append(ms.tasks, task)
 
-	return len(ms.tasks) + 1, nil
+	return task.ID, nil
 }
 
-func (ms *MemoryStorage) GetChat(ctx context.Context, username, phone string) (*domain.Chat, error) {
+func (ms *MemoryStorage) GetTask(ctx context.Context, teamID int64, taskID int) (domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	var resultChat *domain.Chat
-	for _, chat := range ms.chats {
-		if chat.Username == username || chat.Phone == phone {
-			resultChat = chat
+	for _, task := range ms.tasks {
+		if task.TeamID == teamID && task.ID == taskID {
+			return task, nil
 		}
 	}
-	return resultChat, nil
+	return domain.Task{}, errs.ErrNotFound
 }
 
-func (ms *MemoryStorage) MarkTaskAsDone(ctx context.Context, taskID int) error {
+func (ms *MemoryStorage) SetTaskParent(ctx context.Context, teamID int64, taskID, parentID int) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
 	for i, task := range ms.tasks {
-		if task.ID == taskID {
-			ms.tasks[i].Done = true
+		if task.TeamID == teamID && task.ID == taskID {
+			ms.tasks[i].ParentID = parentID
 			return nil
 		}
 	}
 	return errs.ErrNotFound
 }
 
-func (ms *MemoryStorage) MarkTaskAsClosed(ctx context.Context, taskID int) error {
+func (ms *MemoryStorage) GetSubtasks(ctx context.Context, teamID int64, parentID int) ([]domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	tasks := make([]domain.Task, 0)
+	for _, task := range ms.tasks {
+		if task.TeamID == teamID && task.ParentID == parentID {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (ms *MemoryStorage) GetTasksProgress(ctx context.Context, taskIDs []int) (map[int]domain.TaskProgress, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	progress := make(map[int]domain.TaskProgress, len(taskIDs))
+	for _, taskID := range taskIDs {
+		var taskProgress domain.TaskProgress
+		for _, task := range ms.tasks {
+			if task.ParentID != taskID {
+				continue
+			}
+			taskProgress.Subtasks++
+			if task.IsFinished() {
+				taskProgress.SubtasksDone++
+			}
+		}
+		for _, item := range ms.checklists[taskID] {
+			taskProgress.Items++
+			if item.Done {
+				taskProgress.ItemsDone++
+			}
+		}
+		progress[taskID] = taskProgress
+	}
+	return progress, nil
+}
+
+func (ms *MemoryStorage) AddChecklistItem(ctx context.Context, item domain.ChecklistItem) (int, error) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	for i, task := range ms.tasks {
-		if task.ID == taskID {
-			ms.tasks[i].Closed = true
+	ms.lastItemID++
+	item.ID = ms.lastItemID
+	ms.checklists[item.TaskID] = append(ms.checklists[item.TaskID], item)
+
+	return item.ID, nil
+}
+
+func (ms *MemoryStorage) GetChecklist(ctx context.Context, taskID int) ([]domain.ChecklistItem, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return append([]domain.ChecklistItem(nil), ms.checklists[taskID]...), nil
+}
+
+func (ms *MemoryStorage) SetChecklistItemDone(ctx context.Context, taskID, itemID int, done bool) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i, item := range ms.checklists[taskID] {
+		if item.ID == itemID {
+			ms.checklists[taskID][i].Done = done
 			return nil
 		}
 	}
 	return errs.ErrNotFound
 }
 
-func (ms *MemoryStorage) DeleteTask(ctx context.Context, taskID int) error {
+func (ms *MemoryStorage) AddTaskDependency(ctx context.Context, taskID, blockerID int) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	for i, task := range ms.tasks {
-		if task.ID == taskID {
-			ms.tasks = append(ms.tasks[:i], ms.tasks[i+1:]...)
-			return nil
-		}
+	if !slices.Contains(ms.dependencies[taskID], blockerID) {
+		ms.dependencies[taskID] = append(ms.dependencies[taskID], blockerID)
 	}
 	return nil
 }
 
-func (ms *MemoryStorage) ChangeTaskDeadline(ctx context.Context, taskID int, newDeadline time.Time) error {
+func (ms *MemoryStorage) RemoveTaskDependency(ctx context.Context, taskID, blockerID int) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	for i, task := range ms.tasks {
-		if task.ID == taskID {
-			ms.tasks[i].Deadline = newDeadline
-			ms.tasks[i].Expired = false
-			return nil
+	i := slices.Index(ms.dependencies[taskID], blockerID)
+	if i < 0 {
+		return errs.ErrNotFound
+	}
+	ms.dependencies[taskID] = slices.Delete(ms.dependencies[taskID], i, i+1)
+	return nil
+}
+
+func (ms *MemoryStorage) GetBlockers(ctx context.Context, taskIDs []int) (map[int][]domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	blockers := make(map[int][]domain.Task, len(taskIDs))
+	for _, taskID := range taskIDs {
+		for _, task := range ms.tasks {
+			if slices.Contains(ms.dependencies[taskID], task.ID) {
+				blockers[taskID] = append(blockers[taskID], task)
+			}
 		}
 	}
-	return errs.ErrNotFound
+	return blockers, nil
 }
 
-func (ms *MemoryStorage) GetTaskInProgress(ctx context.Context, chatID int64) (domain.Task, error) {
+func (ms *MemoryStorage) GetDependentTasks(ctx context.Context, blockerID int) ([]domain.Task, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	return ms.tasksInProgress[chatID], nil
+	tasks := make([]domain.Task, 0)
+	for _, task := range ms.tasks {
+		if slices.Contains(ms.dependencies[task.ID], blockerID) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
 }
 
-func (ms *MemoryStorage) SetTaskInProgressName(ctx context.Context, chatID int64, name string) error {
+func (ms *MemoryStorage) GetParticipantTasks(ctx context.Context, teamID, chatID int64, role domain.ParticipantRole) ([]domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	tasks := make([]domain.Task, 0)
+	for _, task := range ms.tasks {
+		participant, ok := ms.participants[task.ID][chatID]
+		if task.TeamID == teamID && ok && participant.Role == role {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (ms *MemoryStorage) AddTaskParticipant(ctx context.Context, participant domain.TaskParticipant) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	task := ms.tasksInProgress[chatID]
-	task.ID = len(ms.tasks) + 1
-	task.Title = name
-	ms.tasksInProgress[chatID] = task
+	if _, ok := ms.participants[participant.TaskID]; !ok {
+		ms.participants[participant.TaskID] = make(map[int64]domain.TaskParticipant)
+	}
+	ms.participants[participant.TaskID][participant.ChatID] = participant
 
 	return nil
 }
 
-func (ms *MemoryStorage) SetTaskInProgressUser(ctx context.Context, chatID int64, userContact string, userChatID int64) error {
+func (ms *MemoryStorage) RemoveTaskParticipant(ctx context.Context, taskID int, chatID int64) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	task := ms.tasksInProgress[chatID]
-	task.ExecutorContact = userContact
-	task.ExecutorChatID = userChatID
-	task.ID = len(ms.tasks) + 1
-	ms.tasksInProgress[chatID] = task
+	if _, ok := ms.participants[taskID][chatID]; !ok {
+		return errs.ErrNotFound
+	}
+	delete(ms.participants[taskID], chatID)
 
 	return nil
 }
 
-func (ms *MemoryStorage) SetTaskInProgressDeadline(ctx context.Context, chatID int64, deadline time.Time) error {
+func (ms *MemoryStorage) GetTaskParticipants(ctx context.Context, taskID int) ([]domain.TaskParticipant, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	participants := make([]domain.TaskParticipant, 0, len(ms.participants[taskID]))
+	for _, participant := range ms.participants[taskID] {
+		participants = append(participants, participant)
+	}
+	return participants, nil
+}
+
+func (ms *MemoryStorage) MarkParticipantDone(ctx context.Context, taskID int, chatID int64) (int, error) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	task := ms.tasksInProgress[chatID]
-	task.Deadline = deadline
-	task.ID = len(ms.tasks) + 1
-	ms.tasksInProgress[chatID] = task
+	participant, ok := ms.participants[taskID][chatID]
+	if !ok || participant.Role != domain.ExecutorParticipant {
+		return 0, errs.ErrNotFound
+	}
+	participant.Done = true
+	ms.participants[taskID][chatID] = participant
+
+	remaining := 0
+	for _, participant := range ms.participants[taskID] {
+		if participant.Role == domain.ExecutorParticipant && !participant.Done {
+			remaining++
+		}
+	}
+	return remaining, nil
+}
+
+func (ms *MemoryStorage) GetChat(ctx context.Context, username, phone string) (*domain.Chat, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var resultChat *domain.Chat
+	for _, chat := range ms.chats {
+		if chat.Username == username || chat.Phone == phone {
+			resultChat = ms.chatInTeam(chat, chat.TeamID)
+		}
+	}
+	return resultChat, nil
+}
+
+func (ms *MemoryStorage) GetChatByID(ctx context.Context, chatID int64) (*domain.Chat, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	chat, ok := ms.chats[chatID]
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+	return ms.chatInTeam(chat, chat.TeamID), nil
+}
+
+func (ms *MemoryStorage) GetChatsStats(ctx context.Context, teamID int64) ([]domain.ChatStats, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	stats := make([]domain.ChatStats, 0, len(ms.members[teamID]))
+	for chatID := range ms.members[teamID] {
+		chat, ok := ms.chats[chatID]
+		if !ok {
+			continue
+		}
+		chatStats := domain.ChatStats{Chat: *ms.chatInTeam(chat, teamID), ManagerID: ms.managers[teamID][chatID]}
+		for _, task := range ms.tasks {
+			if task.TeamID != teamID || task.Done || task.Closed {
+				continue
+			}
+			if task.IsExecutedBy(*chat) {
+				chatStats.OpenTasks++
+			}
+		}
+		stats = append(stats, chatStats)
+	}
+	return stats, nil
+}
+
+func (ms *MemoryStorage) SetBanned(ctx context.Context, teamID, chatID int64, banned bool) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.members[teamID][chatID]; !ok {
+		return errs.ErrNotFound
+	}
+	if _, ok := ms.bans[teamID]; !ok {
+		ms.bans[teamID] = make(map[int64]bool)
+	}
+	if !banned {
+		delete(ms.bans[teamID], chatID)
+		return nil
+	}
+	ms.bans[teamID][chatID] = true
 
 	return nil
 }
 
-func (ms *MemoryStorage) DebugStorage(ctx context.Context) (string, error) {
+func (ms *MemoryStorage) TouchChat(ctx context.Context, chatID int64, at time.Time) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	builder := strings.Builder{}
+	chat, ok := ms.chats[chatID]
+	if !ok {
+		return errs.ErrNotFound
+	}
+	chat.LastActivityAt = at
+
+	return nil
+}
+
+func (ms *MemoryStorage) MarkTaskAsDone(ctx context.Context, teamID int64, taskID int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i, task := range ms.tasks {
+		if task.TeamID == teamID && task.ID == taskID {
+			ms.tasks[i].Done = true
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) MarkTaskAsClosed(ctx context.Context, teamID int64, taskID int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i, task := range ms.tasks {
+		if task.TeamID == teamID && task.ID == taskID {
+			ms.tasks[i].Closed = true
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) DeleteTask(ctx context.Context, teamID int64, taskID int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i, task := range ms.tasks {
+		if task.TeamID == teamID && task.ID == taskID {
+			ms.tasks = append(ms.tasks[:i], ms.tasks[i+1:]...)
+			delete(ms.participants, taskID)
+			delete(ms.checklists, taskID)
+			delete(ms.dependencies, taskID)
+			delete(ms.reminders, taskID)
+			delete(ms.tags, taskID)
+			delete(ms.comments, taskID)
+			delete(ms.proofs, taskID)
+			maps.DeleteFunc(ms.taskMessages, func(_ taskMessageKey, id int) bool { return id == taskID })
+			delete(ms.taskCards, taskID)
+			ms.attachments = slices.DeleteFunc(ms.attachments, func(a domain.Attachment) bool { return a.TaskID == taskID })
+			for blockedID, blockerIDs := range ms.dependencies {
+				ms.dependencies[blockedID] = slices.DeleteFunc(blockerIDs, func(id int) bool { return id == taskID })
+			}
+			for j := range ms.tasks {
+				if ms.tasks[j].ParentID == taskID {
+					ms.tasks[j].ParentID = 0
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (ms *MemoryStorage) ChangeTaskDeadline(ctx context.Context, teamID int64, taskID int, newDeadline time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i, task := range ms.tasks {
+		if task.TeamID == teamID && task.ID == taskID {
+			ms.tasks[i].Deadline = newDeadline
+			ms.tasks[i].Expired = false
+			delete(ms.reminders, taskID)
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) SetTaskProofRequired(ctx context.Context, teamID int64, taskID int, required bool) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i, task := range ms.tasks {
+		if task.TeamID == teamID && task.ID == taskID {
+			ms.tasks[i].ProofRequired = required
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) LinkTasksByPhone(ctx context.Context, phone string, chatID int64) (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	linked := 0
+	for i, task := range ms.tasks {
+		if _, member := ms.members[task.TeamID][chatID]; !member {
+			continue
+		}
+		if task.ExecutorContact == phone && task.ExecutorChatID != chatID {
+			ms.tasks[i].ExecutorChatID = chatID
+			linked++
+		}
+	}
+	return linked, nil
+}
+
+func (ms *MemoryStorage) GetTasksToRemind(ctx context.Context, now time.Time) ([]domain.TaskReminder, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	reminders := make([]domain.TaskReminder, 0)
+	for _, task := range ms.tasks {
+		if task.IsFinished() || task.Expired || !now.Before(task.Deadline) {
+			continue
+		}
+		at := ms.reminders[task.ID]
+		if now.Before(at) {
+			continue
+		}
+		reminders = append(reminders, domain.TaskReminder{Task: task, At: at})
+	}
+	return reminders, nil
+}
+
+func (ms *MemoryStorage) SetTaskReminder(ctx context.Context, taskID int, at time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.reminders[taskID] = at
+	return nil
+}
+
+func (ms *MemoryStorage) GetOverdueTasks(ctx context.Context, priority domain.Priority) ([]domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	tasks := make([]domain.Task, 0)
+	for _, task := range ms.tasks {
+		if task.Expired && !task.IsFinished() && task.Priority == priority {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (ms *MemoryStorage) AddComment(ctx context.Context, comment domain.Comment) (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.lastCommentID++
+	comment.ID = ms.lastCommentID
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	ms.comments[comment.TaskID] = append(ms.comments[comment.TaskID], comment)
+	return comment.ID, nil
+}
+
+func (ms *MemoryStorage) GetComments(ctx context.Context, taskID int) ([]domain.Comment, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return slices.Clone(ms.comments[taskID]), nil
+}
+
+func (ms *MemoryStorage) GetCommentsCount(ctx context.Context, taskID int) (int, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return len(ms.comments[taskID]), nil
+}
+
+type taskMessageKey struct {
+	chatID    int64
+	messageID int
+}
+
+func (ms *MemoryStorage) AddTaskMessage(ctx context.Context, chatID int64, messageID, taskID int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.taskMessages[taskMessageKey{chatID: chatID, messageID: messageID}] = taskID
+	return nil
+}
+
+func (ms *MemoryStorage) GetMessageTask(ctx context.Context, chatID int64, messageID int) (int64, int, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	taskID, ok := ms.taskMessages[taskMessageKey{chatID: chatID, messageID: messageID}]
+	if !ok {
+		return 0, 0, errs.ErrNotFound
+	}
+	i := slices.IndexFunc(ms.tasks, func(task domain.Task) bool { return task.ID == taskID })
+	if i < 0 {
+		return 0, 0, errs.ErrNotFound
+	}
+	return ms.tasks[i].TeamID, taskID, nil
+}
+
+func (ms *MemoryStorage) SetTaskCard(ctx context.Context, taskID int, chatID int64, messageID int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.taskCards[taskID] == nil {
+		ms.taskCards[taskID] = make(map[int64]int)
+	}
+	ms.taskCards[taskID][chatID] = messageID
+	return nil
+}
+
+func (ms *MemoryStorage) GetTaskCards(ctx context.Context, taskID int) (map[int64]int, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return maps.Clone(ms.taskCards[taskID]), nil
+}
+
+func (ms *MemoryStorage) DeleteTaskCard(ctx context.Context, taskID int, chatID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.taskCards[taskID], chatID)
+	return nil
+}
+
+func (ms *MemoryStorage) SaveDashboard(ctx context.Context, dashboard domain.Dashboard) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.dashboards[dashboard.ChatID] = dashboard
+	return nil
+}
+
+func (ms *MemoryStorage) GetDashboards(ctx context.Context) ([]domain.Dashboard, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	dashboards := slices.Collect(maps.Values(ms.dashboards))
+	slices.SortFunc(dashboards, func(a, b domain.Dashboard) int { return cmp.Compare(a.ChatID, b.ChatID) })
+	return dashboards, nil
+}
+
+func (ms *MemoryStorage) DeleteDashboard(ctx context.Context, chatID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.dashboards[chatID]; !ok {
+		return errs.ErrNotFound
+	}
+	delete(ms.dashboards, chatID)
+	return nil
+}
+
+func (ms *MemoryStorage) SetChatInactive(ctx context.Context, chatID int64, reason string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.inactiveChats[chatID] = reason
+	return nil
+}
+
+func (ms *MemoryStorage) SetChatActive(ctx context.Context, chatID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.inactiveChats, chatID)
+	return nil
+}
+
+func (ms *MemoryStorage) GetInactiveChats(ctx context.Context) (map[int64]string, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return maps.Clone(ms.inactiveChats), nil
+}
+
+func (ms *MemoryStorage) ClaimUpdate(ctx context.Context, updateID int) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.handledUpdates[updateID]; ok {
+		return false, nil
+	}
+	ms.handledUpdates[updateID] = time.Now()
+	ms.lastUpdateID = max(ms.lastUpdateID, updateID)
+	return true, nil
+}
+
+func (ms *MemoryStorage) GetLastUpdateID(ctx context.Context) (int, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.lastUpdateID, nil
+}
+
+func (ms *MemoryStorage) DeleteHandledUpdates(ctx context.Context, before time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	maps.DeleteFunc(ms.handledUpdates, func(updateID int, handledAt time.Time) bool {
+		return handledAt.Before(before) && updateID < ms.lastUpdateID
+	})
+	return nil
+}
+
+func (ms *MemoryStorage) AddAttachment(ctx context.Context, attachment domain.Attachment) (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.lastAttachmentID++
+	attachment.ID = ms.lastAttachmentID
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now()
+	}
+	ms.attachments = append(ms.attachments, attachment)
+	return attachment.ID, nil
+}
+
+func (ms *MemoryStorage) BindAttachments(ctx context.Context, chatID int64, taskID int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.attachments {
+		if ms.attachments[i].TaskID == 0 && ms.attachments[i].ChatID == chatID {
+			ms.attachments[i].TaskID = taskID
+		}
+	}
+	return nil
+}
+
+func (ms *MemoryStorage) DeletePendingAttachments(ctx context.Context, chatID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.attachments = slices.DeleteFunc(ms.attachments, func(a domain.Attachment) bool { return a.TaskID == 0 && a.ChatID == chatID })
+	return nil
+}
+
+func (ms *MemoryStorage) GetAttachments(ctx context.Context, taskID int) ([]domain.Attachment, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var attachments []domain.Attachment
+	for _, attachment := range ms.attachments {
+		if attachment.TaskID == taskID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (ms *MemoryStorage) AddProof(ctx context.Context, proof domain.Proof) (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.lastProofID++
+	proof.ID = ms.lastProofID
+	if proof.CreatedAt.IsZero() {
+		proof.CreatedAt = time.Now()
+	}
+	ms.proofs[proof.TaskID] = append(ms.proofs[proof.TaskID], proof)
+	return proof.ID, nil
+}
+
+func (ms *MemoryStorage) GetProofs(ctx context.Context, taskID int) ([]domain.Proof, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return slices.Clone(ms.proofs[taskID]), nil
+}
+
+func (ms *MemoryStorage) GetTaskInProgress(ctx context.Context, chatID int64) (domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.tasksInProgress[chatID], nil
+}
+
+func (ms *MemoryStorage) SetTaskInProgressName(ctx context.Context, chatID int64, name string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	task := ms.tasksInProgress[chatID]
+	task.ID = len(ms.tasks) + 1
+	task.Title = name
+	ms.tasksInProgress[chatID] = task
+
+	return nil
+}
+
+func (ms *MemoryStorage) SetTaskInProgressUser(ctx context.Context, chatID int64, userContact string, userChatID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	task := ms.tasksInProgress[chatID]
+	task.ExecutorContact = userContact
+	task.ExecutorChatID = userChatID
+	task.ID = len(ms.tasks) + 1
+	ms.tasksInProgress[chatID] = task
+
+	return nil
+}
+
+func (ms *MemoryStorage) SetTaskInProgressDeadline(ctx context.Context, chatID int64, deadline time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	task := ms.tasksInProgress[chatID]
+	task.Deadline = deadline
+	task.ID = len(ms.tasks) + 1
+	ms.tasksInProgress[chatID] = task
+
+	return nil
+}
+
+func (ms *MemoryStorage) SetTaskInProgressPriority(ctx context.Context, chatID int64, priority domain.Priority) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	task := ms.tasksInProgress[chatID]
+	task.Priority = priority
+	task.ID = len(ms.tasks) + 1
+	ms.tasksInProgress[chatID] = task
+
+	return nil
+}
+
+func (ms *MemoryStorage) AddRecurrence(ctx context.Context, recurrence domain.Recurrence) (int64, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.lastRecurrence++
+	recurrence.ID = ms.lastRecurrence
+	ms.recurrences = append(ms.recurrences, recurrence)
+	return recurrence.ID, nil
+}
+
+func (ms *MemoryStorage) GetRecurrence(ctx context.Context, teamID, recurrenceID int64) (domain.Recurrence, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for _, recurrence := range ms.recurrences {
+		if recurrence.ID == recurrenceID && recurrence.TeamID == teamID {
+			return recurrence, nil
+		}
+	}
+	return domain.Recurrence{}, errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) GetRecurrences(ctx context.Context, teamID int64) ([]domain.Recurrence, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	recurrences := make([]domain.Recurrence, 0)
+	for _, recurrence := range ms.recurrences {
+		if recurrence.TeamID == teamID {
+			recurrences = append(recurrences, recurrence)
+		}
+	}
+	return recurrences, nil
+}
+
+func (ms *MemoryStorage) GetDueRecurrences(ctx context.Context, now time.Time) ([]domain.Recurrence, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	recurrences := make([]domain.Recurrence, 0)
+	for _, recurrence := range ms.recurrences {
+		if !recurrence.Paused && !recurrence.NextRun.After(now) {
+			recurrences = append(recurrences, recurrence)
+		}
+	}
+	return recurrences, nil
+}
+
+func (ms *MemoryStorage) AdvanceRecurrence(ctx context.Context, recurrenceID int64, from, to time.Time) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.recurrences {
+		if ms.recurrences[i].ID == recurrenceID && ms.recurrences[i].NextRun.Equal(from) {
+			ms.recurrences[i].NextRun = to
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ms *MemoryStorage) SetRecurrencePaused(ctx context.Context, teamID, recurrenceID int64, paused bool, nextRun time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for i := range ms.recurrences {
+		if ms.recurrences[i].ID == recurrenceID && ms.recurrences[i].TeamID == teamID {
+			ms.recurrences[i].Paused = paused
+			ms.recurrences[i].NextRun = nextRun
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (ms *MemoryStorage) DeleteRecurrence(ctx context.Context, teamID, recurrenceID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	i := slices.IndexFunc(ms.recurrences, func(recurrence domain.Recurrence) bool {
+		return recurrence.ID == recurrenceID && recurrence.TeamID == teamID
+	})
+	if i < 0 {
+		return errs.ErrNotFound
+	}
+	ms.recurrences = slices.Delete(ms.recurrences, i, i+1)
+	return nil
+}
+
+func (ms *MemoryStorage) SaveTemplate(ctx context.Context, template domain.TaskTemplate) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.templates[template.TeamID] == nil {
+		ms.templates[template.TeamID] = make(map[string]domain.TaskTemplate)
+	}
+	ms.templates[template.TeamID][template.Name] = template
+	return nil
+}
+
+func (ms *MemoryStorage) GetTemplate(ctx context.Context, teamID int64, name string) (domain.TaskTemplate, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	template, ok := ms.templates[teamID][name]
+	if !ok {
+		return domain.TaskTemplate{}, errs.ErrNotFound
+	}
+	return template, nil
+}
+
+func (ms *MemoryStorage) GetTemplates(ctx context.Context, teamID int64) ([]domain.TaskTemplate, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	templates := make([]domain.TaskTemplate, 0, len(ms.templates[teamID]))
+	for _, template := range ms.templates[teamID] {
+		templates = append(templates, template)
+	}
+	slices.SortFunc(templates, func(a, b domain.TaskTemplate) int { return strings.Compare(a.Name, b.Name) })
+	return templates, nil
+}
+
+func (ms *MemoryStorage) DeleteTemplate(ctx context.Context, teamID int64, name string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.templates[teamID][name]; !ok {
+		return errs.ErrNotFound
+	}
+	delete(ms.templates[teamID], name)
+	return nil
+}
+
+func (ms *MemoryStorage) GetTasks(ctx context.Context, filter domain.TaskFilter) ([]domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.filterTasks(filter), nil
+}
+
+// SearchTasks matches stems of the query as substrings, tasks matching in the title go first, then matching in the description or comments.
+func (ms *MemoryStorage) SearchTasks(ctx context.Context, filter domain.TaskFilter, query string, limit, offset int) ([]domain.Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	terms := domain.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	contains := func(text string) bool {
+		text = domain.NormalizeSearchText(text)
+		return !slices.ContainsFunc(terms, func(term string) bool { return !strings.Contains(text, term) })
+	}
+	var inTitle, inText []domain.Task
+	for _, task := range ms.filterTasks(filter) {
+		switch {
+		case contains(task.Title):
+			inTitle = append(inTitle, task)
+		case contains(task.Title + " " + task.Description + " " + ms.commentsText(task.ID)):
+			inText = append(inText, task)
+		}
+	}
+	found := append(inTitle, inText...)
+	if offset >= len(found) {
+		return nil, nil
+	}
+	return found[offset:min(offset+limit, len(found))], nil
+}
+
+// commentsText joins texts of the task comments, caller must hold the lock.
+func (ms *MemoryStorage) commentsText(taskID int) string {
+	texts := make([]string, 0, len(ms.comments[taskID]))
+	for _, comment := range ms.comments[taskID] {
+		texts = append(texts, comment.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+// filterTasks returns tasks matching the filter, caller must hold the lock.
+func (ms *MemoryStorage) filterTasks(filter domain.TaskFilter) []domain.Task {
+	var subordinates []domain.Chat
+	if filter.ManagerID != 0 {
+		subordinates = ms.subordinates(filter.TeamID, filter.ManagerID)
+	}
+	now := time.Now()
+	tasks := make([]domain.Task, 0)
+	for _, task := range ms.tasks {
+		switch {
+		case task.TeamID != filter.TeamID,
+			filter.Open && task.IsFinished(),
+			filter.Done && !task.Done,
+			filter.Closed && !task.Closed,
+			filter.Overdue && (task.IsFinished() || (!task.Expired && now.Before(task.Deadline))),
+			len(filter.Priorities) > 0 && !slices.Contains(filter.Priorities, task.Priority),
+			slices.ContainsFunc(filter.Tags, func(tag string) bool { return !slices.Contains(ms.tags[task.ID], tag) }):
+			continue
+		}
+		if filter.ManagerID != 0 && !slices.ContainsFunc(subordinates, task.IsExecutedBy) {
+			continue
+		}
+		if filter.Executor != nil && !task.IsExecutedBy(*filter.Executor) {
+			participant, ok := ms.participants[task.ID][filter.Executor.ID]
+			if !ok || participant.Role != domain.ExecutorParticipant {
+				continue
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func (ms *MemoryStorage) SetTaskTags(ctx context.Context, teamID int64, taskID int, tags []string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if len(tags) == 0 {
+		delete(ms.tags, taskID)
+		return nil
+	}
+	ms.tags[taskID] = slices.Clone(tags)
+	return nil
+}
+
+func (ms *MemoryStorage) GetTasksTags(ctx context.Context, taskIDs []int) (map[int][]string, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	tags := make(map[int][]string, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if taskTags, ok := ms.tags[taskID]; ok {
+			tags[taskID] = slices.Sorted(slices.Values(taskTags))
+		}
+	}
+	return tags, nil
+}
+
+type viewKey struct {
+	teamID int64
+	chatID int64
+}
+
+func (ms *MemoryStorage) SaveView(ctx context.Context, view domain.SavedView) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	key := viewKey{teamID: view.TeamID, chatID: view.ChatID}
+	if ms.views[key] == nil {
+		ms.views[key] = make(map[string]domain.SavedView)
+	}
+	ms.views[key][view.Name] = view
+	return nil
+}
+
+func (ms *MemoryStorage) GetView(ctx context.Context, teamID, chatID int64, name string) (domain.SavedView, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	view, ok := ms.views[viewKey{teamID: teamID, chatID: chatID}][name]
+	if !ok {
+		return domain.SavedView{}, errs.ErrNotFound
+	}
+	return view, nil
+}
+
+func (ms *MemoryStorage) GetViews(ctx context.Context, teamID, chatID int64) ([]domain.SavedView, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	chatViews := ms.views[viewKey{teamID: teamID, chatID: chatID}]
+	views := make([]domain.SavedView, 0, len(chatViews))
+	for _, view := range chatViews {
+		views = append(views, view)
+	}
+	slices.SortFunc(views, func(a, b domain.SavedView) int { return strings.Compare(a.Name, b.Name) })
+	return views, nil
+}
+
+func (ms *MemoryStorage) DeleteView(ctx context.Context, teamID, chatID int64, name string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	key := viewKey{teamID: teamID, chatID: chatID}
+	if _, ok := ms.views[key][name]; !ok {
+		return errs.ErrNotFound
+	}
+	delete(ms.views[key], name)
+	return nil
+}
+
+func (ms *MemoryStorage) AddInvite(ctx context.Context, invite domain.Invite) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.invites[invite.Code]; ok {
+		return fmt.Errorf("invite %s already exists: %w", invite.Code, errs.ErrInvalidInput)
+	}
+	ms.invites[invite.Code] = &invite
+
+	return nil
+}
+
+func (ms *MemoryStorage) GetActiveInvites(ctx context.Context, teamID int64) ([]domain.Invite, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	now := time.Now()
+	invites := make([]domain.Invite, 0, len(ms.invites))
+	for _, invite := range ms.invites {
+		if invite.TeamID == teamID && invite.IsActive(now) {
+			invites = append(invites, *invite)
+		}
+	}
+	return invites, nil
+}
+
+func (ms *MemoryStorage) RedeemInvite(ctx context.Context, code string, chatID int64, username string) (domain.Invite, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	invite, ok := ms.invites[code]
+	if !ok || !invite.IsActive(now) || !invite.CanBeRedeemedBy(username) {
+		return domain.Invite{}, errs.ErrNotFound
+	}
+	invite.RedeemedBy = chatID
+	invite.RedeemedAt = now
+
+	return *invite, nil
+}
+
+func (ms *MemoryStorage) RevokeInvite(ctx context.Context, teamID int64, code string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	invite, ok := ms.invites[code]
+	if !ok || invite.TeamID != teamID || invite.RedeemedBy != 0 {
+		return errs.ErrNotFound
+	}
+	invite.Revoked = true
+
+	return nil
+}
+
+func (ms *MemoryStorage) GetPasswordAttempts(ctx context.Context, chatID int64) (domain.PasswordAttempts, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	attempts, ok := ms.passwords[chatID]
+	if !ok {
+		return domain.PasswordAttempts{ChatID: chatID}, errs.ErrNotFound
+	}
+	return attempts, nil
+}
+
+func (ms *MemoryStorage) RegisterPasswordFailure(ctx context.Context, chatID int64, now time.Time) (domain.PasswordAttempts, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	attempts, ok := ms.passwords[chatID]
+	if !ok {
+		attempts = domain.PasswordAttempts{ChatID: chatID}
+	}
+	if attempts.IsLocked(now) {
+		return attempts, nil
+	}
+	attempts.Failures++
+	ms.passwords[chatID] = attempts
+	return attempts, nil
+}
+
+func (ms *MemoryStorage) LockPasswordAttempts(ctx context.Context, chatID int64, lockouts int, until time.Time) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	attempts, ok := ms.passwords[chatID]
+	if !ok || attempts.Lockouts != lockouts {
+		return false, nil
+	}
+	attempts.Failures = 0
+	attempts.Lockouts++
+	attempts.LockedUntil = until
+	ms.passwords[chatID] = attempts
+	return true, nil
+}
+
+func (ms *MemoryStorage) GetFailedPasswordAttempts(ctx context.Context) ([]domain.PasswordAttempts, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	result := make([]domain.PasswordAttempts, 0, len(ms.passwords))
+	for _, attempts := range ms.passwords {
+		if attempts.Failures > 0 || attempts.Lockouts > 0 {
+			result = append(result, attempts)
+		}
+	}
+	return result, nil
+}
+
+func (ms *MemoryStorage) ResetPasswordAttempts(ctx context.Context, chatID int64) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.passwords[chatID]; !ok {
+		return errs.ErrNotFound
+	}
+	delete(ms.passwords, chatID)
+	return nil
+}
+
+func (ms *MemoryStorage) AddAdminAction(ctx context.Context, action domain.AdminAction) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	action.ID = len(ms.adminActions) + 1
+	action.CreatedAt = time.Now()
+	ms.adminActions = append(ms.adminActions, action)
+
+	return nil
+}
+
+func (ms *MemoryStorage) GetAdminActions(ctx context.Context, teamID int64, limit int) ([]domain.AdminAction, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	actions := make([]domain.AdminAction, 0, limit)
+	for i := len(ms.adminActions) - 1; i >= 0 && len(actions) < limit; i-- {
+		if ms.adminActions[i].TeamID == teamID {
+			actions = append(actions, ms.adminActions[i])
+		}
+	}
+	return actions, nil
+}
+
+func (ms *MemoryStorage) DebugStorage(ctx context.Context) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	builder := strings.Builder{}
+	for _, team := range ms.teams {
+		builder.WriteString(fmt.Sprintf("team: %+v, members: %v\n", team, ms.members[team.ID]))
+	}
+
+	builder.WriteString("\n")
 	for id, chat := range ms.chats {
 		builder.WriteString(fmt.Sprintf("chat with id: %d, chat: %+v\n", id, chat))
 	}
@@ -1,13 +1,20 @@
 package postgres
 
 import (
+	"fmt"
 	"tasks_bot/internal/domain"
 	queries "tasks_bot/internal/repository/postgres/sqlc"
+	"time"
 )
 
 func TaskToDomain(task *queries.Task) domain.Task {
+	var parentID int
+	if task.ParentID.Valid {
+		parentID = int(task.ParentID.Int64) + 1
+	}
 	return domain.Task{
 		ID:              int(task.ID) + 1,
+		TeamID:          task.TeamID,
 		Title:           task.Title,
 		ExecutorContact: task.ExecutorContact,
 		ExecutorChatID:  task.ExecutorChatID.Int64,
@@ -15,14 +22,197 @@ func TaskToDomain(task *queries.Task) domain.Task {
 		Done:            task.Done,
 		Expired:         task.Expired,
 		Closed:          task.Closed,
+		ParentID:        parentID,
+		Description:     task.Description,
+		Priority:        domain.Priority(task.Priority),
+		CreatedBy:       task.CreatedBy.Int64,
+		ProofRequired:   task.ProofRequired,
 	}
 }
 
-func ChatToDomain(chat *queries.Chat) *domain.Chat {
+// ChatToDomain converts chat with its role in the active team.
+func ChatToDomain(chat *queries.Chat, teamRole int32) *domain.Chat {
 	return &domain.Chat{
-		ID:       chat.ChatID,
-		Username: chat.Username.String,
-		Stage:    domain.Stage(chat.Stage.Int32),
-		Role:     domain.Role(chat.Role.Int32),
+		ID:             chat.ChatID,
+		TeamID:         chat.TeamID,
+		Username:       chat.Username.String,
+		Phone:          chat.Phone.String,
+		Stage:          domain.Stage(chat.Stage.Int32),
+		Role:           domain.Role(teamRole),
+		LastActivityAt: chat.LastActivityAt.Time,
+	}
+}
+
+func ChatStatsToDomain(chat *queries.GetChatsStatsRow, teamID int64) domain.ChatStats {
+	return domain.ChatStats{
+		Chat: domain.Chat{
+			ID:             chat.ChatID,
+			TeamID:         teamID,
+			Username:       chat.Username.String,
+			Phone:          chat.Phone.String,
+			Stage:          domain.Stage(chat.Stage.Int32),
+			Role:           domain.Role(chat.Role),
+			Banned:         chat.Banned,
+			LastActivityAt: chat.LastActivityAt.Time,
+		},
+		ManagerID: chat.ManagerID.Int64,
+		OpenTasks: int(chat.OpenTasks),
+	}
+}
+
+func TaskParticipantToDomain(participant *queries.TaskParticipant) domain.TaskParticipant {
+	return domain.TaskParticipant{
+		TaskID: int(participant.TaskID) + 1,
+		ChatID: participant.ChatID,
+		Role:   domain.ParticipantRole(participant.Role),
+		Done:   participant.Done,
+	}
+}
+
+func ChecklistItemToDomain(item *queries.ChecklistItem) domain.ChecklistItem {
+	return domain.ChecklistItem{
+		ID:     int(item.ID),
+		TaskID: int(item.TaskID) + 1,
+		Title:  item.Title,
+		Done:   item.Done,
+	}
+}
+
+func AdminActionToDomain(action *queries.AdminAction) domain.AdminAction {
+	return domain.AdminAction{
+		ID:           int(action.ID),
+		TeamID:       action.TeamID,
+		AdminChatID:  action.AdminChatID,
+		TargetChatID: action.TargetChatID,
+		Action:       action.Action,
+		Details:      action.Details,
+		CreatedAt:    action.CreatedAt.Time,
+	}
+}
+
+func InviteToDomain(invite *queries.Invite) domain.Invite {
+	return domain.Invite{
+		Code:       invite.Code,
+		TeamID:     invite.TeamID,
+		Role:       domain.Role(invite.Role),
+		Username:   invite.Username.String,
+		CreatedBy:  invite.CreatedBy,
+		RedeemedBy: invite.RedeemedBy.Int64,
+		RedeemedAt: invite.RedeemedAt.Time,
+		ExpiresAt:  invite.ExpiresAt.Time,
+		Revoked:    invite.Revoked,
+	}
+}
+
+// RecurrenceToDomain converts recurrence, its next run is kept in UTC.
+func RecurrenceToDomain(recurrence *queries.Recurrence) (domain.Recurrence, error) {
+	schedule, err := domain.ParseSchedule(recurrence.Schedule)
+	if err != nil {
+		return domain.Recurrence{}, fmt.Errorf("domain.ParseSchedule: %w", err)
+	}
+	return domain.Recurrence{
+		ID:              recurrence.ID,
+		TeamID:          recurrence.TeamID,
+		Title:           recurrence.Title,
+		ExecutorContact: recurrence.ExecutorContact,
+		ExecutorChatID:  recurrence.ExecutorChatID.Int64,
+		Schedule:        schedule,
+		DeadlineIn:      time.Duration(recurrence.DeadlineIn) * time.Second,
+		NextRun:         recurrence.NextRun.Time.Local(),
+		Paused:          recurrence.Paused,
+		CreatedBy:       recurrence.CreatedBy,
+	}, nil
+}
+
+func TemplateToDomain(template *queries.TaskTemplate) domain.TaskTemplate {
+	return domain.TaskTemplate{
+		TeamID:          template.TeamID,
+		Name:            template.Name,
+		TitlePattern:    template.TitlePattern,
+		ExecutorContact: template.ExecutorContact,
+		DeadlineIn:      time.Duration(template.DeadlineIn) * time.Second,
+		Priority:        domain.Priority(template.Priority),
+		Description:     template.Description,
+		Checklist:       template.Checklist,
+		CreatedBy:       template.CreatedBy,
+		ProofRequired:   template.ProofRequired,
+	}
+}
+
+func CommentToDomain(comment *queries.TaskComment) domain.Comment {
+	return domain.Comment{
+		ID:        int(comment.ID),
+		TaskID:    int(comment.TaskID) + 1,
+		ChatID:    comment.ChatID,
+		Author:    comment.Author,
+		Text:      comment.Text,
+		CreatedAt: comment.CreatedAt.Time,
+	}
+}
+
+func AttachmentToDomain(attachment *queries.TaskAttachment) domain.Attachment {
+	result := domain.Attachment{
+		ID:           int(attachment.ID),
+		ChatID:       attachment.ChatID,
+		Kind:         domain.AttachmentKind(attachment.Kind),
+		FileID:       attachment.FileID,
+		FileUniqueID: attachment.FileUniqueID,
+		FileName:     attachment.FileName,
+		MimeType:     attachment.MimeType,
+		FileSize:     int(attachment.FileSize),
+		LocalPath:    attachment.LocalPath,
+		CreatedAt:    attachment.CreatedAt.Time,
+	}
+	if attachment.TaskID.Valid {
+		result.TaskID = int(attachment.TaskID.Int64) + 1
+	}
+	return result
+}
+
+func ProofToDomain(proof *queries.TaskProof) domain.Proof {
+	return domain.Proof{
+		ID:        int(proof.ID),
+		TaskID:    int(proof.TaskID) + 1,
+		ChatID:    proof.ChatID,
+		Author:    proof.Author,
+		Kind:      domain.ProofKind(proof.Kind),
+		FileID:    proof.FileID,
+		Text:      proof.Text,
+		Latitude:  proof.Latitude,
+		Longitude: proof.Longitude,
+		CreatedAt: proof.CreatedAt.Time,
+	}
+}
+
+func ViewToDomain(view *queries.SavedView) domain.SavedView {
+	return domain.SavedView{
+		TeamID: view.TeamID,
+		ChatID: view.ChatID,
+		Name:   view.Name,
+		Query:  view.Query,
+	}
+}
+
+func PasswordAttemptsToDomain(attempts *queries.PasswordAttempt) domain.PasswordAttempts {
+	return domain.PasswordAttempts{
+		ChatID:      attempts.ChatID,
+		Failures:    int(attempts.Failures),
+		Lockouts:    int(attempts.Lockouts),
+		LockedUntil: attempts.LockedUntil.Time,
+	}
+}
+
+func TeamToDomain(team *queries.Team) domain.Team {
+	return domain.Team{
+		ID:   team.ID,
+		Name: team.Name,
+	}
+}
+
+func DashboardToDomain(dashboard *queries.Dashboard) domain.Dashboard {
+	return domain.Dashboard{
+		ChatID:    dashboard.ChatID,
+		TeamID:    dashboard.TeamID,
+		MessageID: int(dashboard.MessageID),
 	}
 }
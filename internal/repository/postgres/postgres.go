@@ -38,12 +38,11 @@ func (p *Writable) DebugStorage(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("not implemented")
 }
 
-func (p *Writable) AddChat(ctx context.Context, chatID int64, username, phone string, role domain.Role) error {
+func (p *Writable) AddChat(ctx context.Context, chatID int64, username, phone string) error {
 	err := queries.New(p.db).AddChat(ctx, &queries.AddChatParams{
 		ChatID:   chatID,
 		Username: pgtype.Text{String: username, Valid: true},
-		Phone:    pgtype.Text{String: phone, Valid: true},
-		Role:     pgtype.Int4{Int32: int32(role), Valid: true},
+		Phone:    pgtype.Text{String: phone, Valid: phone != ""},
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -65,29 +64,82 @@ func (p *Writable) GetChat(ctx context.Context, username, phone string) (*domain
 		}
 		return nil, fmt.Errorf("pgx.Query: %w", err)
 	}
-	return &domain.Chat{
-		ID:       chat.ChatID,
-		Username: username,
-		Stage:    domain.Stage(chat.Stage.Int32),
-		Role:     domain.Role(chat.Role.Int32),
-	}, nil
+	return ChatToDomain(&chat.Chat, chat.TeamRole), nil
+}
+
+func (p *Writable) GetChatByID(ctx context.Context, chatID int64) (*domain.Chat, error) {
+	chat, err := queries.New(p.db).GetChatByID(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	result := ChatToDomain(&chat.Chat, chat.TeamRole)
+	result.Banned = chat.TeamBanned
+	return result, nil
+}
+
+func (p *Writable) GetChatsStats(ctx context.Context, teamID int64) ([]domain.ChatStats, error) {
+	queriesChats, err := queries.New(p.db).GetChatsStats(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	stats := make([]domain.ChatStats, 0, len(queriesChats))
+	for _, chat := range queriesChats {
+		stats = append(stats, ChatStatsToDomain(chat, teamID))
+	}
+	return stats, nil
+}
+
+func (p *Writable) SetBanned(ctx context.Context, teamID, chatID int64, banned bool) error {
+	affectedRows, err := queries.New(p.db).SetBanned(ctx, &queries.SetBannedParams{
+		TeamID: teamID,
+		ChatID: chatID,
+		Banned: banned,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) TouchChat(ctx context.Context, chatID int64, at time.Time) error {
+	err := queries.New(p.db).TouchChat(ctx, &queries.TouchChatParams{
+		ChatID:         chatID,
+		LastActivityAt: pgtype.Timestamp{Time: at, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
 }
 
-func (p *Writable) GetRole(ctx context.Context, chatID int64) (domain.Role, error) {
-	role, err := queries.New(p.db).GetRole(ctx, chatID)
+func (p *Writable) GetRole(ctx context.Context, teamID, chatID int64) (domain.Role, error) {
+	role, err := queries.New(p.db).GetRole(ctx, &queries.GetRoleParams{
+		TeamID: teamID,
+		ChatID: chatID,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.UnknownRole, errs.ErrNotFound
 		}
 		return domain.UnknownRole, fmt.Errorf("pgx.Query: %w", err)
 	}
-	return domain.Role(role.Int32), nil
+	return domain.Role(role), nil
 }
 
-func (p *Writable) SetRole(ctx context.Context, chatID int64, role domain.Role) error {
+func (p *Writable) SetRole(ctx context.Context, teamID, chatID int64, role domain.Role) error {
 	err := queries.New(p.db).SetRole(ctx, &queries.SetRoleParams{
+		TeamID: teamID,
 		ChatID: chatID,
-		Role:   pgtype.Int4{Int32: int32(role), Valid: true},
+		Role:   int32(role),
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -98,6 +150,57 @@ func (p *Writable) SetRole(ctx context.Context, chatID int64, role domain.Role)
 	return nil
 }
 
+func (p *Writable) AddTeam(ctx context.Context, name string) (domain.Team, error) {
+	team, err := queries.New(p.db).AddTeam(ctx, name)
+	if err != nil {
+		return domain.Team{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return TeamToDomain(team), nil
+}
+
+func (p *Writable) GetTeamByName(ctx context.Context, name string) (domain.Team, error) {
+	team, err := queries.New(p.db).GetTeamByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Team{}, errs.ErrNotFound
+		}
+		return domain.Team{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return TeamToDomain(team), nil
+}
+
+func (p *Writable) GetChatTeams(ctx context.Context, chatID int64) ([]domain.TeamMembership, error) {
+	queriesTeams, err := queries.New(p.db).GetChatTeams(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	memberships := make([]domain.TeamMembership, 0, len(queriesTeams))
+	for _, team := range queriesTeams {
+		memberships = append(memberships, domain.TeamMembership{
+			Team: domain.Team{ID: team.ID, Name: team.Name},
+			Role: domain.Role(team.Role),
+		})
+	}
+	return memberships, nil
+}
+
+func (p *Writable) SetActiveTeam(ctx context.Context, chatID, teamID int64) error {
+	affectedRows, err := queries.New(p.db).SetActiveTeam(ctx, &queries.SetActiveTeamParams{
+		ChatID: chatID,
+		TeamID: teamID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
 func (p *Writable) SetStage(ctx context.Context, chatID int64, stage domain.Stage) error {
 	err := queries.New(p.db).SetStage(ctx, &queries.SetStageParams{
 		ChatID: chatID,
@@ -123,8 +226,70 @@ func (p *Writable) GetStage(ctx context.Context, chatID int64) (domain.Stage, er
 	return domain.Stage(role.Int32), nil
 }
 
-func (p *Writable) GetObservers(ctx context.Context) (map[int64]*domain.Chat, error) {
-	queriesObservers, err := queries.New(p.db).GetObservers(ctx)
+func (p *Writable) SetManager(ctx context.Context, teamID, chatID, managerID int64) error {
+	affectedRows, err := queries.New(p.db).SetManager(ctx, &queries.SetManagerParams{
+		TeamID:    teamID,
+		ChatID:    chatID,
+		ManagerID: pgtype.Int8{Int64: managerID, Valid: managerID != 0},
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) GetSubordinates(ctx context.Context, teamID, managerID int64) ([]domain.Chat, error) {
+	queriesChats, err := queries.New(p.db).GetSubordinates(ctx, &queries.GetSubordinatesParams{
+		TeamID:    teamID,
+		ManagerID: pgtype.Int8{Int64: managerID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	subordinates := make([]domain.Chat, 0, len(queriesChats))
+	for _, chat := range queriesChats {
+		subordinate := ChatToDomain(chat, int32(domain.Executor))
+		subordinate.TeamID = teamID
+		subordinates = append(subordinates, *subordinate)
+	}
+	return subordinates, nil
+}
+
+func (p *Writable) GetSubordinatesTasks(ctx context.Context, teamID, managerID int64) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetSubordinatesTasks(ctx, &queries.GetSubordinatesTasksParams{
+		TeamID:    teamID,
+		ManagerID: pgtype.Int8{Int64: managerID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tasks := make([]domain.Task, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		tasks = append(tasks, TaskToDomain(task))
+	}
+	return tasks, nil
+}
+
+func (p *Writable) GetExecutorManager(ctx context.Context, teamID, executorChatID int64, executorContact string) (int64, error) {
+	managerID, err := queries.New(p.db).GetExecutorManager(ctx, &queries.GetExecutorManagerParams{
+		TeamID:   teamID,
+		ChatID:   executorChatID,
+		Username: pgtype.Text{String: executorContact, Valid: executorContact != ""},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errs.ErrNotFound
+		}
+		return 0, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return managerID.Int64, nil
+}
+
+func (p *Writable) GetObservers(ctx context.Context, teamID int64) (map[int64]*domain.Chat, error) {
+	queriesObservers, err := queries.New(p.db).GetObservers(ctx, teamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNotFound
@@ -133,7 +298,7 @@ func (p *Writable) GetObservers(ctx context.Context) (map[int64]*domain.Chat, er
 	}
 	observers := make(map[int64]*domain.Chat, len(queriesObservers))
 	for _, observer := range queriesObservers {
-		observers[observer.ChatID] = ChatToDomain(observer)
+		observers[observer.ChatID] = ChatToDomain(observer, int32(domain.Observer))
 	}
 	return observers, nil
 }
@@ -144,6 +309,11 @@ func (p *Writable) AddTask(ctx context.Context, task domain.Task) (int, error) {
 		ExecutorContact: task.ExecutorContact,
 		ExecutorChatID:  pgtype.Int8{Int64: task.ExecutorChatID, Valid: true},
 		Deadline:        pgtype.Timestamp{Time: task.Deadline, Valid: true},
+		TeamID:          task.TeamID,
+		Description:     task.Description,
+		Priority:        int32(task.Priority),
+		CreatedBy:       pgtype.Int8{Int64: task.CreatedBy, Valid: task.CreatedBy != 0},
+		ProofRequired:   task.ProofRequired,
 	})
 	if err != nil {
 		return -1, fmt.Errorf("pgx.Query: %w", err)
@@ -151,8 +321,8 @@ func (p *Writable) AddTask(ctx context.Context, task domain.Task) (int, error) {
 	return int(taskID + 1), nil
 }
 
-func (p *Writable) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
-	queriesTasks, err := queries.New(p.db).GetAllTasks(ctx)
+func (p *Writable) GetAllTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetAllTasks(ctx, teamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNotFound
@@ -166,8 +336,8 @@ func (p *Writable) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (p *Writable) GetClosedTasks(ctx context.Context) ([]domain.Task, error) {
-	queriesTasks, err := queries.New(p.db).GetClosedTasks(ctx)
+func (p *Writable) GetClosedTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetClosedTasks(ctx, teamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNotFound
@@ -181,8 +351,8 @@ func (p *Writable) GetClosedTasks(ctx context.Context) ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (p *Writable) GetOpenTasks(ctx context.Context) ([]domain.Task, error) {
-	queriesTasks, err := queries.New(p.db).GetOpenTasks(ctx)
+func (p *Writable) GetOpenTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetOpenTasks(ctx, teamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNotFound
@@ -196,8 +366,8 @@ func (p *Writable) GetOpenTasks(ctx context.Context) ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (p *Writable) GetDoneTasks(ctx context.Context) ([]domain.Task, error) {
-	queriesTasks, err := queries.New(p.db).GetDoneTasks(ctx)
+func (p *Writable) GetDoneTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetDoneTasks(ctx, teamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNotFound
@@ -211,9 +381,9 @@ func (p *Writable) GetDoneTasks(ctx context.Context) ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (p *Writable) GetExpiredTasks(ctx context.Context) ([]domain.Task, error) {
+func (p *Writable) GetExpiredTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
 	q := queries.New(p.db)
-	queriesTasks, err := q.GetExpiredTasks(ctx)
+	queriesTasks, err := q.GetExpiredTasks(ctx, teamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrNotFound
@@ -247,8 +417,9 @@ func (p *Writable) GetExpiredTasksToMark(ctx context.Context) ([]domain.Task, er
 	return tasks, nil
 }
 
-func (p *Writable) GetUserTasks(ctx context.Context, username, phone string) ([]domain.Task, error) {
+func (p *Writable) GetUserTasks(ctx context.Context, teamID int64, username, phone string) ([]domain.Task, error) {
 	queriesTasks, err := queries.New(p.db).GetUserTasks(ctx, &queries.GetUserTasksParams{
+		TeamID:            teamID,
 		ExecutorContact:   username,
 		ExecutorContact_2: phone,
 	})
@@ -265,8 +436,42 @@ func (p *Writable) GetUserTasks(ctx context.Context, username, phone string) ([]
 	return tasks, nil
 }
 
-func (p *Writable) MarkTaskAsDone(ctx context.Context, taskID int) error {
-	affectedRows, err := queries.New(p.db).MarkTaskAsDone(ctx, int64(taskID-1))
+func (p *Writable) GetTask(ctx context.Context, teamID int64, taskID int) (domain.Task, error) {
+	task, err := queries.New(p.db).GetTask(ctx, &queries.GetTaskParams{
+		ID:     int64(taskID - 1),
+		TeamID: teamID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, errs.ErrNotFound
+		}
+		return domain.Task{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return TaskToDomain(task), nil
+}
+
+func (p *Writable) GetParticipantTasks(ctx context.Context, teamID, chatID int64, role domain.ParticipantRole) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetParticipantTasks(ctx, &queries.GetParticipantTasksParams{
+		TeamID: teamID,
+		ChatID: chatID,
+		Role:   int32(role),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tasks := make([]domain.Task, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		tasks = append(tasks, TaskToDomain(task))
+	}
+	return tasks, nil
+}
+
+func (p *Writable) SetTaskParent(ctx context.Context, teamID int64, taskID, parentID int) error {
+	affectedRows, err := queries.New(p.db).SetTaskParent(ctx, &queries.SetTaskParentParams{
+		ID:       int64(taskID - 1),
+		TeamID:   teamID,
+		ParentID: pgtype.Int8{Int64: int64(parentID - 1), Valid: parentID != 0},
+	})
 	if err != nil {
 		return fmt.Errorf("pgx.Query: %w", err)
 	}
@@ -276,8 +481,135 @@ func (p *Writable) MarkTaskAsDone(ctx context.Context, taskID int) error {
 	return nil
 }
 
-func (p *Writable) MarkTaskAsClosed(ctx context.Context, taskID int) error {
-	affectedRows, err := queries.New(p.db).MarkTaskAsClosed(ctx, int64(taskID-1))
+func (p *Writable) GetSubtasks(ctx context.Context, teamID int64, parentID int) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetSubtasks(ctx, &queries.GetSubtasksParams{
+		TeamID:   teamID,
+		ParentID: pgtype.Int8{Int64: int64(parentID - 1), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tasks := make([]domain.Task, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		tasks = append(tasks, TaskToDomain(task))
+	}
+	return tasks, nil
+}
+
+func (p *Writable) GetTasksProgress(ctx context.Context, taskIDs []int) (map[int]domain.TaskProgress, error) {
+	ids := make([]int64, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		ids = append(ids, int64(taskID-1))
+	}
+
+	subtasks, err := queries.New(p.db).GetSubtasksProgress(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	progress := make(map[int]domain.TaskProgress, len(taskIDs))
+	for _, row := range subtasks {
+		progress[int(row.ParentID.Int64)+1] = domain.TaskProgress{
+			Subtasks:     int(row.Total),
+			SubtasksDone: int(row.Finished),
+		}
+	}
+
+	items, err := queries.New(p.db).GetChecklistProgress(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	for _, row := range items {
+		taskProgress := progress[int(row.TaskID)+1]
+		taskProgress.Items, taskProgress.ItemsDone = int(row.Total), int(row.Finished)
+		progress[int(row.TaskID)+1] = taskProgress
+	}
+	return progress, nil
+}
+
+func (p *Writable) AddTaskDependency(ctx context.Context, taskID, blockerID int) error {
+	err := queries.New(p.db).AddTaskDependency(ctx, &queries.AddTaskDependencyParams{
+		TaskID:    int64(taskID - 1),
+		BlockerID: int64(blockerID - 1),
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) RemoveTaskDependency(ctx context.Context, taskID, blockerID int) error {
+	affectedRows, err := queries.New(p.db).RemoveTaskDependency(ctx, &queries.RemoveTaskDependencyParams{
+		TaskID:    int64(taskID - 1),
+		BlockerID: int64(blockerID - 1),
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) GetBlockers(ctx context.Context, taskIDs []int) (map[int][]domain.Task, error) {
+	ids := make([]int64, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		ids = append(ids, int64(taskID-1))
+	}
+
+	rows, err := queries.New(p.db).GetBlockers(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	blockers := make(map[int][]domain.Task, len(taskIDs))
+	for _, row := range rows {
+		blockedID := int(row.BlockedID) + 1
+		blockers[blockedID] = append(blockers[blockedID], TaskToDomain(&row.Task))
+	}
+	return blockers, nil
+}
+
+func (p *Writable) GetDependentTasks(ctx context.Context, blockerID int) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetDependentTasks(ctx, int64(blockerID-1))
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tasks := make([]domain.Task, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		tasks = append(tasks, TaskToDomain(task))
+	}
+	return tasks, nil
+}
+
+func (p *Writable) AddChecklistItem(ctx context.Context, item domain.ChecklistItem) (int, error) {
+	itemID, err := queries.New(p.db).AddChecklistItem(ctx, &queries.AddChecklistItemParams{
+		TaskID: int64(item.TaskID - 1),
+		Title:  item.Title,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return int(itemID), nil
+}
+
+func (p *Writable) GetChecklist(ctx context.Context, taskID int) ([]domain.ChecklistItem, error) {
+	queriesItems, err := queries.New(p.db).GetChecklist(ctx, int64(taskID-1))
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	items := make([]domain.ChecklistItem, 0, len(queriesItems))
+	for _, item := range queriesItems {
+		items = append(items, ChecklistItemToDomain(item))
+	}
+	return items, nil
+}
+
+func (p *Writable) SetChecklistItemDone(ctx context.Context, taskID, itemID int, done bool) error {
+	affectedRows, err := queries.New(p.db).SetChecklistItemDone(ctx, &queries.SetChecklistItemDoneParams{
+		ID:     int64(itemID),
+		TaskID: int64(taskID - 1),
+		Done:   done,
+	})
 	if err != nil {
 		return fmt.Errorf("pgx.Query: %w", err)
 	}
@@ -287,87 +619,995 @@ func (p *Writable) MarkTaskAsClosed(ctx context.Context, taskID int) error {
 	return nil
 }
 
-func (p *Writable) DeleteTask(ctx context.Context, taskID int) error {
-	err := queries.New(p.db).DeleteTask(ctx, int64(taskID-1))
+func (p *Writable) AddTaskParticipant(ctx context.Context, participant domain.TaskParticipant) error {
+	err := queries.New(p.db).AddTaskParticipant(ctx, &queries.AddTaskParticipantParams{
+		TaskID: int64(participant.TaskID - 1),
+		ChatID: participant.ChatID,
+		Role:   int32(participant.Role),
+	})
 	if err != nil {
 		return fmt.Errorf("pgx.Query: %w", err)
 	}
 	return nil
 }
 
-func (p *Writable) ChangeTaskDeadline(ctx context.Context, taskID int, newDeadline time.Time) error {
-	err := queries.New(p.db).ChangeTaskDeadline(ctx, &queries.ChangeTaskDeadlineParams{
-		ID:       int64(taskID - 1),
-		Deadline: pgtype.Timestamp{Time: newDeadline, Valid: true},
+func (p *Writable) RemoveTaskParticipant(ctx context.Context, taskID int, chatID int64) error {
+	affectedRows, err := queries.New(p.db).RemoveTaskParticipant(ctx, &queries.RemoveTaskParticipantParams{
+		TaskID: int64(taskID - 1),
+		ChatID: chatID,
 	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return errs.ErrNotFound
-		}
 		return fmt.Errorf("pgx.Query: %w", err)
 	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
 	return nil
 }
 
-func (p *Writable) GetTaskInProgress(ctx context.Context, chatID int64) (domain.Task, error) {
-	queriesTaskInProgress, err := queries.New(p.db).GetTaskInProgress(ctx, chatID)
+func (p *Writable) GetTaskParticipants(ctx context.Context, taskID int) ([]domain.TaskParticipant, error) {
+	queriesParticipants, err := queries.New(p.db).GetTaskParticipants(ctx, int64(taskID-1))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.Task{}, errs.ErrNotFound
-		}
-		return domain.Task{}, fmt.Errorf("pgx.Query: %w", err)
+		return nil, fmt.Errorf("pgx.Query: %w", err)
 	}
-	return domain.Task{
-		Title:           queriesTaskInProgress.Title.String,
-		ExecutorContact: queriesTaskInProgress.ExecutorContact.String,
-		ExecutorChatID:  queriesTaskInProgress.ExecutorChatID.Int64,
-		Deadline:        queriesTaskInProgress.Deadline.Time,
-	}, nil
+	participants := make([]domain.TaskParticipant, 0, len(queriesParticipants))
+	for _, participant := range queriesParticipants {
+		participants = append(participants, TaskParticipantToDomain(participant))
+	}
+	return participants, nil
 }
 
-func (p *Writable) SetTaskInProgressName(ctx context.Context, chatID int64, name string) error {
-	err := queries.New(p.db).SetTaskInProgressName(ctx, &queries.SetTaskInProgressNameParams{
+func (p *Writable) MarkParticipantDone(ctx context.Context, taskID int, chatID int64) (int, error) {
+	affectedRows, err := queries.New(p.db).MarkParticipantDone(ctx, &queries.MarkParticipantDoneParams{
+		TaskID: int64(taskID - 1),
 		ChatID: chatID,
-		Title:  pgtype.Text{String: name, Valid: true},
 	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return errs.ErrNotFound
-		}
-		return fmt.Errorf("pgx.Query: %w", err)
+		return 0, fmt.Errorf("pgx.Query: %w", err)
 	}
-	return nil
+	if affectedRows == 0 {
+		return 0, errs.ErrNotFound
+	}
+	remaining, err := queries.New(p.db).CountPendingExecutors(ctx, int64(taskID-1))
+	if err != nil {
+		return 0, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return int(remaining), nil
 }
 
-func (p *Writable) SetTaskInProgressUser(ctx context.Context, chatID int64, userContact string, userChatID int64) error {
-	err := queries.New(p.db).SetTaskInProgressUser(ctx, &queries.SetTaskInProgressUserParams{
-		ChatID:          chatID,
-		ExecutorContact: pgtype.Text{String: userContact, Valid: true},
-		ExecutorChatID:  pgtype.Int8{Int64: userChatID, Valid: true},
+func (p *Writable) MarkTaskAsDone(ctx context.Context, teamID int64, taskID int) error {
+	affectedRows, err := queries.New(p.db).MarkTaskAsDone(ctx, &queries.MarkTaskAsDoneParams{
+		ID:     int64(taskID - 1),
+		TeamID: teamID,
 	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return errs.ErrNotFound
-		}
 		return fmt.Errorf("pgx.Query: %w", err)
 	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
 	return nil
 }
 
-func (p *Writable) SetTaskInProgressDeadline(ctx context.Context, chatID int64, deadline time.Time) error {
-	err := queries.New(p.db).SetTaskInProgressDeadline(ctx, &queries.SetTaskInProgressDeadlineParams{
-		ChatID:   chatID,
-		Deadline: pgtype.Timestamp{},
+func (p *Writable) MarkTaskAsClosed(ctx context.Context, teamID int64, taskID int) error {
+	affectedRows, err := queries.New(p.db).MarkTaskAsClosed(ctx, &queries.MarkTaskAsClosedParams{
+		ID:     int64(taskID - 1),
+		TeamID: teamID,
 	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return errs.ErrNotFound
-		}
 		return fmt.Errorf("pgx.Query: %w", err)
 	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
 	return nil
 }
 
+func (p *Writable) DeleteTask(ctx context.Context, teamID int64, taskID int) error {
+	err := queries.New(p.db).DeleteTask(ctx, &queries.DeleteTaskParams{
+		ID:     int64(taskID - 1),
+		TeamID: teamID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) ChangeTaskDeadline(ctx context.Context, teamID int64, taskID int, newDeadline time.Time) error {
+	err := queries.New(p.db).ChangeTaskDeadline(ctx, &queries.ChangeTaskDeadlineParams{
+		ID:       int64(taskID - 1),
+		Deadline: pgtype.Timestamp{Time: newDeadline, Valid: true},
+		TeamID:   teamID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrNotFound
+		}
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) SetTaskProofRequired(ctx context.Context, teamID int64, taskID int, required bool) error {
+	affectedRows, err := queries.New(p.db).SetTaskProofRequired(ctx, &queries.SetTaskProofRequiredParams{
+		ID:            int64(taskID - 1),
+		TeamID:        teamID,
+		ProofRequired: required,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) LinkTasksByPhone(ctx context.Context, phone string, chatID int64) (int, error) {
+	affectedRows, err := queries.New(p.db).LinkTasksByPhone(ctx, &queries.LinkTasksByPhoneParams{
+		ExecutorContact: phone,
+		ExecutorChatID:  pgtype.Int8{Int64: chatID, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return int(affectedRows), nil
+}
+
+func (p *Writable) GetTasksToRemind(ctx context.Context, now time.Time) ([]domain.TaskReminder, error) {
+	queriesTasks, err := queries.New(p.db).GetTasksToRemind(ctx, pgtype.Timestamp{Time: now, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	reminders := make([]domain.TaskReminder, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		reminders = append(reminders, domain.TaskReminder{Task: TaskToDomain(task), At: task.NextReminderAt.Time})
+	}
+	return reminders, nil
+}
+
+func (p *Writable) SetTaskReminder(ctx context.Context, taskID int, at time.Time) error {
+	err := queries.New(p.db).SetTaskReminder(ctx, &queries.SetTaskReminderParams{
+		ID:             int64(taskID - 1),
+		NextReminderAt: pgtype.Timestamp{Time: at, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetOverdueTasks(ctx context.Context, priority domain.Priority) ([]domain.Task, error) {
+	queriesTasks, err := queries.New(p.db).GetOverdueTasks(ctx, int32(priority))
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tasks := make([]domain.Task, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		tasks = append(tasks, TaskToDomain(task))
+	}
+	return tasks, nil
+}
+
+func (p *Writable) GetTasks(ctx context.Context, filter domain.TaskFilter) ([]domain.Task, error) {
+	params := taskFilterParams(filter)
+	queriesTasks, err := queries.New(p.db).GetTasks(ctx, &params)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tasks := make([]domain.Task, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		tasks = append(tasks, TaskToDomain(task))
+	}
+	return tasks, nil
+}
+
+func (p *Writable) SearchTasks(ctx context.Context, filter domain.TaskFilter, query string, limit, offset int) ([]domain.Task, error) {
+	params := taskFilterParams(filter)
+	queriesTasks, err := queries.New(p.db).SearchTasks(ctx, &queries.SearchTasksParams{
+		TeamID:           params.TeamID,
+		Query:            query,
+		ManagerID:        params.ManagerID,
+		ExecutorChatID:   params.ExecutorChatID,
+		ExecutorUsername: params.ExecutorUsername,
+		ExecutorPhone:    params.ExecutorPhone,
+		Tags:             params.Tags,
+		Priorities:       params.Priorities,
+		Open:             params.Open,
+		Done:             params.Done,
+		Closed:           params.Closed,
+		Overdue:          params.Overdue,
+		Now:              params.Now,
+		PageLimit:        int32(limit),
+		PageOffset:       int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tasks := make([]domain.Task, 0, len(queriesTasks))
+	for _, task := range queriesTasks {
+		tasks = append(tasks, TaskToDomain(task))
+	}
+	return tasks, nil
+}
+
+// taskFilterParams converts the filter to parameters of GetTasks, other filtering queries copy them.
+func taskFilterParams(filter domain.TaskFilter) queries.GetTasksParams {
+	params := queries.GetTasksParams{
+		TeamID:    filter.TeamID,
+		ManagerID: pgtype.Int8{Int64: filter.ManagerID, Valid: filter.ManagerID != 0},
+		Tags:      filter.Tags,
+		Open:      filter.Open,
+		Done:      filter.Done,
+		Closed:    filter.Closed,
+		Overdue:   filter.Overdue,
+		Now:       pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	if params.Tags == nil {
+		params.Tags = []string{}
+	}
+	if filter.Executor != nil {
+		params.ExecutorChatID = pgtype.Int8{Int64: filter.Executor.ID, Valid: true}
+		params.ExecutorUsername = filter.Executor.Username
+		params.ExecutorPhone = filter.Executor.Phone
+	}
+	params.Priorities = make([]int32, 0, len(filter.Priorities))
+	for _, priority := range filter.Priorities {
+		params.Priorities = append(params.Priorities, int32(priority))
+	}
+	return params
+}
+
+func (p *Writable) SetTaskTags(ctx context.Context, teamID int64, taskID int, tags []string) error {
+	if err := queries.New(p.db).ClearTaskTags(ctx, int64(taskID-1)); err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	for _, tag := range tags {
+		err := queries.New(p.db).AddTaskTag(ctx, &queries.AddTaskTagParams{
+			TeamID: teamID,
+			Name:   tag,
+			TaskID: int64(taskID - 1),
+		})
+		if err != nil {
+			return fmt.Errorf("pgx.Exec: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *Writable) GetTasksTags(ctx context.Context, taskIDs []int) (map[int][]string, error) {
+	ids := make([]int64, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		ids = append(ids, int64(taskID-1))
+	}
+
+	rows, err := queries.New(p.db).GetTasksTags(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	tags := make(map[int][]string, len(taskIDs))
+	for _, row := range rows {
+		taskID := int(row.TaskID) + 1
+		tags[taskID] = append(tags[taskID], row.Name)
+	}
+	return tags, nil
+}
+
+func (p *Writable) SaveView(ctx context.Context, view domain.SavedView) error {
+	err := queries.New(p.db).SaveView(ctx, &queries.SaveViewParams{
+		TeamID: view.TeamID,
+		ChatID: view.ChatID,
+		Name:   view.Name,
+		Query:  view.Query,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetView(ctx context.Context, teamID, chatID int64, name string) (domain.SavedView, error) {
+	view, err := queries.New(p.db).GetView(ctx, &queries.GetViewParams{
+		TeamID: teamID,
+		ChatID: chatID,
+		Name:   name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.SavedView{}, errs.ErrNotFound
+		}
+		return domain.SavedView{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return ViewToDomain(view), nil
+}
+
+func (p *Writable) GetViews(ctx context.Context, teamID, chatID int64) ([]domain.SavedView, error) {
+	queriesViews, err := queries.New(p.db).GetViews(ctx, &queries.GetViewsParams{
+		TeamID: teamID,
+		ChatID: chatID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	views := make([]domain.SavedView, 0, len(queriesViews))
+	for _, view := range queriesViews {
+		views = append(views, ViewToDomain(view))
+	}
+	return views, nil
+}
+
+func (p *Writable) DeleteView(ctx context.Context, teamID, chatID int64, name string) error {
+	affectedRows, err := queries.New(p.db).DeleteView(ctx, &queries.DeleteViewParams{
+		TeamID: teamID,
+		ChatID: chatID,
+		Name:   name,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) AddComment(ctx context.Context, comment domain.Comment) (int, error) {
+	commentID, err := queries.New(p.db).AddComment(ctx, &queries.AddCommentParams{
+		TaskID: int64(comment.TaskID - 1),
+		ChatID: comment.ChatID,
+		Author: comment.Author,
+		Text:   comment.Text,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return int(commentID), nil
+}
+
+func (p *Writable) GetComments(ctx context.Context, taskID int) ([]domain.Comment, error) {
+	queriesComments, err := queries.New(p.db).GetComments(ctx, int64(taskID-1))
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	comments := make([]domain.Comment, 0, len(queriesComments))
+	for _, comment := range queriesComments {
+		comments = append(comments, CommentToDomain(comment))
+	}
+	return comments, nil
+}
+
+func (p *Writable) GetCommentsCount(ctx context.Context, taskID int) (int, error) {
+	count, err := queries.New(p.db).GetCommentsCount(ctx, int64(taskID-1))
+	if err != nil {
+		return 0, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return int(count), nil
+}
+
+func (p *Writable) AddTaskMessage(ctx context.Context, chatID int64, messageID, taskID int) error {
+	err := queries.New(p.db).AddTaskMessage(ctx, &queries.AddTaskMessageParams{
+		ChatID:    chatID,
+		MessageID: int64(messageID),
+		TaskID:    int64(taskID - 1),
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetMessageTask(ctx context.Context, chatID int64, messageID int) (int64, int, error) {
+	row, err := queries.New(p.db).GetMessageTask(ctx, &queries.GetMessageTaskParams{
+		ChatID:    chatID,
+		MessageID: int64(messageID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, errs.ErrNotFound
+		}
+		return 0, 0, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return row.TeamID, int(row.TaskID) + 1, nil
+}
+
+func (p *Writable) SetTaskCard(ctx context.Context, taskID int, chatID int64, messageID int) error {
+	err := queries.New(p.db).SetTaskCard(ctx, &queries.SetTaskCardParams{
+		TaskID:    int64(taskID - 1),
+		ChatID:    chatID,
+		MessageID: int64(messageID),
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetTaskCards(ctx context.Context, taskID int) (map[int64]int, error) {
+	cards, err := queries.New(p.db).GetTaskCards(ctx, int64(taskID-1))
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	res := make(map[int64]int, len(cards))
+	for _, card := range cards {
+		res[card.ChatID] = int(card.MessageID)
+	}
+	return res, nil
+}
+
+func (p *Writable) DeleteTaskCard(ctx context.Context, taskID int, chatID int64) error {
+	err := queries.New(p.db).DeleteTaskCard(ctx, &queries.DeleteTaskCardParams{
+		TaskID: int64(taskID - 1),
+		ChatID: chatID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) SaveDashboard(ctx context.Context, dashboard domain.Dashboard) error {
+	err := queries.New(p.db).SaveDashboard(ctx, &queries.SaveDashboardParams{
+		ChatID:    dashboard.ChatID,
+		TeamID:    dashboard.TeamID,
+		MessageID: int64(dashboard.MessageID),
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetDashboards(ctx context.Context) ([]domain.Dashboard, error) {
+	dashboards, err := queries.New(p.db).GetDashboards(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	res := make([]domain.Dashboard, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		res = append(res, DashboardToDomain(dashboard))
+	}
+	return res, nil
+}
+
+func (p *Writable) DeleteDashboard(ctx context.Context, chatID int64) error {
+	affectedRows, err := queries.New(p.db).DeleteDashboard(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) SetChatInactive(ctx context.Context, chatID int64, reason string) error {
+	err := queries.New(p.db).SetChatInactive(ctx, &queries.SetChatInactiveParams{
+		ChatID: chatID,
+		Reason: reason,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) SetChatActive(ctx context.Context, chatID int64) error {
+	if err := queries.New(p.db).SetChatActive(ctx, chatID); err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetInactiveChats(ctx context.Context) (map[int64]string, error) {
+	chats, err := queries.New(p.db).GetInactiveChats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	res := make(map[int64]string, len(chats))
+	for _, chat := range chats {
+		res[chat.ChatID] = chat.Reason
+	}
+	return res, nil
+}
+
+func (p *Writable) ClaimUpdate(ctx context.Context, updateID int) (bool, error) {
+	affectedRows, err := queries.New(p.db).ClaimUpdate(ctx, &queries.ClaimUpdateParams{
+		UpdateID:  int64(updateID),
+		HandledAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return affectedRows == 1, nil
+}
+
+func (p *Writable) GetLastUpdateID(ctx context.Context) (int, error) {
+	updateID, err := queries.New(p.db).GetLastUpdateID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("pgx.QueryRow: %w", err)
+	}
+	return int(updateID), nil
+}
+
+func (p *Writable) DeleteHandledUpdates(ctx context.Context, before time.Time) error {
+	if err := queries.New(p.db).DeleteHandledUpdates(ctx, pgtype.Timestamp{Time: before, Valid: true}); err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) AddAttachment(ctx context.Context, attachment domain.Attachment) (int, error) {
+	attachmentID, err := queries.New(p.db).AddAttachment(ctx, &queries.AddAttachmentParams{
+		TaskID:       pgtype.Int8{Int64: int64(attachment.TaskID - 1), Valid: attachment.TaskID != 0},
+		ChatID:       attachment.ChatID,
+		Kind:         int32(attachment.Kind),
+		FileID:       attachment.FileID,
+		FileUniqueID: attachment.FileUniqueID,
+		FileName:     attachment.FileName,
+		MimeType:     attachment.MimeType,
+		FileSize:     int64(attachment.FileSize),
+		LocalPath:    attachment.LocalPath,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return int(attachmentID), nil
+}
+
+func (p *Writable) BindAttachments(ctx context.Context, chatID int64, taskID int) error {
+	err := queries.New(p.db).BindAttachments(ctx, &queries.BindAttachmentsParams{
+		TaskID: pgtype.Int8{Int64: int64(taskID - 1), Valid: true},
+		ChatID: chatID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) DeletePendingAttachments(ctx context.Context, chatID int64) error {
+	if err := queries.New(p.db).DeletePendingAttachments(ctx, chatID); err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetAttachments(ctx context.Context, taskID int) ([]domain.Attachment, error) {
+	queriesAttachments, err := queries.New(p.db).GetAttachments(ctx, pgtype.Int8{Int64: int64(taskID - 1), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	attachments := make([]domain.Attachment, 0, len(queriesAttachments))
+	for _, attachment := range queriesAttachments {
+		attachments = append(attachments, AttachmentToDomain(attachment))
+	}
+	return attachments, nil
+}
+
+func (p *Writable) AddProof(ctx context.Context, proof domain.Proof) (int, error) {
+	proofID, err := queries.New(p.db).AddProof(ctx, &queries.AddProofParams{
+		TaskID:    int64(proof.TaskID - 1),
+		ChatID:    proof.ChatID,
+		Author:    proof.Author,
+		Kind:      int32(proof.Kind),
+		FileID:    proof.FileID,
+		Text:      proof.Text,
+		Latitude:  proof.Latitude,
+		Longitude: proof.Longitude,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return int(proofID), nil
+}
+
+func (p *Writable) GetProofs(ctx context.Context, taskID int) ([]domain.Proof, error) {
+	queriesProofs, err := queries.New(p.db).GetProofs(ctx, int64(taskID-1))
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	proofs := make([]domain.Proof, 0, len(queriesProofs))
+	for _, proof := range queriesProofs {
+		proofs = append(proofs, ProofToDomain(proof))
+	}
+	return proofs, nil
+}
+
+func (p *Writable) GetTaskInProgress(ctx context.Context, chatID int64) (domain.Task, error) {
+	queriesTaskInProgress, err := queries.New(p.db).GetTaskInProgress(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, errs.ErrNotFound
+		}
+		return domain.Task{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return domain.Task{
+		Title:           queriesTaskInProgress.Title.String,
+		ExecutorContact: queriesTaskInProgress.ExecutorContact.String,
+		ExecutorChatID:  queriesTaskInProgress.ExecutorChatID.Int64,
+		Deadline:        queriesTaskInProgress.Deadline.Time,
+		Priority:        domain.Priority(queriesTaskInProgress.Priority),
+	}, nil
+}
+
+func (p *Writable) SetTaskInProgressName(ctx context.Context, chatID int64, name string) error {
+	err := queries.New(p.db).SetTaskInProgressName(ctx, &queries.SetTaskInProgressNameParams{
+		ChatID: chatID,
+		Title:  pgtype.Text{String: name, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrNotFound
+		}
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) SetTaskInProgressUser(ctx context.Context, chatID int64, userContact string, userChatID int64) error {
+	err := queries.New(p.db).SetTaskInProgressUser(ctx, &queries.SetTaskInProgressUserParams{
+		ChatID:          chatID,
+		ExecutorContact: pgtype.Text{String: userContact, Valid: true},
+		ExecutorChatID:  pgtype.Int8{Int64: userChatID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrNotFound
+		}
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) SetTaskInProgressDeadline(ctx context.Context, chatID int64, deadline time.Time) error {
+	err := queries.New(p.db).SetTaskInProgressDeadline(ctx, &queries.SetTaskInProgressDeadlineParams{
+		ChatID:   chatID,
+		Deadline: pgtype.Timestamp{},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrNotFound
+		}
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) SetTaskInProgressPriority(ctx context.Context, chatID int64, priority domain.Priority) error {
+	err := queries.New(p.db).SetTaskInProgressPriority(ctx, &queries.SetTaskInProgressPriorityParams{
+		ChatID:   chatID,
+		Priority: int32(priority),
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) AddRecurrence(ctx context.Context, recurrence domain.Recurrence) (int64, error) {
+	recurrenceID, err := queries.New(p.db).AddRecurrence(ctx, &queries.AddRecurrenceParams{
+		TeamID:          recurrence.TeamID,
+		Title:           recurrence.Title,
+		ExecutorContact: recurrence.ExecutorContact,
+		ExecutorChatID:  pgtype.Int8{Int64: recurrence.ExecutorChatID, Valid: recurrence.ExecutorChatID != 0},
+		Schedule:        recurrence.Schedule.String(),
+		DeadlineIn:      int64(recurrence.DeadlineIn.Seconds()),
+		NextRun:         pgtype.Timestamp{Time: recurrence.NextRun.UTC(), Valid: true},
+		CreatedBy:       recurrence.CreatedBy,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return recurrenceID, nil
+}
+
+func (p *Writable) GetRecurrence(ctx context.Context, teamID, recurrenceID int64) (domain.Recurrence, error) {
+	recurrence, err := queries.New(p.db).GetRecurrence(ctx, &queries.GetRecurrenceParams{
+		ID:     recurrenceID,
+		TeamID: teamID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Recurrence{}, errs.ErrNotFound
+		}
+		return domain.Recurrence{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return RecurrenceToDomain(recurrence)
+}
+
+func (p *Writable) GetRecurrences(ctx context.Context, teamID int64) ([]domain.Recurrence, error) {
+	queriesRecurrences, err := queries.New(p.db).GetRecurrences(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return recurrencesToDomain(queriesRecurrences)
+}
+
+func (p *Writable) GetDueRecurrences(ctx context.Context, now time.Time) ([]domain.Recurrence, error) {
+	queriesRecurrences, err := queries.New(p.db).GetDueRecurrences(ctx, pgtype.Timestamp{Time: now.UTC(), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return recurrencesToDomain(queriesRecurrences)
+}
+
+func recurrencesToDomain(queriesRecurrences []*queries.Recurrence) ([]domain.Recurrence, error) {
+	recurrences := make([]domain.Recurrence, 0, len(queriesRecurrences))
+	for _, queriesRecurrence := range queriesRecurrences {
+		recurrence, err := RecurrenceToDomain(queriesRecurrence)
+		if err != nil {
+			return nil, fmt.Errorf("RecurrenceToDomain (%d): %w", queriesRecurrence.ID, err)
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	return recurrences, nil
+}
+
+func (p *Writable) AdvanceRecurrence(ctx context.Context, recurrenceID int64, from, to time.Time) (bool, error) {
+	affectedRows, err := queries.New(p.db).AdvanceRecurrence(ctx, &queries.AdvanceRecurrenceParams{
+		ToRun:   pgtype.Timestamp{Time: to.UTC(), Valid: true},
+		ID:      recurrenceID,
+		FromRun: pgtype.Timestamp{Time: from.UTC(), Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return affectedRows > 0, nil
+}
+
+func (p *Writable) SetRecurrencePaused(ctx context.Context, teamID, recurrenceID int64, paused bool, nextRun time.Time) error {
+	affectedRows, err := queries.New(p.db).SetRecurrencePaused(ctx, &queries.SetRecurrencePausedParams{
+		ID:      recurrenceID,
+		TeamID:  teamID,
+		Paused:  paused,
+		NextRun: pgtype.Timestamp{Time: nextRun.UTC(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) DeleteRecurrence(ctx context.Context, teamID, recurrenceID int64) error {
+	affectedRows, err := queries.New(p.db).DeleteRecurrence(ctx, &queries.DeleteRecurrenceParams{
+		ID:     recurrenceID,
+		TeamID: teamID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) SaveTemplate(ctx context.Context, template domain.TaskTemplate) error {
+	checklist := template.Checklist
+	if checklist == nil {
+		checklist = []string{}
+	}
+	err := queries.New(p.db).SaveTemplate(ctx, &queries.SaveTemplateParams{
+		TeamID:          template.TeamID,
+		Name:            template.Name,
+		TitlePattern:    template.TitlePattern,
+		ExecutorContact: template.ExecutorContact,
+		DeadlineIn:      int64(template.DeadlineIn.Seconds()),
+		Description:     template.Description,
+		Checklist:       checklist,
+		CreatedBy:       template.CreatedBy,
+		Priority:        int32(template.Priority),
+		ProofRequired:   template.ProofRequired,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetTemplate(ctx context.Context, teamID int64, name string) (domain.TaskTemplate, error) {
+	template, err := queries.New(p.db).GetTemplate(ctx, &queries.GetTemplateParams{
+		TeamID: teamID,
+		Name:   name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.TaskTemplate{}, errs.ErrNotFound
+		}
+		return domain.TaskTemplate{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return TemplateToDomain(template), nil
+}
+
+func (p *Writable) GetTemplates(ctx context.Context, teamID int64) ([]domain.TaskTemplate, error) {
+	queriesTemplates, err := queries.New(p.db).GetTemplates(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	templates := make([]domain.TaskTemplate, 0, len(queriesTemplates))
+	for _, template := range queriesTemplates {
+		templates = append(templates, TemplateToDomain(template))
+	}
+	return templates, nil
+}
+
+func (p *Writable) DeleteTemplate(ctx context.Context, teamID int64, name string) error {
+	affectedRows, err := queries.New(p.db).DeleteTemplate(ctx, &queries.DeleteTemplateParams{
+		TeamID: teamID,
+		Name:   name,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Exec: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) AddInvite(ctx context.Context, invite domain.Invite) error {
+	err := queries.New(p.db).AddInvite(ctx, &queries.AddInviteParams{
+		Code:      invite.Code,
+		Role:      int32(invite.Role),
+		Username:  pgtype.Text{String: invite.Username, Valid: invite.Username != ""},
+		CreatedBy: invite.CreatedBy,
+		ExpiresAt: pgtype.Timestamp{Time: invite.ExpiresAt, Valid: !invite.ExpiresAt.IsZero()},
+		TeamID:    invite.TeamID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetActiveInvites(ctx context.Context, teamID int64) ([]domain.Invite, error) {
+	queriesInvites, err := queries.New(p.db).GetActiveInvites(ctx, &queries.GetActiveInvitesParams{
+		TeamID:    teamID,
+		ExpiresAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	invites := make([]domain.Invite, 0, len(queriesInvites))
+	for _, invite := range queriesInvites {
+		invites = append(invites, InviteToDomain(invite))
+	}
+	return invites, nil
+}
+
+func (p *Writable) RedeemInvite(ctx context.Context, code string, chatID int64, username string) (domain.Invite, error) {
+	invite, err := queries.New(p.db).RedeemInvite(ctx, &queries.RedeemInviteParams{
+		Code:       code,
+		RedeemedBy: pgtype.Int8{Int64: chatID, Valid: true},
+		RedeemedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		Username:   pgtype.Text{String: username, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Invite{}, errs.ErrNotFound
+		}
+		return domain.Invite{}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return InviteToDomain(invite), nil
+}
+
+func (p *Writable) RevokeInvite(ctx context.Context, teamID int64, code string) error {
+	affectedRows, err := queries.New(p.db).RevokeInvite(ctx, &queries.RevokeInviteParams{
+		Code:   code,
+		TeamID: teamID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) GetPasswordAttempts(ctx context.Context, chatID int64) (domain.PasswordAttempts, error) {
+	attempts, err := queries.New(p.db).GetPasswordAttempts(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PasswordAttempts{ChatID: chatID}, errs.ErrNotFound
+		}
+		return domain.PasswordAttempts{ChatID: chatID}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return PasswordAttemptsToDomain(attempts), nil
+}
+
+func (p *Writable) RegisterPasswordFailure(ctx context.Context, chatID int64, now time.Time) (domain.PasswordAttempts, error) {
+	attempts, err := queries.New(p.db).RegisterPasswordFailure(ctx, &queries.RegisterPasswordFailureParams{
+		ChatID:      chatID,
+		LockedUntil: pgtype.Timestamp{Time: now, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// the chat is locked, the attempt is not counted
+			return p.GetPasswordAttempts(ctx, chatID)
+		}
+		return domain.PasswordAttempts{ChatID: chatID}, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return PasswordAttemptsToDomain(attempts), nil
+}
+
+func (p *Writable) LockPasswordAttempts(ctx context.Context, chatID int64, lockouts int, until time.Time) (bool, error) {
+	affectedRows, err := queries.New(p.db).LockPasswordAttempts(ctx, &queries.LockPasswordAttemptsParams{
+		ChatID:      chatID,
+		Lockouts:    int32(lockouts),
+		LockedUntil: pgtype.Timestamp{Time: until, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("pgx.Query: %w", err)
+	}
+	return affectedRows == 1, nil
+}
+
+func (p *Writable) GetFailedPasswordAttempts(ctx context.Context) ([]domain.PasswordAttempts, error) {
+	queriesAttempts, err := queries.New(p.db).GetFailedPasswordAttempts(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	result := make([]domain.PasswordAttempts, 0, len(queriesAttempts))
+	for _, attempts := range queriesAttempts {
+		result = append(result, PasswordAttemptsToDomain(attempts))
+	}
+	return result, nil
+}
+
+func (p *Writable) ResetPasswordAttempts(ctx context.Context, chatID int64) error {
+	affectedRows, err := queries.New(p.db).ResetPasswordAttempts(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (p *Writable) AddAdminAction(ctx context.Context, action domain.AdminAction) error {
+	err := queries.New(p.db).AddAdminAction(ctx, &queries.AddAdminActionParams{
+		AdminChatID:  action.AdminChatID,
+		TargetChatID: action.TargetChatID,
+		Action:       action.Action,
+		Details:      action.Details,
+		TeamID:       action.TeamID,
+	})
+	if err != nil {
+		return fmt.Errorf("pgx.Query: %w", err)
+	}
+	return nil
+}
+
+func (p *Writable) GetAdminActions(ctx context.Context, teamID int64, limit int) ([]domain.AdminAction, error) {
+	queriesActions, err := queries.New(p.db).GetAdminActions(ctx, &queries.GetAdminActionsParams{
+		TeamID: teamID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("pgx.Query: %w", err)
+	}
+	actions := make([]domain.AdminAction, 0, len(queriesActions))
+	for _, action := range queriesActions {
+		actions = append(actions, AdminActionToDomain(action))
+	}
+	return actions, nil
+}
+
 func (p *Writable) AddMessage(ctx context.Context, message domain.Message) error {
 	return nil
 }
@@ -1,18 +1,69 @@
 -- name: GetRole :one
-SELECT role FROM chats WHERE chat_id = $1;
+SELECT role FROM team_members WHERE team_id = $1 AND chat_id = $2;
 
 -- name: SetRole :exec
-UPDATE chats SET role = $2 WHERE chat_id = $1;
+INSERT INTO team_members (team_id, chat_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (team_id, chat_id) DO UPDATE SET role = EXCLUDED.role;
+
+-- name: AddTeam :one
+INSERT INTO teams (name) VALUES ($1) RETURNING *;
+
+-- name: GetTeamByName :one
+SELECT * FROM teams WHERE name = $1;
+
+-- name: GetChatTeams :many
+SELECT t.id, t.name, m.role FROM team_members m JOIN teams t ON t.id = m.team_id WHERE m.chat_id = $1 ORDER BY t.id;
+
+-- name: SetActiveTeam :execrows
+UPDATE chats SET team_id = $2 WHERE chat_id = $1;
 
 -- name: AddChat :exec
-INSERT INTO chats (chat_id, username, phone, role) VALUES ($1, $2, $3, $4) ON CONFLICT (chat_id)
+INSERT INTO chats (chat_id, username, phone) VALUES ($1, $2, $3) ON CONFLICT (chat_id)
 DO UPDATE SET username = COALESCE(NULLIF(EXCLUDED.username, ''), chats.username), phone = COALESCE(NULLIF(EXCLUDED.phone, ''), chats.phone);
 
 -- name: GetChat :one
-SELECT * FROM chats WHERE username = $1 OR phone = $2;
+SELECT sqlc.embed(c), COALESCE(m.role, 0)::int AS team_role
+FROM chats c LEFT JOIN team_members m ON m.team_id = c.team_id AND m.chat_id = c.chat_id
+WHERE c.username = $1 OR c.phone = $2;
+
+-- name: GetChatByID :one
+SELECT sqlc.embed(c), COALESCE(m.role, 0)::int AS team_role, COALESCE(m.banned, false)::bool AS team_banned
+FROM chats c LEFT JOIN team_members m ON m.team_id = c.team_id AND m.chat_id = c.chat_id
+WHERE c.chat_id = $1;
+
+-- name: GetChatsStats :many
+SELECT c.chat_id, c.username, c.phone, m.role, c.stage, m.banned, c.last_activity_at, m.manager_id,
+    (SELECT COUNT(*) FROM tasks t WHERE t.team_id = m.team_id AND t.done = false AND t.closed = false
+        AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)) AS open_tasks
+FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+WHERE m.team_id = $1 ORDER BY c.last_activity_at DESC NULLS LAST;
+
+-- name: SetBanned :execrows
+UPDATE team_members SET banned = $3 WHERE team_id = $1 AND chat_id = $2;
+
+-- name: TouchChat :exec
+UPDATE chats SET last_activity_at = $2 WHERE chat_id = $1;
+
+-- name: SetManager :execrows
+UPDATE team_members SET manager_id = $3 WHERE team_id = $1 AND chat_id = $2;
+
+-- name: GetSubordinates :many
+SELECT c.* FROM team_members m JOIN chats c ON c.chat_id = m.chat_id WHERE m.team_id = $1 AND m.manager_id = $2;
+
+-- name: GetSubordinatesTasks :many
+SELECT t.* FROM tasks t WHERE t.team_id = $1 AND EXISTS (
+    SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+    WHERE m.team_id = t.team_id AND m.manager_id = $2
+        AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)
+);
+
+-- name: GetExecutorManager :one
+SELECT m.manager_id FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+WHERE m.team_id = $1 AND m.manager_id IS NOT NULL AND (c.chat_id = $2 OR c.username = $3 OR c.phone = $3)
+LIMIT 1;
 
 -- name: GetObservers :many
-SELECT * FROM chats WHERE role = 2;
+SELECT c.* FROM team_members m JOIN chats c ON c.chat_id = m.chat_id WHERE m.team_id = $1 AND m.role = 2;
 
 -- name: SetStage :exec
 UPDATE chats SET stage = $2 WHERE chat_id = $1;
@@ -21,43 +72,106 @@ UPDATE chats SET stage = $2 WHERE chat_id = $1;
 SELECT stage FROM chats WHERE chat_id = $1;
 
 -- name: GetAllTasks :many
-SELECT * FROM tasks;
+SELECT * FROM tasks WHERE team_id = $1;
 
 -- name: GetExpiredTasks :many
-SELECT * FROM tasks WHERE expired = true;
+SELECT * FROM tasks WHERE team_id = $1 AND expired = true;
 
 -- name: GetExpiredTasksToMark :many
 SELECT * FROM tasks WHERE done = false AND expired = false AND deadline < (NOW() AT TIME ZONE 'UTC-3') FOR UPDATE;
 
 -- name: GetOpenTasks :many
-SELECT * FROM tasks WHERE closed = false;
+SELECT * FROM tasks WHERE team_id = $1 AND closed = false;
 
 -- name: GetDoneTasks :many
-SELECT * FROM tasks WHERE done = true;
+SELECT * FROM tasks WHERE team_id = $1 AND done = true;
 
 -- name: GetClosedTasks :many
-SELECT * FROM tasks WHERE closed = true;
+SELECT * FROM tasks WHERE team_id = $1 AND closed = true;
 
 -- name: GetUserTasks :many
-SELECT * FROM tasks WHERE executor_contact = $1 or executor_contact = $2;
+SELECT * FROM tasks WHERE team_id = $1 AND (executor_contact = $2 or executor_contact = $3);
+
+-- name: GetParticipantTasks :many
+SELECT t.* FROM tasks t JOIN task_participants p ON p.task_id = t.id WHERE t.team_id = $1 AND p.chat_id = $2 AND p.role = $3;
 
 -- name: AddTask :one
-INSERT INTO tasks (title, executor_contact, executor_chat_id, deadline, done, closed, expired) VALUES ($1, $2, $3, $4, false, false, false) RETURNING id;
+INSERT INTO tasks (title, executor_contact, executor_chat_id, deadline, team_id, description, priority, created_by, proof_required, done, closed, expired) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false, false, false) RETURNING id;
+
+-- name: GetTask :one
+SELECT * FROM tasks WHERE id = $1 AND team_id = $2;
 
 -- name: MarkTaskAsDone :execrows
-UPDATE tasks SET done = true WHERE id = $1;
+UPDATE tasks SET done = true WHERE id = $1 AND team_id = $2;
+
+-- name: SetTaskParent :execrows
+UPDATE tasks SET parent_id = $3 WHERE id = $1 AND team_id = $2;
+
+-- name: GetSubtasks :many
+SELECT * FROM tasks WHERE team_id = $1 AND parent_id = $2;
+
+-- name: GetSubtasksProgress :many
+SELECT parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE done OR closed) AS finished
+FROM tasks WHERE parent_id = ANY(@task_ids::bigint[]) GROUP BY parent_id;
+
+-- name: AddChecklistItem :one
+INSERT INTO checklist_items (task_id, title) VALUES ($1, $2) RETURNING id;
+
+-- name: GetChecklist :many
+SELECT * FROM checklist_items WHERE task_id = $1 ORDER BY id;
+
+-- name: GetChecklistProgress :many
+SELECT task_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE done) AS finished
+FROM checklist_items WHERE task_id = ANY(@task_ids::bigint[]) GROUP BY task_id;
+
+-- name: SetChecklistItemDone :execrows
+UPDATE checklist_items SET done = $3 WHERE id = $1 AND task_id = $2;
+
+-- name: AddTaskParticipant :exec
+INSERT INTO task_participants (task_id, chat_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (task_id, chat_id) DO UPDATE SET role = EXCLUDED.role;
+
+-- name: RemoveTaskParticipant :execrows
+DELETE FROM task_participants WHERE task_id = $1 AND chat_id = $2;
+
+-- name: GetTaskParticipants :many
+SELECT * FROM task_participants WHERE task_id = $1 ORDER BY created_at;
+
+-- name: MarkParticipantDone :execrows
+UPDATE task_participants SET done = true WHERE task_id = $1 AND chat_id = $2 AND role = 1;
+
+-- name: CountPendingExecutors :one
+SELECT COUNT(*) FROM task_participants WHERE task_id = $1 AND role = 1 AND done = false;
 
 -- name: MarkTaskAsClosed :execrows
-UPDATE tasks SET closed = true WHERE id = $1;
+UPDATE tasks SET closed = true WHERE id = $1 AND team_id = $2;
 
 -- name: MarkExpiredTask :execrows
 UPDATE tasks SET expired = true WHERE id = $1;
 
 -- name: DeleteTask :exec
-DELETE FROM tasks WHERE id = $1;
+DELETE FROM tasks WHERE id = $1 AND team_id = $2;
 
 -- name: ChangeTaskDeadline :exec
-UPDATE tasks SET deadline = $2, expired = false WHERE id = $1;
+UPDATE tasks SET deadline = $2, expired = false, next_reminder_at = NULL WHERE id = $1 AND team_id = $3;
+
+-- name: SetTaskProofRequired :execrows
+UPDATE tasks SET proof_required = $3 WHERE id = $1 AND team_id = $2;
+
+-- name: LinkTasksByPhone :execrows
+UPDATE tasks SET executor_chat_id = $2
+WHERE executor_contact = $1 AND COALESCE(executor_chat_id, 0) != $2
+    AND team_id IN (SELECT team_id FROM team_members WHERE chat_id = $2);
+
+-- name: GetTasksToRemind :many
+SELECT * FROM tasks
+WHERE done = false AND closed = false AND expired = false AND deadline > $1 AND (next_reminder_at IS NULL OR next_reminder_at <= $1);
+
+-- name: SetTaskReminder :exec
+UPDATE tasks SET next_reminder_at = $2 WHERE id = $1;
+
+-- name: GetOverdueTasks :many
+SELECT * FROM tasks WHERE expired = true AND done = false AND closed = false AND priority = $1 ORDER BY team_id, deadline;
 
 -- name: GetTaskInProgress :one
 SELECT * FROM tasks_in_progress WHERE chat_id = $1;
@@ -70,6 +184,261 @@ ON CONFLICT (chat_id) DO UPDATE SET title = EXCLUDED.title;
 INSERT INTO tasks_in_progress (chat_id, executor_contact, executor_chat_id) VALUES ($1, $2, $3) 
 ON CONFLICT (chat_id) DO UPDATE SET executor_contact = EXCLUDED.executor_contact, executor_chat_id = EXCLUDED.executor_chat_id;
 
+-- name: SetTaskInProgressPriority :exec
+INSERT INTO tasks_in_progress (chat_id, priority) VALUES ($1, $2)
+ON CONFLICT (chat_id) DO UPDATE SET priority = EXCLUDED.priority;
+
 -- name: SetTaskInProgressDeadline :exec
 INSERT INTO tasks_in_progress (chat_id, deadline) VALUES ($1, $2) 
 ON CONFLICT (chat_id) DO UPDATE SET deadline = EXCLUDED.deadline;
+
+-- name: AddInvite :exec
+INSERT INTO invites (code, role, username, created_by, expires_at, team_id) VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetActiveInvites :many
+SELECT * FROM invites WHERE team_id = $1 AND revoked = false AND redeemed_by IS NULL AND (expires_at IS NULL OR expires_at > $2);
+
+-- name: RedeemInvite :one
+UPDATE invites SET redeemed_by = $2, redeemed_at = $3
+WHERE code = $1 AND revoked = false AND redeemed_by IS NULL AND (expires_at IS NULL OR expires_at > $3) AND (username IS NULL OR username = '' OR lower(username) = lower($4))
+RETURNING *;
+
+-- name: RevokeInvite :execrows
+UPDATE invites SET revoked = true WHERE code = $1 AND team_id = $2 AND redeemed_by IS NULL;
+
+-- name: AddAdminAction :exec
+INSERT INTO admin_actions (admin_chat_id, target_chat_id, action, details, team_id) VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetAdminActions :many
+SELECT * FROM admin_actions WHERE team_id = $1 ORDER BY id DESC LIMIT $2;
+
+-- name: GetPasswordAttempts :one
+SELECT * FROM password_attempts WHERE chat_id = $1;
+
+-- name: RegisterPasswordFailure :one
+INSERT INTO password_attempts (chat_id, failures, lockouts, updated_at) VALUES ($1, 1, 0, CURRENT_TIMESTAMP)
+ON CONFLICT (chat_id) DO UPDATE SET failures = password_attempts.failures + 1, updated_at = CURRENT_TIMESTAMP
+WHERE password_attempts.locked_until IS NULL OR password_attempts.locked_until <= $2
+RETURNING *;
+
+-- name: LockPasswordAttempts :execrows
+UPDATE password_attempts SET failures = 0, lockouts = lockouts + 1, locked_until = $3, updated_at = CURRENT_TIMESTAMP
+WHERE chat_id = $1 AND lockouts = $2;
+
+-- name: GetFailedPasswordAttempts :many
+SELECT * FROM password_attempts WHERE failures > 0 OR lockouts > 0;
+
+-- name: ResetPasswordAttempts :execrows
+DELETE FROM password_attempts WHERE chat_id = $1;
+
+-- name: AddTaskDependency :exec
+INSERT INTO task_dependencies (task_id, blocker_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: RemoveTaskDependency :execrows
+DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2;
+
+-- name: GetBlockers :many
+SELECT d.task_id AS blocked_id, sqlc.embed(t)
+FROM task_dependencies d JOIN tasks t ON t.id = d.blocker_id
+WHERE d.task_id = ANY(@task_ids::bigint[]);
+
+-- name: GetDependentTasks :many
+SELECT t.* FROM task_dependencies d JOIN tasks t ON t.id = d.task_id WHERE d.blocker_id = $1;
+
+-- name: AddRecurrence :one
+INSERT INTO recurrences (team_id, title, executor_contact, executor_chat_id, schedule, deadline_in, next_run, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;
+
+-- name: GetRecurrence :one
+SELECT * FROM recurrences WHERE id = $1 AND team_id = $2;
+
+-- name: GetRecurrences :many
+SELECT * FROM recurrences WHERE team_id = $1 ORDER BY id;
+
+-- name: GetDueRecurrences :many
+SELECT * FROM recurrences WHERE paused = false AND next_run <= $1;
+
+-- name: AdvanceRecurrence :execrows
+UPDATE recurrences SET next_run = @to_run WHERE id = @id AND next_run = @from_run;
+
+-- name: SetRecurrencePaused :execrows
+UPDATE recurrences SET paused = $3, next_run = $4 WHERE id = $1 AND team_id = $2;
+
+-- name: DeleteRecurrence :execrows
+DELETE FROM recurrences WHERE id = $1 AND team_id = $2;
+
+-- name: SaveTemplate :exec
+INSERT INTO task_templates (team_id, name, title_pattern, executor_contact, deadline_in, description, checklist, created_by, priority, proof_required)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (team_id, name) DO UPDATE SET
+    title_pattern = EXCLUDED.title_pattern,
+    executor_contact = EXCLUDED.executor_contact,
+    deadline_in = EXCLUDED.deadline_in,
+    priority = EXCLUDED.priority,
+    description = EXCLUDED.description,
+    checklist = EXCLUDED.checklist,
+    created_by = EXCLUDED.created_by,
+    proof_required = EXCLUDED.proof_required;
+
+-- name: GetTemplate :one
+SELECT * FROM task_templates WHERE team_id = $1 AND name = $2;
+
+-- name: GetTemplates :many
+SELECT * FROM task_templates WHERE team_id = $1 ORDER BY name;
+
+-- name: DeleteTemplate :execrows
+DELETE FROM task_templates WHERE team_id = $1 AND name = $2;
+
+-- name: GetTasks :many
+SELECT t.* FROM tasks t
+WHERE t.team_id = @team_id
+    AND (sqlc.narg(manager_id)::bigint IS NULL OR EXISTS (
+        SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+        WHERE m.team_id = t.team_id AND m.manager_id = sqlc.narg(manager_id)
+            AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)
+    ))
+    AND (sqlc.narg(executor_chat_id)::bigint IS NULL OR t.executor_chat_id = sqlc.narg(executor_chat_id)
+        OR t.executor_contact IN (NULLIF(@executor_username::text, ''), NULLIF(@executor_phone::text, ''))
+        OR EXISTS (SELECT 1 FROM task_participants p WHERE p.task_id = t.id AND p.chat_id = sqlc.narg(executor_chat_id) AND p.role = 1))
+    AND (cardinality(@tags::text[]) = 0 OR (
+        SELECT COUNT(*) FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+        WHERE tt.task_id = t.id AND g.name = ANY(@tags::text[])
+    ) = cardinality(@tags::text[]))
+    AND (cardinality(@priorities::int[]) = 0 OR t.priority = ANY(@priorities::int[]))
+    AND (NOT @open::boolean OR (t.done = false AND t.closed = false))
+    AND (NOT @done::boolean OR t.done = true)
+    AND (NOT @closed::boolean OR t.closed = true)
+    AND (NOT @overdue::boolean OR (t.done = false AND t.closed = false AND (t.expired = true OR t.deadline < @now::timestamp)));
+
+-- name: ClearTaskTags :exec
+DELETE FROM task_tags WHERE task_id = $1;
+
+-- name: AddTaskTag :exec
+WITH tag AS (
+    INSERT INTO tags (team_id, name) VALUES ($1, $2)
+    ON CONFLICT (team_id, name) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+)
+INSERT INTO task_tags (task_id, tag_id) SELECT $3, id FROM tag ON CONFLICT DO NOTHING;
+
+-- name: GetTasksTags :many
+SELECT tt.task_id, g.name FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+WHERE tt.task_id = ANY(@task_ids::bigint[]) ORDER BY g.name;
+
+-- name: SaveView :exec
+INSERT INTO saved_views (team_id, chat_id, name, query) VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id, chat_id, name) DO UPDATE SET query = EXCLUDED.query;
+
+-- name: GetView :one
+SELECT * FROM saved_views WHERE team_id = $1 AND chat_id = $2 AND name = $3;
+
+-- name: GetViews :many
+SELECT * FROM saved_views WHERE team_id = $1 AND chat_id = $2 ORDER BY name;
+
+-- name: DeleteView :execrows
+DELETE FROM saved_views WHERE team_id = $1 AND chat_id = $2 AND name = $3;
+
+-- name: SearchTasks :many
+SELECT t.* FROM tasks t
+WHERE t.team_id = @team_id
+    AND (to_tsvector('russian', t.title || ' ' || t.description) @@ plainto_tsquery('russian', @query::text)
+        OR EXISTS (
+            SELECT 1 FROM task_comments tc
+            WHERE tc.task_id = t.id AND to_tsvector('russian', tc.text) @@ plainto_tsquery('russian', @query::text)
+        ))
+    AND (sqlc.narg(manager_id)::bigint IS NULL OR EXISTS (
+        SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+        WHERE m.team_id = t.team_id AND m.manager_id = sqlc.narg(manager_id)
+            AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)
+    ))
+    AND (sqlc.narg(executor_chat_id)::bigint IS NULL OR t.executor_chat_id = sqlc.narg(executor_chat_id)
+        OR t.executor_contact IN (NULLIF(@executor_username::text, ''), NULLIF(@executor_phone::text, ''))
+        OR EXISTS (SELECT 1 FROM task_participants p WHERE p.task_id = t.id AND p.chat_id = sqlc.narg(executor_chat_id) AND p.role = 1))
+    AND (cardinality(@tags::text[]) = 0 OR (
+        SELECT COUNT(*) FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+        WHERE tt.task_id = t.id AND g.name = ANY(@tags::text[])
+    ) = cardinality(@tags::text[]))
+    AND (cardinality(@priorities::int[]) = 0 OR t.priority = ANY(@priorities::int[]))
+    AND (NOT @open::boolean OR (t.done = false AND t.closed = false))
+    AND (NOT @done::boolean OR t.done = true)
+    AND (NOT @closed::boolean OR t.closed = true)
+    AND (NOT @overdue::boolean OR (t.done = false AND t.closed = false AND (t.expired = true OR t.deadline < @now::timestamp)))
+ORDER BY ts_rank(to_tsvector('russian', t.title || ' ' || t.description), plainto_tsquery('russian', @query::text)) DESC, t.id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: AddComment :one
+INSERT INTO task_comments (task_id, chat_id, author, text) VALUES ($1, $2, $3, $4) RETURNING id;
+
+-- name: GetComments :many
+SELECT * FROM task_comments WHERE task_id = $1 ORDER BY id;
+
+-- name: GetCommentsCount :one
+SELECT COUNT(*) FROM task_comments WHERE task_id = $1;
+
+-- name: AddTaskMessage :exec
+INSERT INTO task_messages (chat_id, message_id, task_id) VALUES ($1, $2, $3)
+ON CONFLICT (chat_id, message_id) DO UPDATE SET task_id = EXCLUDED.task_id;
+
+-- name: GetMessageTask :one
+SELECT t.team_id, m.task_id FROM task_messages m JOIN tasks t ON t.id = m.task_id
+WHERE m.chat_id = $1 AND m.message_id = $2;
+
+-- name: AddAttachment :one
+INSERT INTO task_attachments (task_id, chat_id, kind, file_id, file_unique_id, file_name, mime_type, file_size, local_path)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;
+
+-- name: BindAttachments :exec
+UPDATE task_attachments SET task_id = $1 WHERE task_id IS NULL AND chat_id = $2;
+
+-- name: DeletePendingAttachments :exec
+DELETE FROM task_attachments WHERE task_id IS NULL AND chat_id = $1;
+
+-- name: GetAttachments :many
+SELECT * FROM task_attachments WHERE task_id = $1 ORDER BY id;
+
+-- name: AddProof :one
+INSERT INTO task_proofs (task_id, chat_id, author, kind, file_id, text, latitude, longitude)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;
+
+-- name: GetProofs :many
+SELECT * FROM task_proofs WHERE task_id = $1 ORDER BY id;
+
+-- name: SetTaskCard :exec
+INSERT INTO task_cards (task_id, chat_id, message_id) VALUES ($1, $2, $3)
+ON CONFLICT (task_id, chat_id) DO UPDATE SET message_id = EXCLUDED.message_id, updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetTaskCards :many
+SELECT * FROM task_cards WHERE task_id = $1;
+
+-- name: DeleteTaskCard :exec
+DELETE FROM task_cards WHERE task_id = $1 AND chat_id = $2;
+
+-- name: SaveDashboard :exec
+INSERT INTO dashboards (chat_id, team_id, message_id) VALUES ($1, $2, $3)
+ON CONFLICT (chat_id) DO UPDATE SET team_id = EXCLUDED.team_id, message_id = EXCLUDED.message_id;
+
+-- name: GetDashboards :many
+SELECT * FROM dashboards ORDER BY chat_id;
+
+-- name: DeleteDashboard :execrows
+DELETE FROM dashboards WHERE chat_id = $1;
+
+-- name: SetChatInactive :exec
+INSERT INTO inactive_chats (chat_id, reason) VALUES ($1, $2)
+ON CONFLICT (chat_id) DO UPDATE SET reason = EXCLUDED.reason;
+
+-- name: SetChatActive :exec
+DELETE FROM inactive_chats WHERE chat_id = $1;
+
+-- name: GetInactiveChats :many
+SELECT * FROM inactive_chats ORDER BY chat_id;
+
+-- name: ClaimUpdate :execrows
+INSERT INTO handled_updates (update_id, handled_at) VALUES ($1, $2)
+ON CONFLICT (update_id) DO NOTHING;
+
+-- name: GetLastUpdateID :one
+SELECT COALESCE(MAX(update_id), 0)::bigint AS update_id FROM handled_updates;
+
+-- name: DeleteHandledUpdates :exec
+DELETE FROM handled_updates WHERE handled_at < $1 AND update_id < (SELECT MAX(update_id) FROM handled_updates);
@@ -8,12 +8,100 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AdminAction struct {
+	ID           int64            `json:"id"`
+	AdminChatID  int64            `json:"admin_chat_id"`
+	TargetChatID int64            `json:"target_chat_id"`
+	Action       string           `json:"action"`
+	Details      string           `json:"details"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	TeamID       int64            `json:"team_id"`
+}
+
 type Chat struct {
+	ChatID         int64            `json:"chat_id"`
+	Username       pgtype.Text      `json:"username"`
+	Phone          pgtype.Text      `json:"phone"`
+	Role           pgtype.Int4      `json:"role"`
+	Stage          pgtype.Int4      `json:"stage"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	LastActivityAt pgtype.Timestamp `json:"last_activity_at"`
+	TeamID         int64            `json:"team_id"`
+}
+
+type ChecklistItem struct {
+	ID        int64            `json:"id"`
+	TaskID    int64            `json:"task_id"`
+	Title     string           `json:"title"`
+	Done      bool             `json:"done"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Dashboard struct {
+	ChatID    int64            `json:"chat_id"`
+	TeamID    int64            `json:"team_id"`
+	MessageID int64            `json:"message_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type HandledUpdate struct {
+	UpdateID  int64            `json:"update_id"`
+	HandledAt pgtype.Timestamp `json:"handled_at"`
+}
+
+type InactiveChat struct {
+	ChatID    int64            `json:"chat_id"`
+	Reason    string           `json:"reason"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Invite struct {
+	Code       string           `json:"code"`
+	Role       int32            `json:"role"`
+	Username   pgtype.Text      `json:"username"`
+	CreatedBy  int64            `json:"created_by"`
+	RedeemedBy pgtype.Int8      `json:"redeemed_by"`
+	RedeemedAt pgtype.Timestamp `json:"redeemed_at"`
+	ExpiresAt  pgtype.Timestamp `json:"expires_at"`
+	Revoked    bool             `json:"revoked"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	TeamID     int64            `json:"team_id"`
+}
+
+type PasswordAttempt struct {
+	ChatID      int64            `json:"chat_id"`
+	Failures    int32            `json:"failures"`
+	Lockouts    int32            `json:"lockouts"`
+	LockedUntil pgtype.Timestamp `json:"locked_until"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type Recurrence struct {
+	ID              int64            `json:"id"`
+	TeamID          int64            `json:"team_id"`
+	Title           string           `json:"title"`
+	ExecutorContact string           `json:"executor_contact"`
+	ExecutorChatID  pgtype.Int8      `json:"executor_chat_id"`
+	Schedule        string           `json:"schedule"`
+	DeadlineIn      int64            `json:"deadline_in"`
+	NextRun         pgtype.Timestamp `json:"next_run"`
+	Paused          bool             `json:"paused"`
+	CreatedBy       int64            `json:"created_by"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+}
+
+type SavedView struct {
+	TeamID    int64            `json:"team_id"`
 	ChatID    int64            `json:"chat_id"`
-	Username  pgtype.Text      `json:"username"`
-	Phone     pgtype.Text      `json:"phone"`
-	Role      pgtype.Int4      `json:"role"`
-	Stage     pgtype.Int4      `json:"stage"`
+	Name      string           `json:"name"`
+	Query     string           `json:"query"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Tag struct {
+	ID        int64            `json:"id"`
+	TeamID    int64            `json:"team_id"`
+	Name      string           `json:"name"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
 
@@ -27,6 +115,96 @@ type Task struct {
 	Closed          bool             `json:"closed"`
 	Expired         bool             `json:"expired"`
 	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	TeamID          int64            `json:"team_id"`
+	ParentID        pgtype.Int8      `json:"parent_id"`
+	Description     string           `json:"description"`
+	Priority        int32            `json:"priority"`
+	NextReminderAt  pgtype.Timestamp `json:"next_reminder_at"`
+	CreatedBy       pgtype.Int8      `json:"created_by"`
+	ProofRequired   bool             `json:"proof_required"`
+}
+
+type TaskAttachment struct {
+	ID           int64            `json:"id"`
+	TaskID       pgtype.Int8      `json:"task_id"`
+	ChatID       int64            `json:"chat_id"`
+	Kind         int32            `json:"kind"`
+	FileID       string           `json:"file_id"`
+	FileUniqueID string           `json:"file_unique_id"`
+	FileName     string           `json:"file_name"`
+	MimeType     string           `json:"mime_type"`
+	FileSize     int64            `json:"file_size"`
+	LocalPath    string           `json:"local_path"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type TaskCard struct {
+	TaskID    int64            `json:"task_id"`
+	ChatID    int64            `json:"chat_id"`
+	MessageID int64            `json:"message_id"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type TaskComment struct {
+	ID        int64            `json:"id"`
+	TaskID    int64            `json:"task_id"`
+	ChatID    int64            `json:"chat_id"`
+	Author    string           `json:"author"`
+	Text      string           `json:"text"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TaskDependency struct {
+	TaskID    int64            `json:"task_id"`
+	BlockerID int64            `json:"blocker_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TaskMessage struct {
+	ChatID    int64            `json:"chat_id"`
+	MessageID int64            `json:"message_id"`
+	TaskID    int64            `json:"task_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TaskParticipant struct {
+	TaskID    int64            `json:"task_id"`
+	ChatID    int64            `json:"chat_id"`
+	Role      int32            `json:"role"`
+	Done      bool             `json:"done"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TaskProof struct {
+	ID        int64            `json:"id"`
+	TaskID    int64            `json:"task_id"`
+	ChatID    int64            `json:"chat_id"`
+	Author    string           `json:"author"`
+	Kind      int32            `json:"kind"`
+	FileID    string           `json:"file_id"`
+	Text      string           `json:"text"`
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TaskTag struct {
+	TaskID int64 `json:"task_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+type TaskTemplate struct {
+	TeamID          int64            `json:"team_id"`
+	Name            string           `json:"name"`
+	TitlePattern    string           `json:"title_pattern"`
+	ExecutorContact string           `json:"executor_contact"`
+	DeadlineIn      int64            `json:"deadline_in"`
+	Description     string           `json:"description"`
+	Checklist       []string         `json:"checklist"`
+	CreatedBy       int64            `json:"created_by"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	Priority        int32            `json:"priority"`
+	ProofRequired   bool             `json:"proof_required"`
 }
 
 type TasksInProgress struct {
@@ -36,4 +214,20 @@ type TasksInProgress struct {
 	ExecutorChatID  pgtype.Int8      `json:"executor_chat_id"`
 	Deadline        pgtype.Timestamp `json:"deadline"`
 	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	Priority        int32            `json:"priority"`
+}
+
+type Team struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TeamMember struct {
+	TeamID    int64            `json:"team_id"`
+	ChatID    int64            `json:"chat_id"`
+	Role      int32            `json:"role"`
+	Banned    bool             `json:"banned"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	ManagerID pgtype.Int8      `json:"manager_id"`
 }
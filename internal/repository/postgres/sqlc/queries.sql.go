@@ -11,8 +11,65 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addAdminAction = `-- name: AddAdminAction :exec
+INSERT INTO admin_actions (admin_chat_id, target_chat_id, action, details, team_id) VALUES ($1, $2, $3, $4, $5)
+`
+
+type AddAdminActionParams struct {
+	AdminChatID  int64  `json:"admin_chat_id"`
+	TargetChatID int64  `json:"target_chat_id"`
+	Action       string `json:"action"`
+	Details      string `json:"details"`
+	TeamID       int64  `json:"team_id"`
+}
+
+func (q *Queries) AddAdminAction(ctx context.Context, arg *AddAdminActionParams) error {
+	_, err := q.db.Exec(ctx, addAdminAction,
+		arg.AdminChatID,
+		arg.TargetChatID,
+		arg.Action,
+		arg.Details,
+		arg.TeamID,
+	)
+	return err
+}
+
+const addAttachment = `-- name: AddAttachment :one
+INSERT INTO task_attachments (task_id, chat_id, kind, file_id, file_unique_id, file_name, mime_type, file_size, local_path)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id
+`
+
+type AddAttachmentParams struct {
+	TaskID       pgtype.Int8 `json:"task_id"`
+	ChatID       int64       `json:"chat_id"`
+	Kind         int32       `json:"kind"`
+	FileID       string      `json:"file_id"`
+	FileUniqueID string      `json:"file_unique_id"`
+	FileName     string      `json:"file_name"`
+	MimeType     string      `json:"mime_type"`
+	FileSize     int64       `json:"file_size"`
+	LocalPath    string      `json:"local_path"`
+}
+
+func (q *Queries) AddAttachment(ctx context.Context, arg *AddAttachmentParams) (int64, error) {
+	row := q.db.QueryRow(ctx, addAttachment,
+		arg.TaskID,
+		arg.ChatID,
+		arg.Kind,
+		arg.FileID,
+		arg.FileUniqueID,
+		arg.FileName,
+		arg.MimeType,
+		arg.FileSize,
+		arg.LocalPath,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const addChat = `-- name: AddChat :exec
-INSERT INTO chats (chat_id, username, phone, role) VALUES ($1, $2, $3, $4) ON CONFLICT (chat_id)
+INSERT INTO chats (chat_id, username, phone) VALUES ($1, $2, $3) ON CONFLICT (chat_id)
 DO UPDATE SET username = COALESCE(NULLIF(EXCLUDED.username, ''), chats.username), phone = COALESCE(NULLIF(EXCLUDED.phone, ''), chats.phone)
 `
 
@@ -20,21 +77,143 @@ type AddChatParams struct {
 	ChatID   int64       `json:"chat_id"`
 	Username pgtype.Text `json:"username"`
 	Phone    pgtype.Text `json:"phone"`
-	Role     pgtype.Int4 `json:"role"`
 }
 
 func (q *Queries) AddChat(ctx context.Context, arg *AddChatParams) error {
-	_, err := q.db.Exec(ctx, addChat,
+	_, err := q.db.Exec(ctx, addChat, arg.ChatID, arg.Username, arg.Phone)
+	return err
+}
+
+const addChecklistItem = `-- name: AddChecklistItem :one
+INSERT INTO checklist_items (task_id, title) VALUES ($1, $2) RETURNING id
+`
+
+type AddChecklistItemParams struct {
+	TaskID int64  `json:"task_id"`
+	Title  string `json:"title"`
+}
+
+func (q *Queries) AddChecklistItem(ctx context.Context, arg *AddChecklistItemParams) (int64, error) {
+	row := q.db.QueryRow(ctx, addChecklistItem, arg.TaskID, arg.Title)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addComment = `-- name: AddComment :one
+INSERT INTO task_comments (task_id, chat_id, author, text) VALUES ($1, $2, $3, $4) RETURNING id
+`
+
+type AddCommentParams struct {
+	TaskID int64  `json:"task_id"`
+	ChatID int64  `json:"chat_id"`
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+func (q *Queries) AddComment(ctx context.Context, arg *AddCommentParams) (int64, error) {
+	row := q.db.QueryRow(ctx, addComment,
+		arg.TaskID,
 		arg.ChatID,
-		arg.Username,
-		arg.Phone,
+		arg.Author,
+		arg.Text,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addInvite = `-- name: AddInvite :exec
+INSERT INTO invites (code, role, username, created_by, expires_at, team_id) VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type AddInviteParams struct {
+	Code      string           `json:"code"`
+	Role      int32            `json:"role"`
+	Username  pgtype.Text      `json:"username"`
+	CreatedBy int64            `json:"created_by"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	TeamID    int64            `json:"team_id"`
+}
+
+func (q *Queries) AddInvite(ctx context.Context, arg *AddInviteParams) error {
+	_, err := q.db.Exec(ctx, addInvite,
+		arg.Code,
 		arg.Role,
+		arg.Username,
+		arg.CreatedBy,
+		arg.ExpiresAt,
+		arg.TeamID,
 	)
 	return err
 }
 
+const addProof = `-- name: AddProof :one
+INSERT INTO task_proofs (task_id, chat_id, author, kind, file_id, text, latitude, longitude)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+`
+
+type AddProofParams struct {
+	TaskID    int64   `json:"task_id"`
+	ChatID    int64   `json:"chat_id"`
+	Author    string  `json:"author"`
+	Kind      int32   `json:"kind"`
+	FileID    string  `json:"file_id"`
+	Text      string  `json:"text"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (q *Queries) AddProof(ctx context.Context, arg *AddProofParams) (int64, error) {
+	row := q.db.QueryRow(ctx, addProof,
+		arg.TaskID,
+		arg.ChatID,
+		arg.Author,
+		arg.Kind,
+		arg.FileID,
+		arg.Text,
+		arg.Latitude,
+		arg.Longitude,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addRecurrence = `-- name: AddRecurrence :one
+INSERT INTO recurrences (team_id, title, executor_contact, executor_chat_id, schedule, deadline_in, next_run, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+`
+
+type AddRecurrenceParams struct {
+	TeamID          int64            `json:"team_id"`
+	Title           string           `json:"title"`
+	ExecutorContact string           `json:"executor_contact"`
+	ExecutorChatID  pgtype.Int8      `json:"executor_chat_id"`
+	Schedule        string           `json:"schedule"`
+	DeadlineIn      int64            `json:"deadline_in"`
+	NextRun         pgtype.Timestamp `json:"next_run"`
+	CreatedBy       int64            `json:"created_by"`
+}
+
+func (q *Queries) AddRecurrence(ctx context.Context, arg *AddRecurrenceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, addRecurrence,
+		arg.TeamID,
+		arg.Title,
+		arg.ExecutorContact,
+		arg.ExecutorChatID,
+		arg.Schedule,
+		arg.DeadlineIn,
+		arg.NextRun,
+		arg.CreatedBy,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const addTask = `-- name: AddTask :one
-INSERT INTO tasks (title, executor_contact, executor_chat_id, deadline, done, closed, expired) VALUES ($1, $2, $3, $4, false, false, false) RETURNING id
+INSERT INTO tasks (title, executor_contact, executor_chat_id, deadline, team_id, description, priority, created_by, proof_required, done, closed, expired) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false, false, false) RETURNING id
 `
 
 type AddTaskParams struct {
@@ -42,6 +221,11 @@ type AddTaskParams struct {
 	ExecutorContact string           `json:"executor_contact"`
 	ExecutorChatID  pgtype.Int8      `json:"executor_chat_id"`
 	Deadline        pgtype.Timestamp `json:"deadline"`
+	TeamID          int64            `json:"team_id"`
+	Description     string           `json:"description"`
+	Priority        int32            `json:"priority"`
+	CreatedBy       pgtype.Int8      `json:"created_by"`
+	ProofRequired   bool             `json:"proof_required"`
 }
 
 func (q *Queries) AddTask(ctx context.Context, arg *AddTaskParams) (int64, error) {
@@ -50,58 +234,322 @@ func (q *Queries) AddTask(ctx context.Context, arg *AddTaskParams) (int64, error
 		arg.ExecutorContact,
 		arg.ExecutorChatID,
 		arg.Deadline,
+		arg.TeamID,
+		arg.Description,
+		arg.Priority,
+		arg.CreatedBy,
+		arg.ProofRequired,
 	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
+const addTaskDependency = `-- name: AddTaskDependency :exec
+INSERT INTO task_dependencies (task_id, blocker_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type AddTaskDependencyParams struct {
+	TaskID    int64 `json:"task_id"`
+	BlockerID int64 `json:"blocker_id"`
+}
+
+func (q *Queries) AddTaskDependency(ctx context.Context, arg *AddTaskDependencyParams) error {
+	_, err := q.db.Exec(ctx, addTaskDependency, arg.TaskID, arg.BlockerID)
+	return err
+}
+
+const addTaskMessage = `-- name: AddTaskMessage :exec
+INSERT INTO task_messages (chat_id, message_id, task_id) VALUES ($1, $2, $3)
+ON CONFLICT (chat_id, message_id) DO UPDATE SET task_id = EXCLUDED.task_id
+`
+
+type AddTaskMessageParams struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int64 `json:"message_id"`
+	TaskID    int64 `json:"task_id"`
+}
+
+func (q *Queries) AddTaskMessage(ctx context.Context, arg *AddTaskMessageParams) error {
+	_, err := q.db.Exec(ctx, addTaskMessage, arg.ChatID, arg.MessageID, arg.TaskID)
+	return err
+}
+
+const addTaskParticipant = `-- name: AddTaskParticipant :exec
+INSERT INTO task_participants (task_id, chat_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (task_id, chat_id) DO UPDATE SET role = EXCLUDED.role
+`
+
+type AddTaskParticipantParams struct {
+	TaskID int64 `json:"task_id"`
+	ChatID int64 `json:"chat_id"`
+	Role   int32 `json:"role"`
+}
+
+func (q *Queries) AddTaskParticipant(ctx context.Context, arg *AddTaskParticipantParams) error {
+	_, err := q.db.Exec(ctx, addTaskParticipant, arg.TaskID, arg.ChatID, arg.Role)
+	return err
+}
+
+const addTaskTag = `-- name: AddTaskTag :exec
+WITH tag AS (
+    INSERT INTO tags (team_id, name) VALUES ($1, $2)
+    ON CONFLICT (team_id, name) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+)
+INSERT INTO task_tags (task_id, tag_id) SELECT $3, id FROM tag ON CONFLICT DO NOTHING
+`
+
+type AddTaskTagParams struct {
+	TeamID int64  `json:"team_id"`
+	Name   string `json:"name"`
+	TaskID int64  `json:"task_id"`
+}
+
+func (q *Queries) AddTaskTag(ctx context.Context, arg *AddTaskTagParams) error {
+	_, err := q.db.Exec(ctx, addTaskTag, arg.TeamID, arg.Name, arg.TaskID)
+	return err
+}
+
+const addTeam = `-- name: AddTeam :one
+INSERT INTO teams (name) VALUES ($1) RETURNING id, name, created_at
+`
+
+func (q *Queries) AddTeam(ctx context.Context, name string) (*Team, error) {
+	row := q.db.QueryRow(ctx, addTeam, name)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const advanceRecurrence = `-- name: AdvanceRecurrence :execrows
+UPDATE recurrences SET next_run = $1 WHERE id = $2 AND next_run = $3
+`
+
+type AdvanceRecurrenceParams struct {
+	ToRun   pgtype.Timestamp `json:"to_run"`
+	ID      int64            `json:"id"`
+	FromRun pgtype.Timestamp `json:"from_run"`
+}
+
+func (q *Queries) AdvanceRecurrence(ctx context.Context, arg *AdvanceRecurrenceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, advanceRecurrence, arg.ToRun, arg.ID, arg.FromRun)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const bindAttachments = `-- name: BindAttachments :exec
+UPDATE task_attachments SET task_id = $1 WHERE task_id IS NULL AND chat_id = $2
+`
+
+type BindAttachmentsParams struct {
+	TaskID pgtype.Int8 `json:"task_id"`
+	ChatID int64       `json:"chat_id"`
+}
+
+func (q *Queries) BindAttachments(ctx context.Context, arg *BindAttachmentsParams) error {
+	_, err := q.db.Exec(ctx, bindAttachments, arg.TaskID, arg.ChatID)
+	return err
+}
+
 const changeTaskDeadline = `-- name: ChangeTaskDeadline :exec
-UPDATE tasks SET deadline = $2, expired = false WHERE id = $1
+UPDATE tasks SET deadline = $2, expired = false, next_reminder_at = NULL WHERE id = $1 AND team_id = $3
 `
 
 type ChangeTaskDeadlineParams struct {
 	ID       int64            `json:"id"`
 	Deadline pgtype.Timestamp `json:"deadline"`
+	TeamID   int64            `json:"team_id"`
 }
 
 func (q *Queries) ChangeTaskDeadline(ctx context.Context, arg *ChangeTaskDeadlineParams) error {
-	_, err := q.db.Exec(ctx, changeTaskDeadline, arg.ID, arg.Deadline)
+	_, err := q.db.Exec(ctx, changeTaskDeadline, arg.ID, arg.Deadline, arg.TeamID)
+	return err
+}
+
+const claimUpdate = `-- name: ClaimUpdate :execrows
+INSERT INTO handled_updates (update_id, handled_at) VALUES ($1, $2)
+ON CONFLICT (update_id) DO NOTHING
+`
+
+type ClaimUpdateParams struct {
+	UpdateID  int64            `json:"update_id"`
+	HandledAt pgtype.Timestamp `json:"handled_at"`
+}
+
+func (q *Queries) ClaimUpdate(ctx context.Context, arg *ClaimUpdateParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimUpdate, arg.UpdateID, arg.HandledAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const clearTaskTags = `-- name: ClearTaskTags :exec
+DELETE FROM task_tags WHERE task_id = $1
+`
+
+func (q *Queries) ClearTaskTags(ctx context.Context, taskID int64) error {
+	_, err := q.db.Exec(ctx, clearTaskTags, taskID)
+	return err
+}
+
+const countPendingExecutors = `-- name: CountPendingExecutors :one
+SELECT COUNT(*) FROM task_participants WHERE task_id = $1 AND role = 1 AND done = false
+`
+
+func (q *Queries) CountPendingExecutors(ctx context.Context, taskID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countPendingExecutors, taskID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteDashboard = `-- name: DeleteDashboard :execrows
+DELETE FROM dashboards WHERE chat_id = $1
+`
+
+func (q *Queries) DeleteDashboard(ctx context.Context, chatID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDashboard, chatID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteHandledUpdates = `-- name: DeleteHandledUpdates :exec
+DELETE FROM handled_updates WHERE handled_at < $1 AND update_id < (SELECT MAX(update_id) FROM handled_updates)
+`
+
+func (q *Queries) DeleteHandledUpdates(ctx context.Context, handledAt pgtype.Timestamp) error {
+	_, err := q.db.Exec(ctx, deleteHandledUpdates, handledAt)
+	return err
+}
+
+const deletePendingAttachments = `-- name: DeletePendingAttachments :exec
+DELETE FROM task_attachments WHERE task_id IS NULL AND chat_id = $1
+`
+
+func (q *Queries) DeletePendingAttachments(ctx context.Context, chatID int64) error {
+	_, err := q.db.Exec(ctx, deletePendingAttachments, chatID)
 	return err
 }
 
+const deleteRecurrence = `-- name: DeleteRecurrence :execrows
+DELETE FROM recurrences WHERE id = $1 AND team_id = $2
+`
+
+type DeleteRecurrenceParams struct {
+	ID     int64 `json:"id"`
+	TeamID int64 `json:"team_id"`
+}
+
+func (q *Queries) DeleteRecurrence(ctx context.Context, arg *DeleteRecurrenceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRecurrence, arg.ID, arg.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteTask = `-- name: DeleteTask :exec
-DELETE FROM tasks WHERE id = $1
+DELETE FROM tasks WHERE id = $1 AND team_id = $2
 `
 
-func (q *Queries) DeleteTask(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteTask, id)
+type DeleteTaskParams struct {
+	ID     int64 `json:"id"`
+	TeamID int64 `json:"team_id"`
+}
+
+func (q *Queries) DeleteTask(ctx context.Context, arg *DeleteTaskParams) error {
+	_, err := q.db.Exec(ctx, deleteTask, arg.ID, arg.TeamID)
 	return err
 }
 
-const getAllTasks = `-- name: GetAllTasks :many
-SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at FROM tasks
+const deleteTaskCard = `-- name: DeleteTaskCard :exec
+DELETE FROM task_cards WHERE task_id = $1 AND chat_id = $2
+`
+
+type DeleteTaskCardParams struct {
+	TaskID int64 `json:"task_id"`
+	ChatID int64 `json:"chat_id"`
+}
+
+func (q *Queries) DeleteTaskCard(ctx context.Context, arg *DeleteTaskCardParams) error {
+	_, err := q.db.Exec(ctx, deleteTaskCard, arg.TaskID, arg.ChatID)
+	return err
+}
+
+const deleteTemplate = `-- name: DeleteTemplate :execrows
+DELETE FROM task_templates WHERE team_id = $1 AND name = $2
+`
+
+type DeleteTemplateParams struct {
+	TeamID int64  `json:"team_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) DeleteTemplate(ctx context.Context, arg *DeleteTemplateParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTemplate, arg.TeamID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteView = `-- name: DeleteView :execrows
+DELETE FROM saved_views WHERE team_id = $1 AND chat_id = $2 AND name = $3
+`
+
+type DeleteViewParams struct {
+	TeamID int64  `json:"team_id"`
+	ChatID int64  `json:"chat_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) DeleteView(ctx context.Context, arg *DeleteViewParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteView, arg.TeamID, arg.ChatID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActiveInvites = `-- name: GetActiveInvites :many
+SELECT code, role, username, created_by, redeemed_by, redeemed_at, expires_at, revoked, created_at, team_id FROM invites WHERE team_id = $1 AND revoked = false AND redeemed_by IS NULL AND (expires_at IS NULL OR expires_at > $2)
 `
 
-func (q *Queries) GetAllTasks(ctx context.Context) ([]*Task, error) {
-	rows, err := q.db.Query(ctx, getAllTasks)
+type GetActiveInvitesParams struct {
+	TeamID    int64            `json:"team_id"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+func (q *Queries) GetActiveInvites(ctx context.Context, arg *GetActiveInvitesParams) ([]*Invite, error) {
+	rows, err := q.db.Query(ctx, getActiveInvites, arg.TeamID, arg.ExpiresAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []*Task
+	var items []*Invite
 	for rows.Next() {
-		var i Task
+		var i Invite
 		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.ExecutorContact,
-			&i.ExecutorChatID,
-			&i.Deadline,
-			&i.Done,
-			&i.Closed,
-			&i.Expired,
+			&i.Code,
+			&i.Role,
+			&i.Username,
+			&i.CreatedBy,
+			&i.RedeemedBy,
+			&i.RedeemedAt,
+			&i.ExpiresAt,
+			&i.Revoked,
 			&i.CreatedAt,
+			&i.TeamID,
 		); err != nil {
 			return nil, err
 		}
@@ -113,52 +561,32 @@ func (q *Queries) GetAllTasks(ctx context.Context) ([]*Task, error) {
 	return items, nil
 }
 
-const getChat = `-- name: GetChat :one
-SELECT chat_id, username, phone, role, stage, created_at FROM chats WHERE username = $1 OR phone = $2
+const getAdminActions = `-- name: GetAdminActions :many
+SELECT id, admin_chat_id, target_chat_id, action, details, created_at, team_id FROM admin_actions WHERE team_id = $1 ORDER BY id DESC LIMIT $2
 `
 
-type GetChatParams struct {
-	Username pgtype.Text `json:"username"`
-	Phone    pgtype.Text `json:"phone"`
-}
-
-func (q *Queries) GetChat(ctx context.Context, arg *GetChatParams) (*Chat, error) {
-	row := q.db.QueryRow(ctx, getChat, arg.Username, arg.Phone)
-	var i Chat
-	err := row.Scan(
-		&i.ChatID,
-		&i.Username,
-		&i.Phone,
-		&i.Role,
-		&i.Stage,
-		&i.CreatedAt,
-	)
-	return &i, err
+type GetAdminActionsParams struct {
+	TeamID int64 `json:"team_id"`
+	Limit  int32 `json:"limit"`
 }
 
-const getClosedTasks = `-- name: GetClosedTasks :many
-SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at FROM tasks WHERE closed = true
-`
-
-func (q *Queries) GetClosedTasks(ctx context.Context) ([]*Task, error) {
-	rows, err := q.db.Query(ctx, getClosedTasks)
+func (q *Queries) GetAdminActions(ctx context.Context, arg *GetAdminActionsParams) ([]*AdminAction, error) {
+	rows, err := q.db.Query(ctx, getAdminActions, arg.TeamID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []*Task
+	var items []*AdminAction
 	for rows.Next() {
-		var i Task
+		var i AdminAction
 		if err := rows.Scan(
 			&i.ID,
-			&i.Title,
-			&i.ExecutorContact,
-			&i.ExecutorChatID,
-			&i.Deadline,
-			&i.Done,
-			&i.Closed,
-			&i.Expired,
+			&i.AdminChatID,
+			&i.TargetChatID,
+			&i.Action,
+			&i.Details,
 			&i.CreatedAt,
+			&i.TeamID,
 		); err != nil {
 			return nil, err
 		}
@@ -170,12 +598,12 @@ func (q *Queries) GetClosedTasks(ctx context.Context) ([]*Task, error) {
 	return items, nil
 }
 
-const getDoneTasks = `-- name: GetDoneTasks :many
-SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at FROM tasks WHERE done = true
+const getAllTasks = `-- name: GetAllTasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE team_id = $1
 `
 
-func (q *Queries) GetDoneTasks(ctx context.Context) ([]*Task, error) {
-	rows, err := q.db.Query(ctx, getDoneTasks)
+func (q *Queries) GetAllTasks(ctx context.Context, teamID int64) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getAllTasks, teamID)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +621,13 @@ func (q *Queries) GetDoneTasks(ctx context.Context) ([]*Task, error) {
 			&i.Closed,
 			&i.Expired,
 			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
 		); err != nil {
 			return nil, err
 		}
@@ -204,190 +639,2027 @@ func (q *Queries) GetDoneTasks(ctx context.Context) ([]*Task, error) {
 	return items, nil
 }
 
-const getExpiredTasks = `-- name: GetExpiredTasks :many
-SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at FROM tasks WHERE expired = true
+const getAttachments = `-- name: GetAttachments :many
+SELECT id, task_id, chat_id, kind, file_id, file_unique_id, file_name, mime_type, file_size, local_path, created_at FROM task_attachments WHERE task_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetAttachments(ctx context.Context, taskID pgtype.Int8) ([]*TaskAttachment, error) {
+	rows, err := q.db.Query(ctx, getAttachments, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*TaskAttachment
+	for rows.Next() {
+		var i TaskAttachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ChatID,
+			&i.Kind,
+			&i.FileID,
+			&i.FileUniqueID,
+			&i.FileName,
+			&i.MimeType,
+			&i.FileSize,
+			&i.LocalPath,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBlockers = `-- name: GetBlockers :many
+SELECT d.task_id AS blocked_id, t.id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, t.created_at, t.team_id, t.parent_id, t.description, t.priority, t.next_reminder_at, t.created_by, t.proof_required
+FROM task_dependencies d JOIN tasks t ON t.id = d.blocker_id
+WHERE d.task_id = ANY($1::bigint[])
+`
+
+type GetBlockersRow struct {
+	BlockedID int64 `json:"blocked_id"`
+	Task      Task  `json:"task"`
+}
+
+func (q *Queries) GetBlockers(ctx context.Context, taskIds []int64) ([]*GetBlockersRow, error) {
+	rows, err := q.db.Query(ctx, getBlockers, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetBlockersRow
+	for rows.Next() {
+		var i GetBlockersRow
+		if err := rows.Scan(
+			&i.BlockedID,
+			&i.Task.ID,
+			&i.Task.Title,
+			&i.Task.ExecutorContact,
+			&i.Task.ExecutorChatID,
+			&i.Task.Deadline,
+			&i.Task.Done,
+			&i.Task.Closed,
+			&i.Task.Expired,
+			&i.Task.CreatedAt,
+			&i.Task.TeamID,
+			&i.Task.ParentID,
+			&i.Task.Description,
+			&i.Task.Priority,
+			&i.Task.NextReminderAt,
+			&i.Task.CreatedBy,
+			&i.Task.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChat = `-- name: GetChat :one
+SELECT c.chat_id, c.username, c.phone, c.role, c.stage, c.created_at, c.last_activity_at, c.team_id, COALESCE(m.role, 0)::int AS team_role
+FROM chats c LEFT JOIN team_members m ON m.team_id = c.team_id AND m.chat_id = c.chat_id
+WHERE c.username = $1 OR c.phone = $2
+`
+
+type GetChatRow struct {
+	Chat     Chat  `json:"chat"`
+	TeamRole int32 `json:"team_role"`
+}
+
+type GetChatParams struct {
+	Username pgtype.Text `json:"username"`
+	Phone    pgtype.Text `json:"phone"`
+}
+
+func (q *Queries) GetChat(ctx context.Context, arg *GetChatParams) (*GetChatRow, error) {
+	row := q.db.QueryRow(ctx, getChat, arg.Username, arg.Phone)
+	var i GetChatRow
+	err := row.Scan(
+		&i.Chat.ChatID,
+		&i.Chat.Username,
+		&i.Chat.Phone,
+		&i.Chat.Role,
+		&i.Chat.Stage,
+		&i.Chat.CreatedAt,
+		&i.Chat.LastActivityAt,
+		&i.Chat.TeamID,
+		&i.TeamRole,
+	)
+	return &i, err
+}
+
+const getChatByID = `-- name: GetChatByID :one
+SELECT c.chat_id, c.username, c.phone, c.role, c.stage, c.created_at, c.last_activity_at, c.team_id, COALESCE(m.role, 0)::int AS team_role, COALESCE(m.banned, false)::bool AS team_banned
+FROM chats c LEFT JOIN team_members m ON m.team_id = c.team_id AND m.chat_id = c.chat_id
+WHERE c.chat_id = $1
+`
+
+type GetChatByIDRow struct {
+	Chat       Chat  `json:"chat"`
+	TeamRole   int32 `json:"team_role"`
+	TeamBanned bool  `json:"team_banned"`
+}
+
+func (q *Queries) GetChatByID(ctx context.Context, chatID int64) (*GetChatByIDRow, error) {
+	row := q.db.QueryRow(ctx, getChatByID, chatID)
+	var i GetChatByIDRow
+	err := row.Scan(
+		&i.Chat.ChatID,
+		&i.Chat.Username,
+		&i.Chat.Phone,
+		&i.Chat.Role,
+		&i.Chat.Stage,
+		&i.Chat.CreatedAt,
+		&i.Chat.LastActivityAt,
+		&i.Chat.TeamID,
+		&i.TeamRole,
+		&i.TeamBanned,
+	)
+	return &i, err
+}
+
+const getChatTeams = `-- name: GetChatTeams :many
+SELECT t.id, t.name, m.role FROM team_members m JOIN teams t ON t.id = m.team_id WHERE m.chat_id = $1 ORDER BY t.id
+`
+
+type GetChatTeamsRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Role int32  `json:"role"`
+}
+
+func (q *Queries) GetChatTeams(ctx context.Context, chatID int64) ([]*GetChatTeamsRow, error) {
+	rows, err := q.db.Query(ctx, getChatTeams, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetChatTeamsRow
+	for rows.Next() {
+		var i GetChatTeamsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChatsStats = `-- name: GetChatsStats :many
+SELECT c.chat_id, c.username, c.phone, m.role, c.stage, m.banned, c.last_activity_at, m.manager_id,
+    (SELECT COUNT(*) FROM tasks t WHERE t.team_id = m.team_id AND t.done = false AND t.closed = false
+        AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)) AS open_tasks
+FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+WHERE m.team_id = $1 ORDER BY c.last_activity_at DESC NULLS LAST
+`
+
+type GetChatsStatsRow struct {
+	ChatID         int64            `json:"chat_id"`
+	Username       pgtype.Text      `json:"username"`
+	Phone          pgtype.Text      `json:"phone"`
+	Role           int32            `json:"role"`
+	Stage          pgtype.Int4      `json:"stage"`
+	Banned         bool             `json:"banned"`
+	LastActivityAt pgtype.Timestamp `json:"last_activity_at"`
+	ManagerID      pgtype.Int8      `json:"manager_id"`
+	OpenTasks      int64            `json:"open_tasks"`
+}
+
+func (q *Queries) GetChatsStats(ctx context.Context, teamID int64) ([]*GetChatsStatsRow, error) {
+	rows, err := q.db.Query(ctx, getChatsStats, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetChatsStatsRow
+	for rows.Next() {
+		var i GetChatsStatsRow
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.Username,
+			&i.Phone,
+			&i.Role,
+			&i.Stage,
+			&i.Banned,
+			&i.LastActivityAt,
+			&i.ManagerID,
+			&i.OpenTasks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChecklist = `-- name: GetChecklist :many
+SELECT id, task_id, title, done, created_at FROM checklist_items WHERE task_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetChecklist(ctx context.Context, taskID int64) ([]*ChecklistItem, error) {
+	rows, err := q.db.Query(ctx, getChecklist, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ChecklistItem
+	for rows.Next() {
+		var i ChecklistItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.Title,
+			&i.Done,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChecklistProgress = `-- name: GetChecklistProgress :many
+SELECT task_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE done) AS finished
+FROM checklist_items WHERE task_id = ANY($1::bigint[]) GROUP BY task_id
+`
+
+type GetChecklistProgressRow struct {
+	TaskID   int64 `json:"task_id"`
+	Total    int64 `json:"total"`
+	Finished int64 `json:"finished"`
+}
+
+func (q *Queries) GetChecklistProgress(ctx context.Context, taskIds []int64) ([]*GetChecklistProgressRow, error) {
+	rows, err := q.db.Query(ctx, getChecklistProgress, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetChecklistProgressRow
+	for rows.Next() {
+		var i GetChecklistProgressRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.Total,
+			&i.Finished,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClosedTasks = `-- name: GetClosedTasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE team_id = $1 AND closed = true
+`
+
+func (q *Queries) GetClosedTasks(ctx context.Context, teamID int64) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getClosedTasks, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getComments = `-- name: GetComments :many
+SELECT id, task_id, chat_id, author, text, created_at FROM task_comments WHERE task_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetComments(ctx context.Context, taskID int64) ([]*TaskComment, error) {
+	rows, err := q.db.Query(ctx, getComments, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*TaskComment
+	for rows.Next() {
+		var i TaskComment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ChatID,
+			&i.Author,
+			&i.Text,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentsCount = `-- name: GetCommentsCount :one
+SELECT COUNT(*) FROM task_comments WHERE task_id = $1
+`
+
+func (q *Queries) GetCommentsCount(ctx context.Context, taskID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getCommentsCount, taskID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getDashboards = `-- name: GetDashboards :many
+SELECT chat_id, team_id, message_id, created_at FROM dashboards ORDER BY chat_id
+`
+
+func (q *Queries) GetDashboards(ctx context.Context) ([]*Dashboard, error) {
+	rows, err := q.db.Query(ctx, getDashboards)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Dashboard
+	for rows.Next() {
+		var i Dashboard
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.TeamID,
+			&i.MessageID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDependentTasks = `-- name: GetDependentTasks :many
+SELECT t.id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, t.created_at, t.team_id, t.parent_id, t.description, t.priority, t.next_reminder_at, t.created_by, t.proof_required FROM task_dependencies d JOIN tasks t ON t.id = d.task_id WHERE d.blocker_id = $1
+`
+
+func (q *Queries) GetDependentTasks(ctx context.Context, blockerID int64) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getDependentTasks, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDoneTasks = `-- name: GetDoneTasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE team_id = $1 AND done = true
+`
+
+func (q *Queries) GetDoneTasks(ctx context.Context, teamID int64) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getDoneTasks, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueRecurrences = `-- name: GetDueRecurrences :many
+SELECT id, team_id, title, executor_contact, executor_chat_id, schedule, deadline_in, next_run, paused, created_by, created_at FROM recurrences WHERE paused = false AND next_run <= $1
+`
+
+func (q *Queries) GetDueRecurrences(ctx context.Context, nextRun pgtype.Timestamp) ([]*Recurrence, error) {
+	rows, err := q.db.Query(ctx, getDueRecurrences, nextRun)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Recurrence
+	for rows.Next() {
+		var i Recurrence
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Schedule,
+			&i.DeadlineIn,
+			&i.NextRun,
+			&i.Paused,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExecutorManager = `-- name: GetExecutorManager :one
+SELECT m.manager_id FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+WHERE m.team_id = $1 AND m.manager_id IS NOT NULL AND (c.chat_id = $2 OR c.username = $3 OR c.phone = $3)
+LIMIT 1
+`
+
+type GetExecutorManagerParams struct {
+	TeamID   int64       `json:"team_id"`
+	ChatID   int64       `json:"chat_id"`
+	Username pgtype.Text `json:"username"`
+}
+
+func (q *Queries) GetExecutorManager(ctx context.Context, arg *GetExecutorManagerParams) (pgtype.Int8, error) {
+	row := q.db.QueryRow(ctx, getExecutorManager, arg.TeamID, arg.ChatID, arg.Username)
+	var managerID pgtype.Int8
+	err := row.Scan(&managerID)
+	return managerID, err
+}
+
+const getExpiredTasks = `-- name: GetExpiredTasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE team_id = $1 AND expired = true
+`
+
+func (q *Queries) GetExpiredTasks(ctx context.Context, teamID int64) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getExpiredTasks, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExpiredTasksToMark = `-- name: GetExpiredTasksToMark :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE done = false AND expired = false AND deadline < (NOW() AT TIME ZONE 'UTC-3') FOR UPDATE
+`
+
+func (q *Queries) GetExpiredTasksToMark(ctx context.Context) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getExpiredTasksToMark)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFailedPasswordAttempts = `-- name: GetFailedPasswordAttempts :many
+SELECT chat_id, failures, lockouts, locked_until, updated_at FROM password_attempts WHERE failures > 0 OR lockouts > 0
+`
+
+func (q *Queries) GetFailedPasswordAttempts(ctx context.Context) ([]*PasswordAttempt, error) {
+	rows, err := q.db.Query(ctx, getFailedPasswordAttempts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*PasswordAttempt
+	for rows.Next() {
+		var i PasswordAttempt
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.Failures,
+			&i.Lockouts,
+			&i.LockedUntil,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInactiveChats = `-- name: GetInactiveChats :many
+SELECT chat_id, reason, created_at FROM inactive_chats ORDER BY chat_id
+`
+
+func (q *Queries) GetInactiveChats(ctx context.Context) ([]*InactiveChat, error) {
+	rows, err := q.db.Query(ctx, getInactiveChats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*InactiveChat
+	for rows.Next() {
+		var i InactiveChat
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLastUpdateID = `-- name: GetLastUpdateID :one
+SELECT COALESCE(MAX(update_id), 0)::bigint AS update_id FROM handled_updates
+`
+
+func (q *Queries) GetLastUpdateID(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getLastUpdateID)
+	var update_id int64
+	err := row.Scan(&update_id)
+	return update_id, err
+}
+
+const getMessageTask = `-- name: GetMessageTask :one
+SELECT t.team_id, m.task_id FROM task_messages m JOIN tasks t ON t.id = m.task_id
+WHERE m.chat_id = $1 AND m.message_id = $2
+`
+
+type GetMessageTaskParams struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int64 `json:"message_id"`
+}
+
+type GetMessageTaskRow struct {
+	TeamID int64 `json:"team_id"`
+	TaskID int64 `json:"task_id"`
+}
+
+func (q *Queries) GetMessageTask(ctx context.Context, arg *GetMessageTaskParams) (*GetMessageTaskRow, error) {
+	row := q.db.QueryRow(ctx, getMessageTask, arg.ChatID, arg.MessageID)
+	var i GetMessageTaskRow
+	err := row.Scan(&i.TeamID, &i.TaskID)
+	return &i, err
+}
+
+const getObservers = `-- name: GetObservers :many
+SELECT c.chat_id, c.username, c.phone, c.role, c.stage, c.created_at, c.last_activity_at, c.team_id FROM team_members m JOIN chats c ON c.chat_id = m.chat_id WHERE m.team_id = $1 AND m.role = 2
+`
+
+func (q *Queries) GetObservers(ctx context.Context, teamID int64) ([]*Chat, error) {
+	rows, err := q.db.Query(ctx, getObservers, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Chat
+	for rows.Next() {
+		var i Chat
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.Username,
+			&i.Phone,
+			&i.Role,
+			&i.Stage,
+			&i.CreatedAt,
+			&i.LastActivityAt,
+			&i.TeamID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOpenTasks = `-- name: GetOpenTasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE team_id = $1 AND closed = false
+`
+
+func (q *Queries) GetOpenTasks(ctx context.Context, teamID int64) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getOpenTasks, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOverdueTasks = `-- name: GetOverdueTasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE expired = true AND done = false AND closed = false AND priority = $1 ORDER BY team_id, deadline
+`
+
+func (q *Queries) GetOverdueTasks(ctx context.Context, priority int32) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getOverdueTasks, priority)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getParticipantTasks = `-- name: GetParticipantTasks :many
+SELECT t.id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, t.created_at, t.team_id, t.parent_id, t.description, t.priority, t.next_reminder_at, t.created_by, t.proof_required FROM tasks t JOIN task_participants p ON p.task_id = t.id WHERE t.team_id = $1 AND p.chat_id = $2 AND p.role = $3
+`
+
+type GetParticipantTasksParams struct {
+	TeamID int64 `json:"team_id"`
+	ChatID int64 `json:"chat_id"`
+	Role   int32 `json:"role"`
+}
+
+func (q *Queries) GetParticipantTasks(ctx context.Context, arg *GetParticipantTasksParams) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getParticipantTasks, arg.TeamID, arg.ChatID, arg.Role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPasswordAttempts = `-- name: GetPasswordAttempts :one
+SELECT chat_id, failures, lockouts, locked_until, updated_at FROM password_attempts WHERE chat_id = $1
+`
+
+func (q *Queries) GetPasswordAttempts(ctx context.Context, chatID int64) (*PasswordAttempt, error) {
+	row := q.db.QueryRow(ctx, getPasswordAttempts, chatID)
+	var i PasswordAttempt
+	err := row.Scan(
+		&i.ChatID,
+		&i.Failures,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const getProofs = `-- name: GetProofs :many
+SELECT id, task_id, chat_id, author, kind, file_id, text, latitude, longitude, created_at FROM task_proofs WHERE task_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetProofs(ctx context.Context, taskID int64) ([]*TaskProof, error) {
+	rows, err := q.db.Query(ctx, getProofs, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*TaskProof
+	for rows.Next() {
+		var i TaskProof
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ChatID,
+			&i.Author,
+			&i.Kind,
+			&i.FileID,
+			&i.Text,
+			&i.Latitude,
+			&i.Longitude,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecurrence = `-- name: GetRecurrence :one
+SELECT id, team_id, title, executor_contact, executor_chat_id, schedule, deadline_in, next_run, paused, created_by, created_at FROM recurrences WHERE id = $1 AND team_id = $2
+`
+
+type GetRecurrenceParams struct {
+	ID     int64 `json:"id"`
+	TeamID int64 `json:"team_id"`
+}
+
+func (q *Queries) GetRecurrence(ctx context.Context, arg *GetRecurrenceParams) (*Recurrence, error) {
+	row := q.db.QueryRow(ctx, getRecurrence, arg.ID, arg.TeamID)
+	var i Recurrence
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.Title,
+		&i.ExecutorContact,
+		&i.ExecutorChatID,
+		&i.Schedule,
+		&i.DeadlineIn,
+		&i.NextRun,
+		&i.Paused,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getRecurrences = `-- name: GetRecurrences :many
+SELECT id, team_id, title, executor_contact, executor_chat_id, schedule, deadline_in, next_run, paused, created_by, created_at FROM recurrences WHERE team_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetRecurrences(ctx context.Context, teamID int64) ([]*Recurrence, error) {
+	rows, err := q.db.Query(ctx, getRecurrences, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Recurrence
+	for rows.Next() {
+		var i Recurrence
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Schedule,
+			&i.DeadlineIn,
+			&i.NextRun,
+			&i.Paused,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRole = `-- name: GetRole :one
+SELECT role FROM team_members WHERE team_id = $1 AND chat_id = $2
+`
+
+type GetRoleParams struct {
+	TeamID int64 `json:"team_id"`
+	ChatID int64 `json:"chat_id"`
+}
+
+func (q *Queries) GetRole(ctx context.Context, arg *GetRoleParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getRole, arg.TeamID, arg.ChatID)
+	var role int32
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getStage = `-- name: GetStage :one
+SELECT stage FROM chats WHERE chat_id = $1
+`
+
+func (q *Queries) GetStage(ctx context.Context, chatID int64) (pgtype.Int4, error) {
+	row := q.db.QueryRow(ctx, getStage, chatID)
+	var stage pgtype.Int4
+	err := row.Scan(&stage)
+	return stage, err
+}
+
+const getSubordinates = `-- name: GetSubordinates :many
+SELECT c.chat_id, c.username, c.phone, c.role, c.stage, c.created_at, c.last_activity_at, c.team_id FROM team_members m JOIN chats c ON c.chat_id = m.chat_id WHERE m.team_id = $1 AND m.manager_id = $2
+`
+
+type GetSubordinatesParams struct {
+	TeamID    int64       `json:"team_id"`
+	ManagerID pgtype.Int8 `json:"manager_id"`
+}
+
+func (q *Queries) GetSubordinates(ctx context.Context, arg *GetSubordinatesParams) ([]*Chat, error) {
+	rows, err := q.db.Query(ctx, getSubordinates, arg.TeamID, arg.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Chat
+	for rows.Next() {
+		var i Chat
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.Username,
+			&i.Phone,
+			&i.Role,
+			&i.Stage,
+			&i.CreatedAt,
+			&i.LastActivityAt,
+			&i.TeamID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSubordinatesTasks = `-- name: GetSubordinatesTasks :many
+SELECT t.id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, t.created_at, t.team_id, t.parent_id, t.description, t.priority, t.next_reminder_at, t.created_by, t.proof_required FROM tasks t WHERE t.team_id = $1 AND EXISTS (
+    SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+    WHERE m.team_id = t.team_id AND m.manager_id = $2
+        AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)
+)
+`
+
+type GetSubordinatesTasksParams struct {
+	TeamID    int64       `json:"team_id"`
+	ManagerID pgtype.Int8 `json:"manager_id"`
+}
+
+func (q *Queries) GetSubordinatesTasks(ctx context.Context, arg *GetSubordinatesTasksParams) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getSubordinatesTasks, arg.TeamID, arg.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSubtasks = `-- name: GetSubtasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE team_id = $1 AND parent_id = $2
+`
+
+type GetSubtasksParams struct {
+	TeamID   int64       `json:"team_id"`
+	ParentID pgtype.Int8 `json:"parent_id"`
+}
+
+func (q *Queries) GetSubtasks(ctx context.Context, arg *GetSubtasksParams) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getSubtasks, arg.TeamID, arg.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSubtasksProgress = `-- name: GetSubtasksProgress :many
+SELECT parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE done OR closed) AS finished
+FROM tasks WHERE parent_id = ANY($1::bigint[]) GROUP BY parent_id
+`
+
+type GetSubtasksProgressRow struct {
+	ParentID pgtype.Int8 `json:"parent_id"`
+	Total    int64       `json:"total"`
+	Finished int64       `json:"finished"`
+}
+
+func (q *Queries) GetSubtasksProgress(ctx context.Context, taskIds []int64) ([]*GetSubtasksProgressRow, error) {
+	rows, err := q.db.Query(ctx, getSubtasksProgress, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetSubtasksProgressRow
+	for rows.Next() {
+		var i GetSubtasksProgressRow
+		if err := rows.Scan(
+			&i.ParentID,
+			&i.Total,
+			&i.Finished,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTask = `-- name: GetTask :one
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE id = $1 AND team_id = $2
+`
+
+type GetTaskParams struct {
+	ID     int64 `json:"id"`
+	TeamID int64 `json:"team_id"`
+}
+
+func (q *Queries) GetTask(ctx context.Context, arg *GetTaskParams) (*Task, error) {
+	row := q.db.QueryRow(ctx, getTask, arg.ID, arg.TeamID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.ExecutorContact,
+		&i.ExecutorChatID,
+		&i.Deadline,
+		&i.Done,
+		&i.Closed,
+		&i.Expired,
+		&i.CreatedAt,
+		&i.TeamID,
+		&i.ParentID,
+		&i.Description,
+		&i.Priority,
+		&i.NextReminderAt,
+		&i.CreatedBy,
+		&i.ProofRequired,
+	)
+	return &i, err
+}
+
+const getTaskCards = `-- name: GetTaskCards :many
+SELECT task_id, chat_id, message_id, updated_at FROM task_cards WHERE task_id = $1
+`
+
+func (q *Queries) GetTaskCards(ctx context.Context, taskID int64) ([]*TaskCard, error) {
+	rows, err := q.db.Query(ctx, getTaskCards, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*TaskCard
+	for rows.Next() {
+		var i TaskCard
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.ChatID,
+			&i.MessageID,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTaskInProgress = `-- name: GetTaskInProgress :one
+SELECT chat_id, title, executor_contact, executor_chat_id, deadline, created_at, priority FROM tasks_in_progress WHERE chat_id = $1
+`
+
+func (q *Queries) GetTaskInProgress(ctx context.Context, chatID int64) (*TasksInProgress, error) {
+	row := q.db.QueryRow(ctx, getTaskInProgress, chatID)
+	var i TasksInProgress
+	err := row.Scan(
+		&i.ChatID,
+		&i.Title,
+		&i.ExecutorContact,
+		&i.ExecutorChatID,
+		&i.Deadline,
+		&i.CreatedAt,
+		&i.Priority,
+	)
+	return &i, err
+}
+
+const getTaskParticipants = `-- name: GetTaskParticipants :many
+SELECT task_id, chat_id, role, done, created_at FROM task_participants WHERE task_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetTaskParticipants(ctx context.Context, taskID int64) ([]*TaskParticipant, error) {
+	rows, err := q.db.Query(ctx, getTaskParticipants, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*TaskParticipant
+	for rows.Next() {
+		var i TaskParticipant
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.ChatID,
+			&i.Role,
+			&i.Done,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTasks = `-- name: GetTasks :many
+SELECT t.id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, t.created_at, t.team_id, t.parent_id, t.description, t.priority, t.next_reminder_at, t.created_by, t.proof_required FROM tasks t
+WHERE t.team_id = $1
+    AND ($2::bigint IS NULL OR EXISTS (
+        SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+        WHERE m.team_id = t.team_id AND m.manager_id = $2
+            AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)
+    ))
+    AND ($3::bigint IS NULL OR t.executor_chat_id = $3
+        OR t.executor_contact IN (NULLIF($4::text, ''), NULLIF($5::text, ''))
+        OR EXISTS (SELECT 1 FROM task_participants p WHERE p.task_id = t.id AND p.chat_id = $3 AND p.role = 1))
+    AND (cardinality($6::text[]) = 0 OR (
+        SELECT COUNT(*) FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+        WHERE tt.task_id = t.id AND g.name = ANY($6::text[])
+    ) = cardinality($6::text[]))
+    AND (cardinality($7::int[]) = 0 OR t.priority = ANY($7::int[]))
+    AND (NOT $8::boolean OR (t.done = false AND t.closed = false))
+    AND (NOT $9::boolean OR t.done = true)
+    AND (NOT $10::boolean OR t.closed = true)
+    AND (NOT $11::boolean OR (t.done = false AND t.closed = false AND (t.expired = true OR t.deadline < $12::timestamp)))
+`
+
+type GetTasksParams struct {
+	TeamID           int64            `json:"team_id"`
+	ManagerID        pgtype.Int8      `json:"manager_id"`
+	ExecutorChatID   pgtype.Int8      `json:"executor_chat_id"`
+	ExecutorUsername string           `json:"executor_username"`
+	ExecutorPhone    string           `json:"executor_phone"`
+	Tags             []string         `json:"tags"`
+	Priorities       []int32          `json:"priorities"`
+	Open             bool             `json:"open"`
+	Done             bool             `json:"done"`
+	Closed           bool             `json:"closed"`
+	Overdue          bool             `json:"overdue"`
+	Now              pgtype.Timestamp `json:"now"`
+}
+
+func (q *Queries) GetTasks(ctx context.Context, arg *GetTasksParams) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getTasks,
+		arg.TeamID,
+		arg.ManagerID,
+		arg.ExecutorChatID,
+		arg.ExecutorUsername,
+		arg.ExecutorPhone,
+		arg.Tags,
+		arg.Priorities,
+		arg.Open,
+		arg.Done,
+		arg.Closed,
+		arg.Overdue,
+		arg.Now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTasksTags = `-- name: GetTasksTags :many
+SELECT tt.task_id, g.name FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+WHERE tt.task_id = ANY($1::bigint[]) ORDER BY g.name
+`
+
+type GetTasksTagsRow struct {
+	TaskID int64  `json:"task_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) GetTasksTags(ctx context.Context, taskIds []int64) ([]*GetTasksTagsRow, error) {
+	rows, err := q.db.Query(ctx, getTasksTags, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetTasksTagsRow
+	for rows.Next() {
+		var i GetTasksTagsRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTasksToRemind = `-- name: GetTasksToRemind :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks
+WHERE done = false AND closed = false AND expired = false AND deadline > $1 AND (next_reminder_at IS NULL OR next_reminder_at <= $1)
+`
+
+func (q *Queries) GetTasksToRemind(ctx context.Context, deadline pgtype.Timestamp) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getTasksToRemind, deadline)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeamByName = `-- name: GetTeamByName :one
+SELECT id, name, created_at FROM teams WHERE name = $1
+`
+
+func (q *Queries) GetTeamByName(ctx context.Context, name string) (*Team, error) {
+	row := q.db.QueryRow(ctx, getTeamByName, name)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getTemplate = `-- name: GetTemplate :one
+SELECT team_id, name, title_pattern, executor_contact, deadline_in, description, checklist, created_by, created_at, priority, proof_required FROM task_templates WHERE team_id = $1 AND name = $2
+`
+
+type GetTemplateParams struct {
+	TeamID int64  `json:"team_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) GetTemplate(ctx context.Context, arg *GetTemplateParams) (*TaskTemplate, error) {
+	row := q.db.QueryRow(ctx, getTemplate, arg.TeamID, arg.Name)
+	var i TaskTemplate
+	err := row.Scan(
+		&i.TeamID,
+		&i.Name,
+		&i.TitlePattern,
+		&i.ExecutorContact,
+		&i.DeadlineIn,
+		&i.Description,
+		&i.Checklist,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.Priority,
+		&i.ProofRequired,
+	)
+	return &i, err
+}
+
+const getTemplates = `-- name: GetTemplates :many
+SELECT team_id, name, title_pattern, executor_contact, deadline_in, description, checklist, created_by, created_at, priority, proof_required FROM task_templates WHERE team_id = $1 ORDER BY name
+`
+
+func (q *Queries) GetTemplates(ctx context.Context, teamID int64) ([]*TaskTemplate, error) {
+	rows, err := q.db.Query(ctx, getTemplates, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*TaskTemplate
+	for rows.Next() {
+		var i TaskTemplate
+		if err := rows.Scan(
+			&i.TeamID,
+			&i.Name,
+			&i.TitlePattern,
+			&i.ExecutorContact,
+			&i.DeadlineIn,
+			&i.Description,
+			&i.Checklist,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.Priority,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTasks = `-- name: GetUserTasks :many
+SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at, team_id, parent_id, description, priority, next_reminder_at, created_by, proof_required FROM tasks WHERE team_id = $1 AND (executor_contact = $2 or executor_contact = $3)
+`
+
+type GetUserTasksParams struct {
+	TeamID            int64  `json:"team_id"`
+	ExecutorContact   string `json:"executor_contact"`
+	ExecutorContact_2 string `json:"executor_contact_2"`
+}
+
+func (q *Queries) GetUserTasks(ctx context.Context, arg *GetUserTasksParams) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, getUserTasks, arg.TeamID, arg.ExecutorContact, arg.ExecutorContact_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.ExecutorContact,
+			&i.ExecutorChatID,
+			&i.Deadline,
+			&i.Done,
+			&i.Closed,
+			&i.Expired,
+			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getView = `-- name: GetView :one
+SELECT team_id, chat_id, name, query, created_at FROM saved_views WHERE team_id = $1 AND chat_id = $2 AND name = $3
+`
+
+type GetViewParams struct {
+	TeamID int64  `json:"team_id"`
+	ChatID int64  `json:"chat_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) GetView(ctx context.Context, arg *GetViewParams) (*SavedView, error) {
+	row := q.db.QueryRow(ctx, getView, arg.TeamID, arg.ChatID, arg.Name)
+	var i SavedView
+	err := row.Scan(
+		&i.TeamID,
+		&i.ChatID,
+		&i.Name,
+		&i.Query,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getViews = `-- name: GetViews :many
+SELECT team_id, chat_id, name, query, created_at FROM saved_views WHERE team_id = $1 AND chat_id = $2 ORDER BY name
+`
+
+type GetViewsParams struct {
+	TeamID int64 `json:"team_id"`
+	ChatID int64 `json:"chat_id"`
+}
+
+func (q *Queries) GetViews(ctx context.Context, arg *GetViewsParams) ([]*SavedView, error) {
+	rows, err := q.db.Query(ctx, getViews, arg.TeamID, arg.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*SavedView
+	for rows.Next() {
+		var i SavedView
+		if err := rows.Scan(
+			&i.TeamID,
+			&i.ChatID,
+			&i.Name,
+			&i.Query,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const linkTasksByPhone = `-- name: LinkTasksByPhone :execrows
+UPDATE tasks SET executor_chat_id = $2
+WHERE executor_contact = $1 AND COALESCE(executor_chat_id, 0) != $2
+    AND team_id IN (SELECT team_id FROM team_members WHERE chat_id = $2)
+`
+
+type LinkTasksByPhoneParams struct {
+	ExecutorContact string      `json:"executor_contact"`
+	ExecutorChatID  pgtype.Int8 `json:"executor_chat_id"`
+}
+
+func (q *Queries) LinkTasksByPhone(ctx context.Context, arg *LinkTasksByPhoneParams) (int64, error) {
+	result, err := q.db.Exec(ctx, linkTasksByPhone, arg.ExecutorContact, arg.ExecutorChatID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const lockPasswordAttempts = `-- name: LockPasswordAttempts :execrows
+UPDATE password_attempts SET failures = 0, lockouts = lockouts + 1, locked_until = $3, updated_at = CURRENT_TIMESTAMP
+WHERE chat_id = $1 AND lockouts = $2
+`
+
+type LockPasswordAttemptsParams struct {
+	ChatID      int64            `json:"chat_id"`
+	Lockouts    int32            `json:"lockouts"`
+	LockedUntil pgtype.Timestamp `json:"locked_until"`
+}
+
+func (q *Queries) LockPasswordAttempts(ctx context.Context, arg *LockPasswordAttemptsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, lockPasswordAttempts, arg.ChatID, arg.Lockouts, arg.LockedUntil)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markExpiredTask = `-- name: MarkExpiredTask :execrows
+UPDATE tasks SET expired = true WHERE id = $1
+`
+
+func (q *Queries) MarkExpiredTask(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, markExpiredTask, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markParticipantDone = `-- name: MarkParticipantDone :execrows
+UPDATE task_participants SET done = true WHERE task_id = $1 AND chat_id = $2 AND role = 1
+`
+
+type MarkParticipantDoneParams struct {
+	TaskID int64 `json:"task_id"`
+	ChatID int64 `json:"chat_id"`
+}
+
+func (q *Queries) MarkParticipantDone(ctx context.Context, arg *MarkParticipantDoneParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markParticipantDone, arg.TaskID, arg.ChatID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markTaskAsClosed = `-- name: MarkTaskAsClosed :execrows
+UPDATE tasks SET closed = true WHERE id = $1 AND team_id = $2
+`
+
+type MarkTaskAsClosedParams struct {
+	ID     int64 `json:"id"`
+	TeamID int64 `json:"team_id"`
+}
+
+func (q *Queries) MarkTaskAsClosed(ctx context.Context, arg *MarkTaskAsClosedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markTaskAsClosed, arg.ID, arg.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markTaskAsDone = `-- name: MarkTaskAsDone :execrows
+UPDATE tasks SET done = true WHERE id = $1 AND team_id = $2
+`
+
+type MarkTaskAsDoneParams struct {
+	ID     int64 `json:"id"`
+	TeamID int64 `json:"team_id"`
+}
+
+func (q *Queries) MarkTaskAsDone(ctx context.Context, arg *MarkTaskAsDoneParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markTaskAsDone, arg.ID, arg.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const redeemInvite = `-- name: RedeemInvite :one
+UPDATE invites SET redeemed_by = $2, redeemed_at = $3
+WHERE code = $1 AND revoked = false AND redeemed_by IS NULL AND (expires_at IS NULL OR expires_at > $3) AND (username IS NULL OR username = '' OR lower(username) = lower($4))
+RETURNING code, role, username, created_by, redeemed_by, redeemed_at, expires_at, revoked, created_at, team_id
+`
+
+type RedeemInviteParams struct {
+	Code       string           `json:"code"`
+	RedeemedBy pgtype.Int8      `json:"redeemed_by"`
+	RedeemedAt pgtype.Timestamp `json:"redeemed_at"`
+	Username   pgtype.Text      `json:"username"`
+}
+
+func (q *Queries) RedeemInvite(ctx context.Context, arg *RedeemInviteParams) (*Invite, error) {
+	row := q.db.QueryRow(ctx, redeemInvite,
+		arg.Code,
+		arg.RedeemedBy,
+		arg.RedeemedAt,
+		arg.Username,
+	)
+	var i Invite
+	err := row.Scan(
+		&i.Code,
+		&i.Role,
+		&i.Username,
+		&i.CreatedBy,
+		&i.RedeemedBy,
+		&i.RedeemedAt,
+		&i.ExpiresAt,
+		&i.Revoked,
+		&i.CreatedAt,
+		&i.TeamID,
+	)
+	return &i, err
+}
+
+const registerPasswordFailure = `-- name: RegisterPasswordFailure :one
+INSERT INTO password_attempts (chat_id, failures, lockouts, updated_at) VALUES ($1, 1, 0, CURRENT_TIMESTAMP)
+ON CONFLICT (chat_id) DO UPDATE SET failures = password_attempts.failures + 1, updated_at = CURRENT_TIMESTAMP
+WHERE password_attempts.locked_until IS NULL OR password_attempts.locked_until <= $2
+RETURNING chat_id, failures, lockouts, locked_until, updated_at
+`
+
+type RegisterPasswordFailureParams struct {
+	ChatID      int64            `json:"chat_id"`
+	LockedUntil pgtype.Timestamp `json:"locked_until"`
+}
+
+func (q *Queries) RegisterPasswordFailure(ctx context.Context, arg *RegisterPasswordFailureParams) (*PasswordAttempt, error) {
+	row := q.db.QueryRow(ctx, registerPasswordFailure, arg.ChatID, arg.LockedUntil)
+	var i PasswordAttempt
+	err := row.Scan(
+		&i.ChatID,
+		&i.Failures,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const removeTaskDependency = `-- name: RemoveTaskDependency :execrows
+DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2
 `
 
-func (q *Queries) GetExpiredTasks(ctx context.Context) ([]*Task, error) {
-	rows, err := q.db.Query(ctx, getExpiredTasks)
+type RemoveTaskDependencyParams struct {
+	TaskID    int64 `json:"task_id"`
+	BlockerID int64 `json:"blocker_id"`
+}
+
+func (q *Queries) RemoveTaskDependency(ctx context.Context, arg *RemoveTaskDependencyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeTaskDependency, arg.TaskID, arg.BlockerID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []*Task
-	for rows.Next() {
-		var i Task
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.ExecutorContact,
-			&i.ExecutorChatID,
-			&i.Deadline,
-			&i.Done,
-			&i.Closed,
-			&i.Expired,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, &i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getExpiredTasksToMark = `-- name: GetExpiredTasksToMark :many
-SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at FROM tasks WHERE done = false AND expired = false AND deadline < (NOW() AT TIME ZONE 'UTC-3') FOR UPDATE
+const removeTaskParticipant = `-- name: RemoveTaskParticipant :execrows
+DELETE FROM task_participants WHERE task_id = $1 AND chat_id = $2
 `
 
-func (q *Queries) GetExpiredTasksToMark(ctx context.Context) ([]*Task, error) {
-	rows, err := q.db.Query(ctx, getExpiredTasksToMark)
+type RemoveTaskParticipantParams struct {
+	TaskID int64 `json:"task_id"`
+	ChatID int64 `json:"chat_id"`
+}
+
+func (q *Queries) RemoveTaskParticipant(ctx context.Context, arg *RemoveTaskParticipantParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeTaskParticipant, arg.TaskID, arg.ChatID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []*Task
-	for rows.Next() {
-		var i Task
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.ExecutorContact,
-			&i.ExecutorChatID,
-			&i.Deadline,
-			&i.Done,
-			&i.Closed,
-			&i.Expired,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, &i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getObservers = `-- name: GetObservers :many
-SELECT chat_id, username, phone, role, stage, created_at FROM chats WHERE role = 2
+const resetPasswordAttempts = `-- name: ResetPasswordAttempts :execrows
+DELETE FROM password_attempts WHERE chat_id = $1
 `
 
-func (q *Queries) GetObservers(ctx context.Context) ([]*Chat, error) {
-	rows, err := q.db.Query(ctx, getObservers)
+func (q *Queries) ResetPasswordAttempts(ctx context.Context, chatID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, resetPasswordAttempts, chatID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []*Chat
-	for rows.Next() {
-		var i Chat
-		if err := rows.Scan(
-			&i.ChatID,
-			&i.Username,
-			&i.Phone,
-			&i.Role,
-			&i.Stage,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, &i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getOpenTasks = `-- name: GetOpenTasks :many
-SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at FROM tasks WHERE closed = false
+const revokeInvite = `-- name: RevokeInvite :execrows
+UPDATE invites SET revoked = true WHERE code = $1 AND team_id = $2 AND redeemed_by IS NULL
 `
 
-func (q *Queries) GetOpenTasks(ctx context.Context) ([]*Task, error) {
-	rows, err := q.db.Query(ctx, getOpenTasks)
+type RevokeInviteParams struct {
+	Code   string `json:"code"`
+	TeamID int64  `json:"team_id"`
+}
+
+func (q *Queries) RevokeInvite(ctx context.Context, arg *RevokeInviteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeInvite, arg.Code, arg.TeamID)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []*Task
-	for rows.Next() {
-		var i Task
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.ExecutorContact,
-			&i.ExecutorChatID,
-			&i.Deadline,
-			&i.Done,
-			&i.Closed,
-			&i.Expired,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, &i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getRole = `-- name: GetRole :one
-SELECT role FROM chats WHERE chat_id = $1
+const saveDashboard = `-- name: SaveDashboard :exec
+INSERT INTO dashboards (chat_id, team_id, message_id) VALUES ($1, $2, $3)
+ON CONFLICT (chat_id) DO UPDATE SET team_id = EXCLUDED.team_id, message_id = EXCLUDED.message_id
 `
 
-func (q *Queries) GetRole(ctx context.Context, chatID int64) (pgtype.Int4, error) {
-	row := q.db.QueryRow(ctx, getRole, chatID)
-	var role pgtype.Int4
-	err := row.Scan(&role)
-	return role, err
+type SaveDashboardParams struct {
+	ChatID    int64 `json:"chat_id"`
+	TeamID    int64 `json:"team_id"`
+	MessageID int64 `json:"message_id"`
 }
 
-const getStage = `-- name: GetStage :one
-SELECT stage FROM chats WHERE chat_id = $1
+func (q *Queries) SaveDashboard(ctx context.Context, arg *SaveDashboardParams) error {
+	_, err := q.db.Exec(ctx, saveDashboard, arg.ChatID, arg.TeamID, arg.MessageID)
+	return err
+}
+
+const saveTemplate = `-- name: SaveTemplate :exec
+INSERT INTO task_templates (team_id, name, title_pattern, executor_contact, deadline_in, description, checklist, created_by, priority, proof_required)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (team_id, name) DO UPDATE SET
+    title_pattern = EXCLUDED.title_pattern,
+    executor_contact = EXCLUDED.executor_contact,
+    deadline_in = EXCLUDED.deadline_in,
+    priority = EXCLUDED.priority,
+    description = EXCLUDED.description,
+    checklist = EXCLUDED.checklist,
+    created_by = EXCLUDED.created_by,
+    proof_required = EXCLUDED.proof_required
 `
 
-func (q *Queries) GetStage(ctx context.Context, chatID int64) (pgtype.Int4, error) {
-	row := q.db.QueryRow(ctx, getStage, chatID)
-	var stage pgtype.Int4
-	err := row.Scan(&stage)
-	return stage, err
+type SaveTemplateParams struct {
+	TeamID          int64    `json:"team_id"`
+	Name            string   `json:"name"`
+	TitlePattern    string   `json:"title_pattern"`
+	ExecutorContact string   `json:"executor_contact"`
+	DeadlineIn      int64    `json:"deadline_in"`
+	Description     string   `json:"description"`
+	Checklist       []string `json:"checklist"`
+	CreatedBy       int64    `json:"created_by"`
+	Priority        int32    `json:"priority"`
+	ProofRequired   bool     `json:"proof_required"`
 }
 
-const getTaskInProgress = `-- name: GetTaskInProgress :one
-SELECT chat_id, title, executor_contact, executor_chat_id, deadline, created_at FROM tasks_in_progress WHERE chat_id = $1
+func (q *Queries) SaveTemplate(ctx context.Context, arg *SaveTemplateParams) error {
+	_, err := q.db.Exec(ctx, saveTemplate,
+		arg.TeamID,
+		arg.Name,
+		arg.TitlePattern,
+		arg.ExecutorContact,
+		arg.DeadlineIn,
+		arg.Description,
+		arg.Checklist,
+		arg.CreatedBy,
+		arg.Priority,
+		arg.ProofRequired,
+	)
+	return err
+}
+
+const saveView = `-- name: SaveView :exec
+INSERT INTO saved_views (team_id, chat_id, name, query) VALUES ($1, $2, $3, $4)
+ON CONFLICT (team_id, chat_id, name) DO UPDATE SET query = EXCLUDED.query
 `
 
-func (q *Queries) GetTaskInProgress(ctx context.Context, chatID int64) (*TasksInProgress, error) {
-	row := q.db.QueryRow(ctx, getTaskInProgress, chatID)
-	var i TasksInProgress
-	err := row.Scan(
-		&i.ChatID,
-		&i.Title,
-		&i.ExecutorContact,
-		&i.ExecutorChatID,
-		&i.Deadline,
-		&i.CreatedAt,
+type SaveViewParams struct {
+	TeamID int64  `json:"team_id"`
+	ChatID int64  `json:"chat_id"`
+	Name   string `json:"name"`
+	Query  string `json:"query"`
+}
+
+func (q *Queries) SaveView(ctx context.Context, arg *SaveViewParams) error {
+	_, err := q.db.Exec(ctx, saveView,
+		arg.TeamID,
+		arg.ChatID,
+		arg.Name,
+		arg.Query,
 	)
-	return &i, err
+	return err
 }
 
-const getUserTasks = `-- name: GetUserTasks :many
-SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, created_at FROM tasks WHERE executor_contact = $1 or executor_contact = $2
+const searchTasks = `-- name: SearchTasks :many
+SELECT t.id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, t.created_at, t.team_id, t.parent_id, t.description, t.priority, t.next_reminder_at, t.created_by, t.proof_required FROM tasks t
+WHERE t.team_id = $1
+    AND (to_tsvector('russian', t.title || ' ' || t.description) @@ plainto_tsquery('russian', $2::text)
+        OR EXISTS (
+            SELECT 1 FROM task_comments tc
+            WHERE tc.task_id = t.id AND to_tsvector('russian', tc.text) @@ plainto_tsquery('russian', $2::text)
+        ))
+    AND ($3::bigint IS NULL OR EXISTS (
+        SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+        WHERE m.team_id = t.team_id AND m.manager_id = $3
+            AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)
+    ))
+    AND ($4::bigint IS NULL OR t.executor_chat_id = $4
+        OR t.executor_contact IN (NULLIF($5::text, ''), NULLIF($6::text, ''))
+        OR EXISTS (SELECT 1 FROM task_participants p WHERE p.task_id = t.id AND p.chat_id = $4 AND p.role = 1))
+    AND (cardinality($7::text[]) = 0 OR (
+        SELECT COUNT(*) FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+        WHERE tt.task_id = t.id AND g.name = ANY($7::text[])
+    ) = cardinality($7::text[]))
+    AND (cardinality($8::int[]) = 0 OR t.priority = ANY($8::int[]))
+    AND (NOT $9::boolean OR (t.done = false AND t.closed = false))
+    AND (NOT $10::boolean OR t.done = true)
+    AND (NOT $11::boolean OR t.closed = true)
+    AND (NOT $12::boolean OR (t.done = false AND t.closed = false AND (t.expired = true OR t.deadline < $13::timestamp)))
+ORDER BY ts_rank(to_tsvector('russian', t.title || ' ' || t.description), plainto_tsquery('russian', $2::text)) DESC, t.id
+LIMIT $14 OFFSET $15
 `
 
-type GetUserTasksParams struct {
-	ExecutorContact   string `json:"executor_contact"`
-	ExecutorContact_2 string `json:"executor_contact_2"`
+type SearchTasksParams struct {
+	TeamID           int64            `json:"team_id"`
+	Query            string           `json:"query"`
+	ManagerID        pgtype.Int8      `json:"manager_id"`
+	ExecutorChatID   pgtype.Int8      `json:"executor_chat_id"`
+	ExecutorUsername string           `json:"executor_username"`
+	ExecutorPhone    string           `json:"executor_phone"`
+	Tags             []string         `json:"tags"`
+	Priorities       []int32          `json:"priorities"`
+	Open             bool             `json:"open"`
+	Done             bool             `json:"done"`
+	Closed           bool             `json:"closed"`
+	Overdue          bool             `json:"overdue"`
+	Now              pgtype.Timestamp `json:"now"`
+	PageLimit        int32            `json:"page_limit"`
+	PageOffset       int32            `json:"page_offset"`
 }
 
-func (q *Queries) GetUserTasks(ctx context.Context, arg *GetUserTasksParams) ([]*Task, error) {
-	rows, err := q.db.Query(ctx, getUserTasks, arg.ExecutorContact, arg.ExecutorContact_2)
+func (q *Queries) SearchTasks(ctx context.Context, arg *SearchTasksParams) ([]*Task, error) {
+	rows, err := q.db.Query(ctx, searchTasks,
+		arg.TeamID,
+		arg.Query,
+		arg.ManagerID,
+		arg.ExecutorChatID,
+		arg.ExecutorUsername,
+		arg.ExecutorPhone,
+		arg.Tags,
+		arg.Priorities,
+		arg.Open,
+		arg.Done,
+		arg.Closed,
+		arg.Overdue,
+		arg.Now,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -405,6 +2677,13 @@ func (q *Queries) GetUserTasks(ctx context.Context, arg *GetUserTasksParams) ([]
 			&i.Closed,
 			&i.Expired,
 			&i.CreatedAt,
+			&i.TeamID,
+			&i.ParentID,
+			&i.Description,
+			&i.Priority,
+			&i.NextReminderAt,
+			&i.CreatedBy,
+			&i.ProofRequired,
 		); err != nil {
 			return nil, err
 		}
@@ -416,36 +2695,119 @@ func (q *Queries) GetUserTasks(ctx context.Context, arg *GetUserTasksParams) ([]
 	return items, nil
 }
 
-const markExpiredTask = `-- name: MarkExpiredTask :execrows
-UPDATE tasks SET expired = true WHERE id = $1
+const setActiveTeam = `-- name: SetActiveTeam :execrows
+UPDATE chats SET team_id = $2 WHERE chat_id = $1
 `
 
-func (q *Queries) MarkExpiredTask(ctx context.Context, id int64) (int64, error) {
-	result, err := q.db.Exec(ctx, markExpiredTask, id)
+type SetActiveTeamParams struct {
+	ChatID int64 `json:"chat_id"`
+	TeamID int64 `json:"team_id"`
+}
+
+func (q *Queries) SetActiveTeam(ctx context.Context, arg *SetActiveTeamParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setActiveTeam, arg.ChatID, arg.TeamID)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected(), nil
 }
 
-const markTaskAsClosed = `-- name: MarkTaskAsClosed :execrows
-UPDATE tasks SET closed = true WHERE id = $1
+const setBanned = `-- name: SetBanned :execrows
+UPDATE team_members SET banned = $3 WHERE team_id = $1 AND chat_id = $2
 `
 
-func (q *Queries) MarkTaskAsClosed(ctx context.Context, id int64) (int64, error) {
-	result, err := q.db.Exec(ctx, markTaskAsClosed, id)
+type SetBannedParams struct {
+	TeamID int64 `json:"team_id"`
+	ChatID int64 `json:"chat_id"`
+	Banned bool  `json:"banned"`
+}
+
+func (q *Queries) SetBanned(ctx context.Context, arg *SetBannedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setBanned, arg.TeamID, arg.ChatID, arg.Banned)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected(), nil
 }
 
-const markTaskAsDone = `-- name: MarkTaskAsDone :execrows
-UPDATE tasks SET done = true WHERE id = $1
+const setChatActive = `-- name: SetChatActive :exec
+DELETE FROM inactive_chats WHERE chat_id = $1
+`
+
+func (q *Queries) SetChatActive(ctx context.Context, chatID int64) error {
+	_, err := q.db.Exec(ctx, setChatActive, chatID)
+	return err
+}
+
+const setChatInactive = `-- name: SetChatInactive :exec
+INSERT INTO inactive_chats (chat_id, reason) VALUES ($1, $2)
+ON CONFLICT (chat_id) DO UPDATE SET reason = EXCLUDED.reason
+`
+
+type SetChatInactiveParams struct {
+	ChatID int64  `json:"chat_id"`
+	Reason string `json:"reason"`
+}
+
+func (q *Queries) SetChatInactive(ctx context.Context, arg *SetChatInactiveParams) error {
+	_, err := q.db.Exec(ctx, setChatInactive, arg.ChatID, arg.Reason)
+	return err
+}
+
+const setChecklistItemDone = `-- name: SetChecklistItemDone :execrows
+UPDATE checklist_items SET done = $3 WHERE id = $1 AND task_id = $2
+`
+
+type SetChecklistItemDoneParams struct {
+	ID     int64 `json:"id"`
+	TaskID int64 `json:"task_id"`
+	Done   bool  `json:"done"`
+}
+
+func (q *Queries) SetChecklistItemDone(ctx context.Context, arg *SetChecklistItemDoneParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setChecklistItemDone, arg.ID, arg.TaskID, arg.Done)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setManager = `-- name: SetManager :execrows
+UPDATE team_members SET manager_id = $3 WHERE team_id = $1 AND chat_id = $2
+`
+
+type SetManagerParams struct {
+	TeamID    int64       `json:"team_id"`
+	ChatID    int64       `json:"chat_id"`
+	ManagerID pgtype.Int8 `json:"manager_id"`
+}
+
+func (q *Queries) SetManager(ctx context.Context, arg *SetManagerParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setManager, arg.TeamID, arg.ChatID, arg.ManagerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setRecurrencePaused = `-- name: SetRecurrencePaused :execrows
+UPDATE recurrences SET paused = $3, next_run = $4 WHERE id = $1 AND team_id = $2
 `
 
-func (q *Queries) MarkTaskAsDone(ctx context.Context, id int64) (int64, error) {
-	result, err := q.db.Exec(ctx, markTaskAsDone, id)
+type SetRecurrencePausedParams struct {
+	ID      int64            `json:"id"`
+	TeamID  int64            `json:"team_id"`
+	Paused  bool             `json:"paused"`
+	NextRun pgtype.Timestamp `json:"next_run"`
+}
+
+func (q *Queries) SetRecurrencePaused(ctx context.Context, arg *SetRecurrencePausedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setRecurrencePaused,
+		arg.ID,
+		arg.TeamID,
+		arg.Paused,
+		arg.NextRun,
+	)
 	if err != nil {
 		return 0, err
 	}
@@ -453,16 +2815,18 @@ func (q *Queries) MarkTaskAsDone(ctx context.Context, id int64) (int64, error) {
 }
 
 const setRole = `-- name: SetRole :exec
-UPDATE chats SET role = $2 WHERE chat_id = $1
+INSERT INTO team_members (team_id, chat_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (team_id, chat_id) DO UPDATE SET role = EXCLUDED.role
 `
 
 type SetRoleParams struct {
-	ChatID int64       `json:"chat_id"`
-	Role   pgtype.Int4 `json:"role"`
+	TeamID int64 `json:"team_id"`
+	ChatID int64 `json:"chat_id"`
+	Role   int32 `json:"role"`
 }
 
 func (q *Queries) SetRole(ctx context.Context, arg *SetRoleParams) error {
-	_, err := q.db.Exec(ctx, setRole, arg.ChatID, arg.Role)
+	_, err := q.db.Exec(ctx, setRole, arg.TeamID, arg.ChatID, arg.Role)
 	return err
 }
 
@@ -480,6 +2844,22 @@ func (q *Queries) SetStage(ctx context.Context, arg *SetStageParams) error {
 	return err
 }
 
+const setTaskCard = `-- name: SetTaskCard :exec
+INSERT INTO task_cards (task_id, chat_id, message_id) VALUES ($1, $2, $3)
+ON CONFLICT (task_id, chat_id) DO UPDATE SET message_id = EXCLUDED.message_id, updated_at = CURRENT_TIMESTAMP
+`
+
+type SetTaskCardParams struct {
+	TaskID    int64 `json:"task_id"`
+	ChatID    int64 `json:"chat_id"`
+	MessageID int64 `json:"message_id"`
+}
+
+func (q *Queries) SetTaskCard(ctx context.Context, arg *SetTaskCardParams) error {
+	_, err := q.db.Exec(ctx, setTaskCard, arg.TaskID, arg.ChatID, arg.MessageID)
+	return err
+}
+
 const setTaskInProgressDeadline = `-- name: SetTaskInProgressDeadline :exec
 INSERT INTO tasks_in_progress (chat_id, deadline) VALUES ($1, $2) 
 ON CONFLICT (chat_id) DO UPDATE SET deadline = EXCLUDED.deadline
@@ -510,6 +2890,21 @@ func (q *Queries) SetTaskInProgressName(ctx context.Context, arg *SetTaskInProgr
 	return err
 }
 
+const setTaskInProgressPriority = `-- name: SetTaskInProgressPriority :exec
+INSERT INTO tasks_in_progress (chat_id, priority) VALUES ($1, $2)
+ON CONFLICT (chat_id) DO UPDATE SET priority = EXCLUDED.priority
+`
+
+type SetTaskInProgressPriorityParams struct {
+	ChatID   int64 `json:"chat_id"`
+	Priority int32 `json:"priority"`
+}
+
+func (q *Queries) SetTaskInProgressPriority(ctx context.Context, arg *SetTaskInProgressPriorityParams) error {
+	_, err := q.db.Exec(ctx, setTaskInProgressPriority, arg.ChatID, arg.Priority)
+	return err
+}
+
 const setTaskInProgressUser = `-- name: SetTaskInProgressUser :exec
 INSERT INTO tasks_in_progress (chat_id, executor_contact, executor_chat_id) VALUES ($1, $2, $3) 
 ON CONFLICT (chat_id) DO UPDATE SET executor_contact = EXCLUDED.executor_contact, executor_chat_id = EXCLUDED.executor_chat_id
@@ -525,3 +2920,67 @@ func (q *Queries) SetTaskInProgressUser(ctx context.Context, arg *SetTaskInProgr
 	_, err := q.db.Exec(ctx, setTaskInProgressUser, arg.ChatID, arg.ExecutorContact, arg.ExecutorChatID)
 	return err
 }
+
+const setTaskParent = `-- name: SetTaskParent :execrows
+UPDATE tasks SET parent_id = $3 WHERE id = $1 AND team_id = $2
+`
+
+type SetTaskParentParams struct {
+	ID       int64       `json:"id"`
+	TeamID   int64       `json:"team_id"`
+	ParentID pgtype.Int8 `json:"parent_id"`
+}
+
+func (q *Queries) SetTaskParent(ctx context.Context, arg *SetTaskParentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setTaskParent, arg.ID, arg.TeamID, arg.ParentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setTaskProofRequired = `-- name: SetTaskProofRequired :execrows
+UPDATE tasks SET proof_required = $3 WHERE id = $1 AND team_id = $2
+`
+
+type SetTaskProofRequiredParams struct {
+	ID            int64 `json:"id"`
+	TeamID        int64 `json:"team_id"`
+	ProofRequired bool  `json:"proof_required"`
+}
+
+func (q *Queries) SetTaskProofRequired(ctx context.Context, arg *SetTaskProofRequiredParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setTaskProofRequired, arg.ID, arg.TeamID, arg.ProofRequired)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setTaskReminder = `-- name: SetTaskReminder :exec
+UPDATE tasks SET next_reminder_at = $2 WHERE id = $1
+`
+
+type SetTaskReminderParams struct {
+	ID             int64            `json:"id"`
+	NextReminderAt pgtype.Timestamp `json:"next_reminder_at"`
+}
+
+func (q *Queries) SetTaskReminder(ctx context.Context, arg *SetTaskReminderParams) error {
+	_, err := q.db.Exec(ctx, setTaskReminder, arg.ID, arg.NextReminderAt)
+	return err
+}
+
+const touchChat = `-- name: TouchChat :exec
+UPDATE chats SET last_activity_at = $2 WHERE chat_id = $1
+`
+
+type TouchChatParams struct {
+	ChatID         int64            `json:"chat_id"`
+	LastActivityAt pgtype.Timestamp `json:"last_activity_at"`
+}
+
+func (q *Queries) TouchChat(ctx context.Context, arg *TouchChatParams) error {
+	_, err := q.db.Exec(ctx, touchChat, arg.ChatID, arg.LastActivityAt)
+	return err
+}
@@ -21,41 +21,188 @@ func NewStorage(ctx context.Context, cfg *config.Config) (Storage, error) {
 	return postgres, nil
 }
 
+// Storage keeps bot data. Roles, tasks, invites and admin actions belong to a team
+// and are always queried within the team, chats and their stages are global.
 type Storage interface {
 	DebugStorage(ctx context.Context) (string, error)
 
 	// chats
-	AddChat(ctx context.Context, chatID int64, username, phone string, role domain.Role) error
+	AddChat(ctx context.Context, chatID int64, username, phone string) error
 	GetChat(ctx context.Context, username, phone string) (*domain.Chat, error)
+	GetChatByID(ctx context.Context, chatID int64) (*domain.Chat, error)
+	GetChatsStats(ctx context.Context, teamID int64) ([]domain.ChatStats, error)
+	SetBanned(ctx context.Context, teamID, chatID int64, banned bool) error
+	TouchChat(ctx context.Context, chatID int64, at time.Time) error
+
+	// teams
+	AddTeam(ctx context.Context, name string) (domain.Team, error)
+	GetTeamByName(ctx context.Context, name string) (domain.Team, error)
+	GetChatTeams(ctx context.Context, chatID int64) ([]domain.TeamMembership, error)
+	SetActiveTeam(ctx context.Context, chatID, teamID int64) error
 
 	// role
-	GetRole(ctx context.Context, chatID int64) (domain.Role, error)
-	SetRole(ctx context.Context, chatID int64, role domain.Role) error
+	GetRole(ctx context.Context, teamID, chatID int64) (domain.Role, error)
+	SetRole(ctx context.Context, teamID, chatID int64, role domain.Role) error
+
+	// hierarchy, managerID is the chat id of the chief
+	SetManager(ctx context.Context, teamID, chatID, managerID int64) error
+	GetSubordinates(ctx context.Context, teamID, managerID int64) ([]domain.Chat, error)
+	GetSubordinatesTasks(ctx context.Context, teamID, managerID int64) ([]domain.Task, error)
+	GetExecutorManager(ctx context.Context, teamID, executorChatID int64, executorContact string) (int64, error)
 
 	// stage
 	SetStage(ctx context.Context, chatID int64, stage domain.Stage) error
 	GetStage(ctx context.Context, chatID int64) (domain.Stage, error)
 
-	GetObservers(ctx context.Context) (map[int64]*domain.Chat, error)
+	GetObservers(ctx context.Context, teamID int64) (map[int64]*domain.Chat, error)
 
 	// tasks
 	AddTask(ctx context.Context, task domain.Task) (int, error)
-	GetAllTasks(ctx context.Context) ([]domain.Task, error)
-	GetClosedTasks(ctx context.Context) ([]domain.Task, error)
-	GetOpenTasks(ctx context.Context) ([]domain.Task, error)
-	GetDoneTasks(ctx context.Context) ([]domain.Task, error)
-	GetExpiredTasks(ctx context.Context) ([]domain.Task, error)
+	GetTask(ctx context.Context, teamID int64, taskID int) (domain.Task, error)
+	GetAllTasks(ctx context.Context, teamID int64) ([]domain.Task, error)
+	GetClosedTasks(ctx context.Context, teamID int64) ([]domain.Task, error)
+	GetOpenTasks(ctx context.Context, teamID int64) ([]domain.Task, error)
+	GetDoneTasks(ctx context.Context, teamID int64) ([]domain.Task, error)
+	GetExpiredTasks(ctx context.Context, teamID int64) ([]domain.Task, error)
 	GetExpiredTasksToMark(ctx context.Context) ([]domain.Task, error)
-	GetUserTasks(ctx context.Context, username, phone string) ([]domain.Task, error)
-	MarkTaskAsDone(ctx context.Context, taskID int) error
-	MarkTaskAsClosed(ctx context.Context, taskID int) error
-	DeleteTask(ctx context.Context, taskID int) error
-	ChangeTaskDeadline(ctx context.Context, taskID int, newDeadline time.Time) error
+	GetUserTasks(ctx context.Context, teamID int64, username, phone string) ([]domain.Task, error)
+	GetParticipantTasks(ctx context.Context, teamID, chatID int64, role domain.ParticipantRole) ([]domain.Task, error)
+	MarkTaskAsDone(ctx context.Context, teamID int64, taskID int) error
+	MarkTaskAsClosed(ctx context.Context, teamID int64, taskID int) error
+	DeleteTask(ctx context.Context, teamID int64, taskID int) error
+	ChangeTaskDeadline(ctx context.Context, teamID int64, taskID int, newDeadline time.Time) error
+	SetTaskProofRequired(ctx context.Context, teamID int64, taskID int, required bool) error
+	// LinkTasksByPhone assigns the chat to tasks of its teams whose executor contact is the phone
+	LinkTasksByPhone(ctx context.Context, phone string, chatID int64) (int, error)
+
+	// reminders, GetTasksToRemind returns unfinished tasks before their deadline whose reminder has come
+	// or was not scheduled yet, deadline change drops scheduled reminder
+	GetTasksToRemind(ctx context.Context, now time.Time) ([]domain.TaskReminder, error)
+	SetTaskReminder(ctx context.Context, taskID int, at time.Time) error
+	// GetOverdueTasks returns expired and unfinished tasks of the priority in all teams
+	GetOverdueTasks(ctx context.Context, priority domain.Priority) ([]domain.Task, error)
+
+	// task participants, MarkParticipantDone returns number of executors who have not finished the task yet
+	AddTaskParticipant(ctx context.Context, participant domain.TaskParticipant) error
+	RemoveTaskParticipant(ctx context.Context, taskID int, chatID int64) error
+	GetTaskParticipants(ctx context.Context, taskID int) ([]domain.TaskParticipant, error)
+	MarkParticipantDone(ctx context.Context, taskID int, chatID int64) (int, error)
+
+	// subtasks and checklists, parentID 0 detaches subtask from its parent
+	SetTaskParent(ctx context.Context, teamID int64, taskID, parentID int) error
+	GetSubtasks(ctx context.Context, teamID int64, parentID int) ([]domain.Task, error)
+	GetTasksProgress(ctx context.Context, taskIDs []int) (map[int]domain.TaskProgress, error)
+	AddChecklistItem(ctx context.Context, item domain.ChecklistItem) (int, error)
+	GetChecklist(ctx context.Context, taskID int) ([]domain.ChecklistItem, error)
+	SetChecklistItemDone(ctx context.Context, taskID, itemID int, done bool) error
+
+	// dependencies, GetBlockers returns tasks each of taskIDs depends on
+	AddTaskDependency(ctx context.Context, taskID, blockerID int) error
+	RemoveTaskDependency(ctx context.Context, taskID, blockerID int) error
+	GetBlockers(ctx context.Context, taskIDs []int) (map[int][]domain.Task, error)
+	GetDependentTasks(ctx context.Context, blockerID int) ([]domain.Task, error)
+
+	// recurrences, AdvanceRecurrence moves the next run only from the expected one,
+	// so every run is claimed once and it returns false when the run was already claimed
+	AddRecurrence(ctx context.Context, recurrence domain.Recurrence) (int64, error)
+	GetRecurrence(ctx context.Context, teamID, recurrenceID int64) (domain.Recurrence, error)
+	GetRecurrences(ctx context.Context, teamID int64) ([]domain.Recurrence, error)
+	GetDueRecurrences(ctx context.Context, now time.Time) ([]domain.Recurrence, error)
+	AdvanceRecurrence(ctx context.Context, recurrenceID int64, from, to time.Time) (bool, error)
+	SetRecurrencePaused(ctx context.Context, teamID, recurrenceID int64, paused bool, nextRun time.Time) error
+	DeleteRecurrence(ctx context.Context, teamID, recurrenceID int64) error
+
+	// task templates are identified by their name within the team, SaveTemplate replaces the template with the same name
+	SaveTemplate(ctx context.Context, template domain.TaskTemplate) error
+	GetTemplate(ctx context.Context, teamID int64, name string) (domain.TaskTemplate, error)
+	GetTemplates(ctx context.Context, teamID int64) ([]domain.TaskTemplate, error)
+	DeleteTemplate(ctx context.Context, teamID int64, name string) error
+
+	// GetTasks is the generic query of the tasks matching the filter
+	GetTasks(ctx context.Context, filter domain.TaskFilter) ([]domain.Task, error)
+	// SearchTasks finds tasks matching the filter by words of their title and description, the most relevant first
+	SearchTasks(ctx context.Context, filter domain.TaskFilter, query string, limit, offset int) ([]domain.Task, error)
+
+	// tags are normalized names unique within the team, SetTaskTags replaces all tags of the task
+	SetTaskTags(ctx context.Context, teamID int64, taskID int, tags []string) error
+	GetTasksTags(ctx context.Context, taskIDs []int) (map[int][]string, error)
+
+	// saved views are personal filters of the chat within the team, SaveView replaces the view with the same name
+	SaveView(ctx context.Context, view domain.SavedView) error
+	GetView(ctx context.Context, teamID, chatID int64, name string) (domain.SavedView, error)
+	GetViews(ctx context.Context, teamID, chatID int64) ([]domain.SavedView, error)
+	DeleteView(ctx context.Context, teamID, chatID int64, name string) error
+
+	// comments are returned in the order they were written
+	AddComment(ctx context.Context, comment domain.Comment) (int, error)
+	GetComments(ctx context.Context, taskID int) ([]domain.Comment, error)
+	GetCommentsCount(ctx context.Context, taskID int) (int, error)
+
+	// task messages are outgoing bot messages about the task, replies to them are matched back to the task
+	AddTaskMessage(ctx context.Context, chatID int64, messageID, taskID int) error
+	// GetMessageTask returns the team and the number of the task the message is about
+	GetMessageTask(ctx context.Context, chatID int64, messageID int) (int64, int, error)
+
+	// task cards are the last messages with the task card in each chat, they are edited when the task changes
+	SetTaskCard(ctx context.Context, taskID int, chatID int64, messageID int) error
+	GetTaskCards(ctx context.Context, taskID int) (map[int64]int, error)
+	DeleteTaskCard(ctx context.Context, taskID int, chatID int64) error
+
+	// dashboards are pinned messages with the summary of team tasks, one per chat
+	SaveDashboard(ctx context.Context, dashboard domain.Dashboard) error
+	GetDashboards(ctx context.Context) ([]domain.Dashboard, error)
+	DeleteDashboard(ctx context.Context, chatID int64) error
+
+	// inactive chats blocked the bot or were deleted, they are not notified until they /start the bot again
+	SetChatInactive(ctx context.Context, chatID int64, reason string) error
+	SetChatActive(ctx context.Context, chatID int64) error
+	GetInactiveChats(ctx context.Context) (map[int64]string, error)
+
+	// handled updates make handling of telegram updates idempotent, the last of them is the offset to resume polling from.
+	// ClaimUpdate returns false when the update is already claimed, the last update is never deleted.
+	// The update is claimed before it is handled, so delivery is at most once: the update claimed by the bot
+	// which stopped in the middle of the handler is not handled again. Handlers send messages and change tasks
+	// without a transaction, so repeating them after the crash could duplicate notifications and tasks
+	ClaimUpdate(ctx context.Context, updateID int) (bool, error)
+	GetLastUpdateID(ctx context.Context) (int, error)
+	DeleteHandledUpdates(ctx context.Context, before time.Time) error
+
+	// attachments without task are pending in the chat creating a task until BindAttachments gives them the task
+	AddAttachment(ctx context.Context, attachment domain.Attachment) (int, error)
+	BindAttachments(ctx context.Context, chatID int64, taskID int) error
+	DeletePendingAttachments(ctx context.Context, chatID int64) error
+	GetAttachments(ctx context.Context, taskID int) ([]domain.Attachment, error)
+
+	// proofs of completion are returned in the order they were sent
+	AddProof(ctx context.Context, proof domain.Proof) (int, error)
+	GetProofs(ctx context.Context, taskID int) ([]domain.Proof, error)
 
 	GetTaskInProgress(ctx context.Context, chatID int64) (domain.Task, error)
 	SetTaskInProgressName(ctx context.Context, chatID int64, name string) error
 	SetTaskInProgressUser(ctx context.Context, chatID int64, userContact string, userChatID int64) error
 	SetTaskInProgressDeadline(ctx context.Context, chatID int64, deadline time.Time) error
+	SetTaskInProgressPriority(ctx context.Context, chatID int64, priority domain.Priority) error
+
+	// invites
+	AddInvite(ctx context.Context, invite domain.Invite) error
+	GetActiveInvites(ctx context.Context, teamID int64) ([]domain.Invite, error)
+	RedeemInvite(ctx context.Context, code string, chatID int64, username string) (domain.Invite, error)
+	RevokeInvite(ctx context.Context, teamID int64, code string) error
+
+	// password attempts
+	GetPasswordAttempts(ctx context.Context, chatID int64) (domain.PasswordAttempts, error)
+	// RegisterPasswordFailure counts the attempt as failed before the password is checked, unless the chat is locked at now,
+	// and returns attempts after it. The counter is incremented by storage, so concurrent attempts get different counts
+	RegisterPasswordFailure(ctx context.Context, chatID int64, now time.Time) (domain.PasswordAttempts, error)
+	// LockPasswordAttempts locks the chat until the time and resets its failures. Returns false when the chat
+	// has already been locked by a concurrent attempt, i.e. its lockouts differ from the given ones
+	LockPasswordAttempts(ctx context.Context, chatID int64, lockouts int, until time.Time) (bool, error)
+	GetFailedPasswordAttempts(ctx context.Context) ([]domain.PasswordAttempts, error)
+	ResetPasswordAttempts(ctx context.Context, chatID int64) error
+
+	// admin actions
+	AddAdminAction(ctx context.Context, action domain.AdminAction) error
+	GetAdminActions(ctx context.Context, teamID int64, limit int) ([]domain.AdminAction, error)
 
 	// messages
 	AddMessage(ctx context.Context, message domain.Message) error
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"tasks_bot/internal/domain"
 	"tasks_bot/internal/errs"
 	"time"
@@ -65,13 +67,368 @@ CREATE TABLE IF NOT EXISTS tasks_in_progress (
 	executor_chat_id INTEGER,
 	deadline TIMESTAMP,
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema for invites table
+CREATE TABLE IF NOT EXISTS invites (
+	code TEXT PRIMARY KEY,
+	role INTEGER NOT NULL,
+	username TEXT,
+	created_by INTEGER NOT NULL,
+	redeemed_by INTEGER,
+	redeemed_at TIMESTAMP,
+	expires_at TIMESTAMP,
+	revoked BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema for admin_actions table
+CREATE TABLE IF NOT EXISTS admin_actions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	admin_chat_id INTEGER NOT NULL,
+	target_chat_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	details TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema for teams table
+CREATE TABLE IF NOT EXISTS teams (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+INSERT OR IGNORE INTO teams (id, name) VALUES (1, 'default');
+
+-- Schema for team_members table
+CREATE TABLE IF NOT EXISTS team_members (
+	team_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	role INTEGER NOT NULL DEFAULT 0,
+	banned BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (team_id, chat_id)
+);
+
+-- Schema for task_participants table
+CREATE TABLE IF NOT EXISTS task_participants (
+	task_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	role INTEGER NOT NULL,
+	done BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (task_id, chat_id)
+);
+
+-- Schema for checklist_items table
+CREATE TABLE IF NOT EXISTS checklist_items (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	done BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema for task_dependencies table
+CREATE TABLE IF NOT EXISTS task_dependencies (
+	task_id INTEGER NOT NULL,
+	blocker_id INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (task_id, blocker_id)
+);
+
+-- Schema for recurrences table
+CREATE TABLE IF NOT EXISTS recurrences (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	team_id INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	executor_contact TEXT NOT NULL,
+	executor_chat_id INTEGER,
+	schedule TEXT NOT NULL,
+	deadline_in INTEGER NOT NULL,
+	next_run TIMESTAMP NOT NULL,
+	paused BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema for task_templates table, checklist is JSON array of items
+CREATE TABLE IF NOT EXISTS task_templates (
+	team_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	title_pattern TEXT NOT NULL,
+	executor_contact TEXT NOT NULL DEFAULT '',
+	deadline_in INTEGER NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	checklist TEXT NOT NULL DEFAULT '[]',
+	created_by INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (team_id, name)
+);
+
+-- Schema for tags table, tags are unique within the team
+CREATE TABLE IF NOT EXISTS tags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	team_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (team_id, name)
+);
+
+-- Schema for task_tags table
+CREATE TABLE IF NOT EXISTS task_tags (
+	task_id INTEGER NOT NULL,
+	tag_id INTEGER NOT NULL,
+	PRIMARY KEY (task_id, tag_id)
+);
+
+-- Schema for saved_views table
+CREATE TABLE IF NOT EXISTS saved_views (
+	team_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	query TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (team_id, chat_id, name)
+);
+
+-- Schema for task_comments table
+CREATE TABLE IF NOT EXISTS task_comments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	author TEXT NOT NULL DEFAULT '',
+	text TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_comments_task_id_idx ON task_comments (task_id);
+
+-- Schema for task_messages table, outgoing bot messages about tasks
+CREATE TABLE IF NOT EXISTS task_messages (
+	chat_id INTEGER NOT NULL,
+	message_id INTEGER NOT NULL,
+	task_id INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (chat_id, message_id)
+);
+
+-- Schema for task_cards table, the last message with the task card in each chat
+CREATE TABLE IF NOT EXISTS task_cards (
+	task_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	message_id INTEGER NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (task_id, chat_id)
+);
+
+-- Schema for dashboards table, pinned messages with the summary of team tasks, one per chat
+CREATE TABLE IF NOT EXISTS dashboards (
+	chat_id INTEGER PRIMARY KEY,
+	team_id INTEGER NOT NULL,
+	message_id INTEGER NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema for inactive_chats table, chats which blocked the bot or were deleted are not notified until /start
+CREATE TABLE IF NOT EXISTS inactive_chats (
+	chat_id INTEGER PRIMARY KEY,
+	reason TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Schema for handled_updates table, claimed telegram updates are not handled again, the last one is the polling offset
+CREATE TABLE IF NOT EXISTS handled_updates (
+	update_id INTEGER PRIMARY KEY,
+	handled_at TIMESTAMP NOT NULL
+);
+
+-- Schema for task_attachments table, task_id is NULL while the task is being created
+CREATE TABLE IF NOT EXISTS task_attachments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER,
+	chat_id INTEGER NOT NULL,
+	kind INTEGER NOT NULL,
+	file_id TEXT NOT NULL,
+	file_unique_id TEXT NOT NULL DEFAULT '',
+	file_name TEXT NOT NULL DEFAULT '',
+	mime_type TEXT NOT NULL DEFAULT '',
+	file_size INTEGER NOT NULL DEFAULT 0,
+	local_path TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_attachments_task_id_idx ON task_attachments (task_id);
+
+-- Schema for task_proofs table, proofs of completion kept for audit
+CREATE TABLE IF NOT EXISTS task_proofs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	author TEXT NOT NULL DEFAULT '',
+	kind INTEGER NOT NULL,
+	file_id TEXT NOT NULL DEFAULT '',
+	text TEXT NOT NULL DEFAULT '',
+	latitude REAL NOT NULL DEFAULT 0,
+	longitude REAL NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_proofs_task_id_idx ON task_proofs (task_id);
+
+-- Schema for password_attempts table
+CREATE TABLE IF NOT EXISTS password_attempts (
+	chat_id INTEGER PRIMARY KEY,
+	failures INTEGER NOT NULL DEFAULT 0,
+	lockouts INTEGER NOT NULL DEFAULT 0,
+	-- unix seconds, so the lock is compared as a number
+	locked_until INTEGER,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exec migration")
 	}
+	if err = addMissingColumns(ctx, db); err != nil {
+		return nil, fmt.Errorf("addMissingColumns: %w", err)
+	}
+	if err = moveChatBans(ctx, db); err != nil {
+		return nil, fmt.Errorf("moveChatBans: %w", err)
+	}
+	if err = createSearchIndex(ctx, db); err != nil {
+		return nil, fmt.Errorf("createSearchIndex: %w", err)
+	}
 	return db, nil
 }
 
+// sqliteSearchSchema is the full-text index of tasks with their comments kept in sync by triggers.
+// FTS5 folds case but not "ё", so task_search_documents index it as "е" like search terms are.
+const sqliteSearchSchema = `
+CREATE VIEW IF NOT EXISTS task_search_documents AS
+SELECT t.id,
+	replace(t.title, 'ё', 'е') AS title,
+	replace(COALESCE(t.description, ''), 'ё', 'е') AS description,
+	replace(COALESCE((SELECT group_concat(c.text, ' ') FROM task_comments c WHERE c.task_id = t.id), ''), 'ё', 'е') AS comments
+FROM tasks t;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, description, comments, tokenize = 'unicode61 remove_diacritics 2');
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+	INSERT INTO tasks_fts (rowid, title, description, comments) SELECT * FROM task_search_documents WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title, description ON tasks BEGIN
+	DELETE FROM tasks_fts WHERE rowid = old.id;
+	INSERT INTO tasks_fts (rowid, title, description, comments) SELECT * FROM task_search_documents WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+	DELETE FROM tasks_fts WHERE rowid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_comment AFTER INSERT ON task_comments BEGIN
+	DELETE FROM tasks_fts WHERE rowid = new.task_id;
+	INSERT INTO tasks_fts (rowid, title, description, comments) SELECT * FROM task_search_documents WHERE id = new.task_id;
+END;`
+
+// moveChatBans moves global bans of the schema before teams to the default team and drops the column of chats.
+func moveChatBans(ctx context.Context, db *sql.DB) error {
+	row := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info('chats') WHERE name = 'banned'`)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, `
+		UPDATE team_members SET banned = TRUE WHERE team_id = 1 AND chat_id IN (SELECT chat_id FROM chats WHERE banned);
+		ALTER TABLE chats DROP COLUMN banned;`)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+// createSearchIndex creates the full-text index after missing columns are added and indexes tasks created before it.
+// The index of the previous version has no comments, FTS5 tables can't be altered, so it is rebuilt.
+func createSearchIndex(ctx context.Context, db *sql.DB) error {
+	row := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info('tasks_fts') WHERE name = 'comments'`)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	if count == 0 {
+		_, err := db.ExecContext(ctx, `
+			DROP TRIGGER IF EXISTS tasks_fts_insert;
+			DROP TRIGGER IF EXISTS tasks_fts_update;
+			DROP TRIGGER IF EXISTS tasks_fts_delete;
+			DROP TABLE IF EXISTS tasks_fts;`)
+		if err != nil {
+			return fmt.Errorf("sqlite.Exec drop index: %w", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, sqliteSearchSchema); err != nil {
+		return fmt.Errorf("sqlite.Exec schema: %w", err)
+	}
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO tasks_fts (rowid, title, description, comments)
+		SELECT * FROM task_search_documents WHERE id NOT IN (SELECT rowid FROM tasks_fts)`)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec backfill: %w", err)
+	}
+	return nil
+}
+
+// sqliteColumns are columns added to already existing tables.
+// They are created on startup if database was created by previous version of the schema,
+// migration is executed once right after the column is added.
+var sqliteColumns = []struct {
+	table      string
+	column     string
+	definition string
+	migration  string
+}{
+	{table: "chats", column: "last_activity_at", definition: "TIMESTAMP"},
+	{
+		table: "chats", column: "team_id", definition: "INTEGER NOT NULL DEFAULT 1",
+		// roles were global before teams, all of them go to the default team
+		migration: `INSERT OR IGNORE INTO team_members (team_id, chat_id, role) SELECT 1, chat_id, COALESCE(role, 0) FROM chats`,
+	},
+	{table: "tasks", column: "team_id", definition: "INTEGER NOT NULL DEFAULT 1"},
+	{table: "invites", column: "team_id", definition: "INTEGER NOT NULL DEFAULT 1"},
+	{table: "admin_actions", column: "team_id", definition: "INTEGER NOT NULL DEFAULT 1"},
+	{table: "team_members", column: "manager_id", definition: "INTEGER"},
+	{table: "tasks", column: "parent_id", definition: "INTEGER"},
+	{table: "tasks", column: "description", definition: "TEXT"},
+	{table: "tasks", column: "priority", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "tasks", column: "next_reminder_at", definition: "TIMESTAMP"},
+	{table: "tasks_in_progress", column: "priority", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "task_templates", column: "priority", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "tasks", column: "created_by", definition: "INTEGER"},
+	{table: "tasks", column: "proof_required", definition: "BOOLEAN NOT NULL DEFAULT FALSE"},
+	{table: "task_templates", column: "proof_required", definition: "BOOLEAN NOT NULL DEFAULT FALSE"},
+}
+
+func addMissingColumns(ctx context.Context, db *sql.DB) error {
+	for _, c := range sqliteColumns {
+		row := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, c.table, c.column)
+		var count int
+		if err := row.Scan(&count); err != nil {
+			return fmt.Errorf("sqlite.QueryRow (%s.%s): %w", c.table, c.column, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("sqlite.Exec (%s.%s): %w", c.table, c.column, err)
+		}
+		if c.migration == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, c.migration); err != nil {
+			return fmt.Errorf("sqlite.Exec migration (%s.%s): %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
 func (s *SQLiteStorage) Close() {
 	s.db.Close()
 }
@@ -80,13 +437,13 @@ func (s *SQLiteStorage) DebugStorage(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("not implemented")
 }
 
-func (s *SQLiteStorage) AddChat(ctx context.Context, chatID int64, username, phone string, role domain.Role) error {
+func (s *SQLiteStorage) AddChat(ctx context.Context, chatID int64, username, phone string) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO chats (chat_id, username, phone, role) VALUES (?, ?, ?, ?)
+		INSERT INTO chats (chat_id, username, phone) VALUES (?, ?, ?)
 		ON CONFLICT(chat_id) DO UPDATE SET 
 			username = COALESCE(NULLIF(EXCLUDED.username, ''), chats.username), 
 			phone = COALESCE(NULLIF(EXCLUDED.phone, ''), chats.phone)`,
-		chatID, username, phone, int(role))
+		chatID, username, phone)
 	if err != nil {
 		return fmt.Errorf("sqlite.Exec: %w", err)
 	}
@@ -94,22 +451,105 @@ func (s *SQLiteStorage) AddChat(ctx context.Context, chatID int64, username, pho
 }
 
 func (s *SQLiteStorage) GetChat(ctx context.Context, username, phone string) (*domain.Chat, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT chat_id, username, phone, role, stage FROM chats WHERE username = ? OR phone = ?`, username, phone)
+	row := s.db.QueryRowContext(ctx, `
+		SELECT c.chat_id, c.team_id, c.username, c.phone, COALESCE(m.role, 0), c.stage
+		FROM chats c LEFT JOIN team_members m ON m.team_id = c.team_id AND m.chat_id = c.chat_id
+		WHERE c.username = ? OR c.phone = ?`, username, phone)
+	var chat domain.Chat
+	var role, stage int
+	if err := row.Scan(&chat.ID, &chat.TeamID, &chat.Username, &chat.Phone, &role, &stage); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	chat.Role = domain.Role(role)
+	chat.Stage = domain.Stage(stage)
+	return &chat, nil
+}
+
+func (s *SQLiteStorage) GetChatByID(ctx context.Context, chatID int64) (*domain.Chat, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT c.chat_id, c.team_id, c.username, c.phone, COALESCE(m.role, 0), c.stage, COALESCE(m.banned, FALSE), c.last_activity_at
+		FROM chats c LEFT JOIN team_members m ON m.team_id = c.team_id AND m.chat_id = c.chat_id
+		WHERE c.chat_id = ?`, chatID)
 	var chat domain.Chat
+	var username, phone sql.NullString
 	var role, stage int
-	if err := row.Scan(&chat.ID, &chat.Username, &chat.Phone, &role, &stage); err != nil {
+	var lastActivityAt sql.NullTime
+	if err := row.Scan(&chat.ID, &chat.TeamID, &username, &phone, &role, &stage, &chat.Banned, &lastActivityAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errs.ErrNotFound
 		}
 		return nil, fmt.Errorf("sqlite.QueryRow: %w", err)
 	}
+	chat.Username = username.String
+	chat.Phone = phone.String
 	chat.Role = domain.Role(role)
 	chat.Stage = domain.Stage(stage)
+	chat.LastActivityAt = lastActivityAt.Time
 	return &chat, nil
 }
 
-func (s *SQLiteStorage) GetRole(ctx context.Context, chatID int64) (domain.Role, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT role FROM chats WHERE chat_id = ?`, chatID)
+func (s *SQLiteStorage) GetChatsStats(ctx context.Context, teamID int64) ([]domain.ChatStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.chat_id, c.username, c.phone, m.role, c.stage, m.banned, c.last_activity_at, m.manager_id,
+			(SELECT COUNT(*) FROM tasks t WHERE t.team_id = m.team_id AND t.done = false AND t.closed = false
+				AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone)) AS open_tasks
+		FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+		WHERE m.team_id = ? ORDER BY c.last_activity_at DESC`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []domain.ChatStats
+	for rows.Next() {
+		var chat domain.ChatStats
+		var username, phone sql.NullString
+		var role, stage int
+		var lastActivityAt sql.NullTime
+		var managerID sql.NullInt64
+		if err := rows.Scan(&chat.ID, &username, &phone, &role, &stage, &chat.Banned, &lastActivityAt, &managerID, &chat.OpenTasks); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		chat.TeamID = teamID
+		chat.Username = username.String
+		chat.Phone = phone.String
+		chat.Role = domain.Role(role)
+		chat.Stage = domain.Stage(stage)
+		chat.LastActivityAt = lastActivityAt.Time
+		chat.ManagerID = managerID.Int64
+		stats = append(stats, chat)
+	}
+	return stats, nil
+}
+
+func (s *SQLiteStorage) SetBanned(ctx context.Context, teamID, chatID int64, banned bool) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE team_members SET banned = ? WHERE team_id = ? AND chat_id = ?`, banned, teamID, chatID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) TouchChat(ctx context.Context, chatID int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE chats SET last_activity_at = ? WHERE chat_id = ?`, at, chatID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetRole(ctx context.Context, teamID, chatID int64) (domain.Role, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT role FROM team_members WHERE team_id = ? AND chat_id = ?`, teamID, chatID)
 	var role int
 	if err := row.Scan(&role); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -120,11 +560,156 @@ func (s *SQLiteStorage) GetRole(ctx context.Context, chatID int64) (domain.Role,
 	return domain.Role(role), nil
 }
 
-func (s *SQLiteStorage) SetRole(ctx context.Context, chatID int64, role domain.Role) error {
-	_, err := s.db.ExecContext(ctx, `UPDATE chats SET role = ? WHERE chat_id = ?`, int(role), chatID)
+func (s *SQLiteStorage) SetRole(ctx context.Context, teamID, chatID int64, role domain.Role) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO team_members (team_id, chat_id, role) VALUES (?, ?, ?)
+		ON CONFLICT(team_id, chat_id) DO UPDATE SET role = EXCLUDED.role`, teamID, chatID, int(role))
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SetManager(ctx context.Context, teamID, chatID, managerID int64) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE team_members SET manager_id = NULLIF(?, 0) WHERE team_id = ? AND chat_id = ?`, managerID, teamID, chatID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetSubordinates(ctx context.Context, teamID, managerID int64) ([]domain.Chat, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.chat_id, c.username, c.phone, m.role, c.stage
+		FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+		WHERE m.team_id = ? AND m.manager_id = ?`, teamID, managerID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var subordinates []domain.Chat
+	for rows.Next() {
+		var chat domain.Chat
+		var username, phone sql.NullString
+		var role, stage int
+		if err := rows.Scan(&chat.ID, &username, &phone, &role, &stage); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		chat.TeamID = teamID
+		chat.Username = username.String
+		chat.Phone = phone.String
+		chat.Role = domain.Role(role)
+		chat.Stage = domain.Stage(stage)
+		subordinates = append(subordinates, chat)
+	}
+	return subordinates, nil
+}
+
+func (s *SQLiteStorage) GetSubordinatesTasks(ctx context.Context, teamID, managerID int64) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.team_id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, COALESCE(t.parent_id, 0), COALESCE(t.description, ''), t.priority, COALESCE(t.created_by, 0), t.proof_required
+		FROM tasks t WHERE t.team_id = ? AND EXISTS (
+			SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+			WHERE m.team_id = t.team_id AND m.manager_id = ?
+				AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone))`,
+		teamID, managerID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *SQLiteStorage) GetExecutorManager(ctx context.Context, teamID, executorChatID int64, executorContact string) (int64, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT m.manager_id FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+		WHERE m.team_id = ? AND m.manager_id IS NOT NULL AND (c.chat_id = ? OR c.username = NULLIF(?, '') OR c.phone = NULLIF(?, ''))
+		LIMIT 1`, teamID, executorChatID, executorContact, executorContact)
+	var managerID int64
+	if err := row.Scan(&managerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.ErrNotFound
+		}
+		return 0, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	return managerID, nil
+}
+
+func (s *SQLiteStorage) AddTeam(ctx context.Context, name string) (domain.Team, error) {
+	result, err := s.db.ExecContext(ctx, `INSERT INTO teams (name) VALUES (?)`, name)
+	if err != nil {
+		return domain.Team{}, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	teamID, err := result.LastInsertId()
+	if err != nil {
+		return domain.Team{}, fmt.Errorf("sqlite.LastInsertId: %w", err)
+	}
+	return domain.Team{ID: teamID, Name: name}, nil
+}
+
+func (s *SQLiteStorage) GetTeamByName(ctx context.Context, name string) (domain.Team, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, name FROM teams WHERE name = ?`, name)
+	var team domain.Team
+	if err := row.Scan(&team.ID, &team.Name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Team{}, errs.ErrNotFound
+		}
+		return domain.Team{}, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	return team, nil
+}
+
+func (s *SQLiteStorage) GetChatTeams(ctx context.Context, chatID int64) ([]domain.TeamMembership, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.name, m.role FROM team_members m JOIN teams t ON t.id = m.team_id
+		WHERE m.chat_id = ? ORDER BY t.id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var memberships []domain.TeamMembership
+	for rows.Next() {
+		var membership domain.TeamMembership
+		var role int
+		if err := rows.Scan(&membership.ID, &membership.Name, &role); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		membership.Role = domain.Role(role)
+		memberships = append(memberships, membership)
+	}
+	return memberships, nil
+}
+
+func (s *SQLiteStorage) SetActiveTeam(ctx context.Context, chatID, teamID int64) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE chats SET team_id = ? WHERE chat_id = ?`, teamID, chatID)
 	if err != nil {
 		return fmt.Errorf("sqlite.Exec: %w", err)
 	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
 	return nil
 }
 
@@ -148,8 +733,11 @@ func (s *SQLiteStorage) GetStage(ctx context.Context, chatID int64) (domain.Stag
 	return domain.Stage(stage), nil
 }
 
-func (s *SQLiteStorage) GetObservers(ctx context.Context) (map[int64]*domain.Chat, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, username, phone, role, stage FROM chats WHERE role = 2`)
+func (s *SQLiteStorage) GetObservers(ctx context.Context, teamID int64) (map[int64]*domain.Chat, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.chat_id, c.username, c.phone, m.role, c.stage
+		FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+		WHERE m.team_id = ? AND m.role = 2`, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -162,6 +750,7 @@ func (s *SQLiteStorage) GetObservers(ctx context.Context) (map[int64]*domain.Cha
 		if err := rows.Scan(&chat.ID, &chat.Username, &chat.Phone, &role, &stage); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
+		chat.TeamID = teamID
 		chat.Role = domain.Role(role)
 		chat.Stage = domain.Stage(stage)
 		observers[chat.ID] = &chat
@@ -171,8 +760,8 @@ func (s *SQLiteStorage) GetObservers(ctx context.Context) (map[int64]*domain.Cha
 
 func (s *SQLiteStorage) AddTask(ctx context.Context, task domain.Task) (int, error) {
 	result, err := s.db.ExecContext(ctx, `
-		INSERT INTO tasks (title, executor_contact, executor_chat_id, deadline, done, closed, expired) 
-		VALUES (?, ?, ?, ?, false, false, false)`, task.Title, task.ExecutorContact, task.ExecutorChatID, task.Deadline)
+		INSERT INTO tasks (team_id, title, executor_contact, executor_chat_id, deadline, description, priority, created_by, proof_required, done, closed, expired) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, false, false, false)`, task.TeamID, task.Title, task.ExecutorContact, task.ExecutorChatID, task.Deadline, task.Description, task.Priority, task.CreatedBy, task.ProofRequired)
 	if err != nil {
 		return -1, fmt.Errorf("sqlite.Exec: %w", err)
 	}
@@ -183,8 +772,20 @@ func (s *SQLiteStorage) AddTask(ctx context.Context, task domain.Task) (int, err
 	return int(taskID), nil
 }
 
-func (s *SQLiteStorage) GetAllTasks(ctx context.Context) ([]domain.Task, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired FROM tasks`)
+func (s *SQLiteStorage) GetTask(ctx context.Context, teamID int64, taskID int) (domain.Task, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE id = ? AND team_id = ?`, taskID, teamID)
+	var task domain.Task
+	if err := row.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Task{}, errs.ErrNotFound
+		}
+		return domain.Task{}, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	return task, nil
+}
+
+func (s *SQLiteStorage) GetAllTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE team_id = ?`, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -193,7 +794,7 @@ func (s *SQLiteStorage) GetAllTasks(ctx context.Context) ([]domain.Task, error)
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired); err != nil {
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
 		tasks = append(tasks, task)
@@ -201,8 +802,8 @@ func (s *SQLiteStorage) GetAllTasks(ctx context.Context) ([]domain.Task, error)
 	return tasks, nil
 }
 
-func (s *SQLiteStorage) GetClosedTasks(ctx context.Context) ([]domain.Task, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired FROM tasks WHERE closed = true`)
+func (s *SQLiteStorage) GetClosedTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE team_id = ? AND closed = true`, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -211,7 +812,7 @@ func (s *SQLiteStorage) GetClosedTasks(ctx context.Context) ([]domain.Task, erro
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired); err != nil {
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
 		tasks = append(tasks, task)
@@ -219,8 +820,8 @@ func (s *SQLiteStorage) GetClosedTasks(ctx context.Context) ([]domain.Task, erro
 	return tasks, nil
 }
 
-func (s *SQLiteStorage) GetOpenTasks(ctx context.Context) ([]domain.Task, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired FROM tasks WHERE closed = false`)
+func (s *SQLiteStorage) GetOpenTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE team_id = ? AND closed = false`, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -229,7 +830,7 @@ func (s *SQLiteStorage) GetOpenTasks(ctx context.Context) ([]domain.Task, error)
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired); err != nil {
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
 		tasks = append(tasks, task)
@@ -237,8 +838,8 @@ func (s *SQLiteStorage) GetOpenTasks(ctx context.Context) ([]domain.Task, error)
 	return tasks, nil
 }
 
-func (s *SQLiteStorage) GetDoneTasks(ctx context.Context) ([]domain.Task, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired FROM tasks WHERE done = true`)
+func (s *SQLiteStorage) GetDoneTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE team_id = ? AND done = true`, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -247,7 +848,7 @@ func (s *SQLiteStorage) GetDoneTasks(ctx context.Context) ([]domain.Task, error)
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired); err != nil {
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
 		tasks = append(tasks, task)
@@ -255,8 +856,8 @@ func (s *SQLiteStorage) GetDoneTasks(ctx context.Context) ([]domain.Task, error)
 	return tasks, nil
 }
 
-func (s *SQLiteStorage) GetExpiredTasks(ctx context.Context) ([]domain.Task, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired FROM tasks WHERE expired = true`)
+func (s *SQLiteStorage) GetExpiredTasks(ctx context.Context, teamID int64) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE team_id = ? AND expired = true`, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -265,7 +866,7 @@ func (s *SQLiteStorage) GetExpiredTasks(ctx context.Context) ([]domain.Task, err
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired); err != nil {
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
 		tasks = append(tasks, task)
@@ -274,7 +875,7 @@ func (s *SQLiteStorage) GetExpiredTasks(ctx context.Context) ([]domain.Task, err
 }
 
 func (s *SQLiteStorage) GetExpiredTasksToMark(ctx context.Context) ([]domain.Task, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired FROM tasks WHERE done = false AND expired = false AND deadline < ?`, time.Now().UTC())
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE done = false AND expired = false AND deadline < ?`, time.Now().UTC())
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -283,7 +884,7 @@ func (s *SQLiteStorage) GetExpiredTasksToMark(ctx context.Context) ([]domain.Tas
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired); err != nil {
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
 		task.Expired = true
@@ -295,8 +896,8 @@ func (s *SQLiteStorage) GetExpiredTasksToMark(ctx context.Context) ([]domain.Tas
 	return tasks, nil
 }
 
-func (s *SQLiteStorage) GetUserTasks(ctx context.Context, username, phone string) ([]domain.Task, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, title, executor_contact, executor_chat_id, deadline, done, closed, expired FROM tasks WHERE executor_contact = ? OR executor_contact = ?`, username, phone)
+func (s *SQLiteStorage) GetUserTasks(ctx context.Context, teamID int64, username, phone string) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE team_id = ? AND (executor_contact = ? OR executor_contact = ?)`, teamID, username, phone)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
@@ -305,7 +906,7 @@ func (s *SQLiteStorage) GetUserTasks(ctx context.Context, username, phone string
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired); err != nil {
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
 			return nil, fmt.Errorf("sqlite.Scan: %w", err)
 		}
 		tasks = append(tasks, task)
@@ -313,8 +914,8 @@ func (s *SQLiteStorage) GetUserTasks(ctx context.Context, username, phone string
 	return tasks, nil
 }
 
-func (s *SQLiteStorage) MarkTaskAsDone(ctx context.Context, taskID int) error {
-	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET done = true WHERE id = ?`, taskID)
+func (s *SQLiteStorage) SetTaskParent(ctx context.Context, teamID int64, taskID, parentID int) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET parent_id = NULLIF(?, 0) WHERE id = ? AND team_id = ?`, parentID, taskID, teamID)
 	if err != nil {
 		return fmt.Errorf("sqlite.Exec: %w", err)
 	}
@@ -328,42 +929,954 @@ func (s *SQLiteStorage) MarkTaskAsDone(ctx context.Context, taskID int) error {
 	return nil
 }
 
-func (s *SQLiteStorage) MarkTaskAsClosed(ctx context.Context, taskID int) error {
-	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET closed = true WHERE id = ?`, taskID)
-	if err != nil {
-		return fmt.Errorf("sqlite.Exec: %w", err)
-	}
-	affectedRows, err := result.RowsAffected()
+func (s *SQLiteStorage) GetSubtasks(ctx context.Context, teamID int64, parentID int) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required FROM tasks WHERE team_id = ? AND parent_id = ?`, teamID, parentID)
 	if err != nil {
-		return fmt.Errorf("sqlite.RowsAffected: %w", err)
-	}
-	if affectedRows == 0 {
-		return errs.ErrNotFound
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
 	}
-	return nil
-}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *SQLiteStorage) GetTasksProgress(ctx context.Context, taskIDs []int) (map[int]domain.TaskProgress, error) {
+	ids, err := json.Marshal(taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	progress := make(map[int]domain.TaskProgress, len(taskIDs))
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT parent_id, COUNT(*), SUM(CASE WHEN done OR closed THEN 1 ELSE 0 END)
+		FROM tasks WHERE parent_id IN (SELECT value FROM json_each(?)) GROUP BY parent_id`, string(ids))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var taskID int
+		var taskProgress domain.TaskProgress
+		if err := rows.Scan(&taskID, &taskProgress.Subtasks, &taskProgress.SubtasksDone); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		progress[taskID] = taskProgress
+	}
+
+	itemRows, err := s.db.QueryContext(ctx, `
+		SELECT task_id, COUNT(*), SUM(CASE WHEN done THEN 1 ELSE 0 END)
+		FROM checklist_items WHERE task_id IN (SELECT value FROM json_each(?)) GROUP BY task_id`, string(ids))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer itemRows.Close()
+	for itemRows.Next() {
+		var taskID, items, itemsDone int
+		if err := itemRows.Scan(&taskID, &items, &itemsDone); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		taskProgress := progress[taskID]
+		taskProgress.Items, taskProgress.ItemsDone = items, itemsDone
+		progress[taskID] = taskProgress
+	}
+	return progress, nil
+}
+
+func (s *SQLiteStorage) AddChecklistItem(ctx context.Context, item domain.ChecklistItem) (int, error) {
+	result, err := s.db.ExecContext(ctx, `INSERT INTO checklist_items (task_id, title) VALUES (?, ?)`, item.TaskID, item.Title)
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	itemID, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.LastInsertId: %w", err)
+	}
+	return int(itemID), nil
+}
+
+func (s *SQLiteStorage) GetChecklist(ctx context.Context, taskID int) ([]domain.ChecklistItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, task_id, title, done FROM checklist_items WHERE task_id = ? ORDER BY id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var items []domain.ChecklistItem
+	for rows.Next() {
+		var item domain.ChecklistItem
+		if err := rows.Scan(&item.ID, &item.TaskID, &item.Title, &item.Done); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *SQLiteStorage) SetChecklistItemDone(ctx context.Context, taskID, itemID int, done bool) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE checklist_items SET done = ? WHERE id = ? AND task_id = ?`, done, itemID, taskID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) AddTaskDependency(ctx context.Context, taskID, blockerID int) error {
+	_, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO task_dependencies (task_id, blocker_id) VALUES (?, ?)`, taskID, blockerID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) RemoveTaskDependency(ctx context.Context, taskID, blockerID int) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = ? AND blocker_id = ?`, taskID, blockerID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetBlockers(ctx context.Context, taskIDs []int) (map[int][]domain.Task, error) {
+	ids, err := json.Marshal(taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT d.task_id, t.id, t.team_id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, COALESCE(t.parent_id, 0), COALESCE(t.description, ''), t.priority, COALESCE(t.created_by, 0), t.proof_required
+		FROM task_dependencies d JOIN tasks t ON t.id = d.blocker_id
+		WHERE d.task_id IN (SELECT value FROM json_each(?))`, string(ids))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	blockers := make(map[int][]domain.Task, len(taskIDs))
+	for rows.Next() {
+		var blockedID int
+		var task domain.Task
+		if err := rows.Scan(&blockedID, &task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		blockers[blockedID] = append(blockers[blockedID], task)
+	}
+	return blockers, nil
+}
+
+func (s *SQLiteStorage) GetDependentTasks(ctx context.Context, blockerID int) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.team_id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, COALESCE(t.parent_id, 0), COALESCE(t.description, ''), t.priority, COALESCE(t.created_by, 0), t.proof_required
+		FROM task_dependencies d JOIN tasks t ON t.id = d.task_id
+		WHERE d.blocker_id = ?`, blockerID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *SQLiteStorage) GetParticipantTasks(ctx context.Context, teamID, chatID int64, role domain.ParticipantRole) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.team_id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, COALESCE(t.parent_id, 0), COALESCE(t.description, ''), t.priority, COALESCE(t.created_by, 0), t.proof_required
+		FROM tasks t JOIN task_participants p ON p.task_id = t.id
+		WHERE t.team_id = ? AND p.chat_id = ? AND p.role = ?`, teamID, chatID, role)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *SQLiteStorage) AddTaskParticipant(ctx context.Context, participant domain.TaskParticipant) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_participants (task_id, chat_id, role) VALUES (?, ?, ?)
+		ON CONFLICT(task_id, chat_id) DO UPDATE SET role = EXCLUDED.role`,
+		participant.TaskID, participant.ChatID, participant.Role)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) RemoveTaskParticipant(ctx context.Context, taskID int, chatID int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM task_participants WHERE task_id = ? AND chat_id = ?`, taskID, chatID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetTaskParticipants(ctx context.Context, taskID int) ([]domain.TaskParticipant, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT task_id, chat_id, role, done FROM task_participants WHERE task_id = ? ORDER BY created_at`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var participants []domain.TaskParticipant
+	for rows.Next() {
+		var participant domain.TaskParticipant
+		if err := rows.Scan(&participant.TaskID, &participant.ChatID, &participant.Role, &participant.Done); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		participants = append(participants, participant)
+	}
+	return participants, nil
+}
+
+func (s *SQLiteStorage) MarkParticipantDone(ctx context.Context, taskID int, chatID int64) (int, error) {
+	result, err := s.db.ExecContext(ctx, `UPDATE task_participants SET done = true WHERE task_id = ? AND chat_id = ? AND role = ?`,
+		taskID, chatID, domain.ExecutorParticipant)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return 0, errs.ErrNotFound
+	}
+
+	row := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM task_participants WHERE task_id = ? AND role = ? AND done = false`,
+		taskID, domain.ExecutorParticipant)
+	var remaining int
+	if err := row.Scan(&remaining); err != nil {
+		return 0, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	return remaining, nil
+}
+
+func (s *SQLiteStorage) MarkTaskAsDone(ctx context.Context, teamID int64, taskID int) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET done = true WHERE id = ? AND team_id = ?`, taskID, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) MarkTaskAsClosed(ctx context.Context, teamID int64, taskID int) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET closed = true WHERE id = ? AND team_id = ?`, taskID, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteTask(ctx context.Context, teamID int64, taskID int) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = ? AND team_id = ?`, taskID, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_participants WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM checklist_items WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE tasks SET parent_id = NULL WHERE parent_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = ? OR blocker_id = ?`, taskID, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_comments WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_messages WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_cards WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_attachments WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_proofs WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ChangeTaskDeadline(ctx context.Context, teamID int64, taskID int, newDeadline time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE tasks SET deadline = ?, expired = false, next_reminder_at = NULL WHERE id = ? AND team_id = ?`, newDeadline, taskID, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SetTaskProofRequired(ctx context.Context, teamID int64, taskID int, required bool) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET proof_required = ? WHERE id = ? AND team_id = ?`, required, taskID, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) LinkTasksByPhone(ctx context.Context, phone string, chatID int64) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE tasks SET executor_chat_id = ?
+		WHERE executor_contact = ? AND COALESCE(executor_chat_id, 0) != ?
+			AND team_id IN (SELECT team_id FROM team_members WHERE chat_id = ?)`,
+		chatID, phone, chatID, chatID)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	return int(affectedRows), nil
+}
+
+func (s *SQLiteStorage) GetTasksToRemind(ctx context.Context, now time.Time) ([]domain.TaskReminder, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required, next_reminder_at
+		FROM tasks WHERE done = false AND closed = false AND expired = false AND deadline > ? AND (next_reminder_at IS NULL OR next_reminder_at <= ?)`, now.UTC(), now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []domain.TaskReminder
+	for rows.Next() {
+		var task domain.Task
+		var at sql.NullTime
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired, &at); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		reminders = append(reminders, domain.TaskReminder{Task: task, At: at.Time})
+	}
+	return reminders, nil
+}
+
+func (s *SQLiteStorage) SetTaskReminder(ctx context.Context, taskID int, at time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE tasks SET next_reminder_at = ? WHERE id = ?`, at.UTC(), taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetOverdueTasks(ctx context.Context, priority domain.Priority) ([]domain.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, team_id, title, executor_contact, executor_chat_id, deadline, done, closed, expired, COALESCE(parent_id, 0), COALESCE(description, ''), priority, COALESCE(created_by, 0), proof_required
+		FROM tasks WHERE expired = true AND done = false AND closed = false AND priority = ? ORDER BY team_id, deadline`, priority)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *SQLiteStorage) GetTasks(ctx context.Context, filter domain.TaskFilter) ([]domain.Task, error) {
+	conditions, args, err := taskFilterConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.team_id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, COALESCE(t.parent_id, 0), COALESCE(t.description, ''), t.priority, COALESCE(t.created_by, 0), t.proof_required
+		FROM tasks t WHERE `+strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *SQLiteStorage) SearchTasks(ctx context.Context, filter domain.TaskFilter, query string, limit, offset int) ([]domain.Task, error) {
+	terms := domain.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	// every stem is matched as a prefix of a word, FTS5 has no russian morphology
+	match := make([]string, 0, len(terms))
+	for _, term := range terms {
+		match = append(match, `"`+strings.ReplaceAll(term, `"`, `""`)+`"*`)
+	}
+	conditions, args, err := taskFilterConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	args = append([]any{strings.Join(match, " ")}, args...)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.team_id, t.title, t.executor_contact, t.executor_chat_id, t.deadline, t.done, t.closed, t.expired, COALESCE(t.parent_id, 0), COALESCE(t.description, ''), t.priority, COALESCE(t.created_by, 0), t.proof_required
+		FROM tasks_fts JOIN tasks t ON t.id = tasks_fts.rowid
+		WHERE tasks_fts MATCH ? AND `+strings.Join(conditions, " AND ")+`
+		ORDER BY bm25(tasks_fts, 2.0, 1.0, 0.5), t.id LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		var task domain.Task
+		if err := rows.Scan(&task.ID, &task.TeamID, &task.Title, &task.ExecutorContact, &task.ExecutorChatID, &task.Deadline, &task.Done, &task.Closed, &task.Expired, &task.ParentID, &task.Description, &task.Priority, &task.CreatedBy, &task.ProofRequired); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// taskFilterConditions returns WHERE conditions of the filter for tasks aliased as t, they are joined with AND.
+func taskFilterConditions(filter domain.TaskFilter) ([]string, []any, error) {
+	conditions := []string{"t.team_id = ?"}
+	args := []any{filter.TeamID}
+	if filter.ManagerID != 0 {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM team_members m JOIN chats c ON c.chat_id = m.chat_id
+			WHERE m.team_id = t.team_id AND m.manager_id = ?
+				AND (t.executor_chat_id = c.chat_id OR t.executor_contact = c.username OR t.executor_contact = c.phone))`)
+		args = append(args, filter.ManagerID)
+	}
+	if filter.Executor != nil {
+		conditions = append(conditions, `(t.executor_chat_id = NULLIF(?, 0) OR t.executor_contact IN (NULLIF(?, ''), NULLIF(?, ''))
+			OR EXISTS (SELECT 1 FROM task_participants p WHERE p.task_id = t.id AND p.chat_id = ? AND p.role = ?))`)
+		args = append(args, filter.Executor.ID, filter.Executor.Username, filter.Executor.Phone, filter.Executor.ID, domain.ExecutorParticipant)
+	}
+	if len(filter.Tags) > 0 {
+		tags, err := json.Marshal(filter.Tags)
+		if err != nil {
+			return nil, nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		conditions = append(conditions, `(
+			SELECT COUNT(*) FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+			WHERE tt.task_id = t.id AND g.name IN (SELECT value FROM json_each(?))) = ?`)
+		args = append(args, string(tags), len(filter.Tags))
+	}
+	if len(filter.Priorities) > 0 {
+		priorities, err := json.Marshal(filter.Priorities)
+		if err != nil {
+			return nil, nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		conditions = append(conditions, `t.priority IN (SELECT value FROM json_each(?))`)
+		args = append(args, string(priorities))
+	}
+	if filter.Open {
+		conditions = append(conditions, `t.done = false AND t.closed = false`)
+	}
+	if filter.Done {
+		conditions = append(conditions, `t.done = true`)
+	}
+	if filter.Closed {
+		conditions = append(conditions, `t.closed = true`)
+	}
+	if filter.Overdue {
+		conditions = append(conditions, `t.done = false AND t.closed = false AND (t.expired = true OR t.deadline < ?)`)
+		args = append(args, time.Now().UTC())
+	}
+	return conditions, args, nil
+}
+
+func (s *SQLiteStorage) SetTaskTags(ctx context.Context, teamID int64, taskID int, tags []string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	for _, tag := range tags {
+		if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO tags (team_id, name) VALUES (?, ?)`, teamID, tag); err != nil {
+			return fmt.Errorf("sqlite.Exec: %w", err)
+		}
+		_, err := s.db.ExecContext(ctx, `
+			INSERT OR IGNORE INTO task_tags (task_id, tag_id) SELECT ?, id FROM tags WHERE team_id = ? AND name = ?`, taskID, teamID, tag)
+		if err != nil {
+			return fmt.Errorf("sqlite.Exec: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetTasksTags(ctx context.Context, taskIDs []int) (map[int][]string, error) {
+	ids, err := json.Marshal(taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tt.task_id, g.name FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+		WHERE tt.task_id IN (SELECT value FROM json_each(?)) ORDER BY g.name`, string(ids))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string, len(taskIDs))
+	for rows.Next() {
+		var taskID int
+		var tag string
+		if err := rows.Scan(&taskID, &tag); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		tags[taskID] = append(tags[taskID], tag)
+	}
+	return tags, nil
+}
+
+func (s *SQLiteStorage) SaveView(ctx context.Context, view domain.SavedView) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO saved_views (team_id, chat_id, name, query) VALUES (?, ?, ?, ?)
+		ON CONFLICT(team_id, chat_id, name) DO UPDATE SET query = EXCLUDED.query`, view.TeamID, view.ChatID, view.Name, view.Query)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetView(ctx context.Context, teamID, chatID int64, name string) (domain.SavedView, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT team_id, chat_id, name, query FROM saved_views WHERE team_id = ? AND chat_id = ? AND name = ?`, teamID, chatID, name)
+	var view domain.SavedView
+	if err := row.Scan(&view.TeamID, &view.ChatID, &view.Name, &view.Query); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.SavedView{}, errs.ErrNotFound
+		}
+		return domain.SavedView{}, fmt.Errorf("sqlite.Scan: %w", err)
+	}
+	return view, nil
+}
+
+func (s *SQLiteStorage) GetViews(ctx context.Context, teamID, chatID int64) ([]domain.SavedView, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT team_id, chat_id, name, query FROM saved_views WHERE team_id = ? AND chat_id = ? ORDER BY name`, teamID, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var views []domain.SavedView
+	for rows.Next() {
+		var view domain.SavedView
+		if err := rows.Scan(&view.TeamID, &view.ChatID, &view.Name, &view.Query); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+func (s *SQLiteStorage) DeleteView(ctx context.Context, teamID, chatID int64, name string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM saved_views WHERE team_id = ? AND chat_id = ? AND name = ?`, teamID, chatID, name)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) AddComment(ctx context.Context, comment domain.Comment) (int, error) {
+	result, err := s.db.ExecContext(ctx, `INSERT INTO task_comments (task_id, chat_id, author, text) VALUES (?, ?, ?, ?)`,
+		comment.TaskID, comment.ChatID, comment.Author, comment.Text)
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	commentID, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.LastInsertId: %w", err)
+	}
+	return int(commentID), nil
+}
+
+func (s *SQLiteStorage) GetComments(ctx context.Context, taskID int) ([]domain.Comment, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, task_id, chat_id, author, text, created_at FROM task_comments WHERE task_id = ? ORDER BY id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []domain.Comment
+	for rows.Next() {
+		var comment domain.Comment
+		if err := rows.Scan(&comment.ID, &comment.TaskID, &comment.ChatID, &comment.Author, &comment.Text, &comment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+func (s *SQLiteStorage) GetCommentsCount(ctx context.Context, taskID int) (int, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM task_comments WHERE task_id = ?`, taskID)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("sqlite.Scan: %w", err)
+	}
+	return count, nil
+}
+
+func (s *SQLiteStorage) AddTaskMessage(ctx context.Context, chatID int64, messageID, taskID int) error {
+	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO task_messages (chat_id, message_id, task_id) VALUES (?, ?, ?)`, chatID, messageID, taskID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetMessageTask(ctx context.Context, chatID int64, messageID int) (int64, int, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT t.team_id, m.task_id FROM task_messages m JOIN tasks t ON t.id = m.task_id
+		WHERE m.chat_id = ? AND m.message_id = ?`, chatID, messageID)
+	var teamID int64
+	var taskID int
+	if err := row.Scan(&teamID, &taskID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, errs.ErrNotFound
+		}
+		return 0, 0, fmt.Errorf("sqlite.Scan: %w", err)
+	}
+	return teamID, taskID, nil
+}
+
+func (s *SQLiteStorage) SetTaskCard(ctx context.Context, taskID int, chatID int64, messageID int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_cards (task_id, chat_id, message_id) VALUES (?, ?, ?)
+		ON CONFLICT (task_id, chat_id) DO UPDATE SET message_id = excluded.message_id, updated_at = CURRENT_TIMESTAMP`,
+		taskID, chatID, messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetTaskCards(ctx context.Context, taskID int) (map[int64]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, message_id FROM task_cards WHERE task_id = ?`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	cards := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var messageID int
+		if err := rows.Scan(&chatID, &messageID); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		cards[chatID] = messageID
+	}
+	return cards, nil
+}
+
+func (s *SQLiteStorage) DeleteTaskCard(ctx context.Context, taskID int, chatID int64) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_cards WHERE task_id = ? AND chat_id = ?`, taskID, chatID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveDashboard(ctx context.Context, dashboard domain.Dashboard) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO dashboards (chat_id, team_id, message_id) VALUES (?, ?, ?)
+		ON CONFLICT (chat_id) DO UPDATE SET team_id = excluded.team_id, message_id = excluded.message_id`,
+		dashboard.ChatID, dashboard.TeamID, dashboard.MessageID,
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetDashboards(ctx context.Context) ([]domain.Dashboard, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, team_id, message_id FROM dashboards ORDER BY chat_id`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var dashboards []domain.Dashboard
+	for rows.Next() {
+		var dashboard domain.Dashboard
+		if err := rows.Scan(&dashboard.ChatID, &dashboard.TeamID, &dashboard.MessageID); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		dashboards = append(dashboards, dashboard)
+	}
+	return dashboards, nil
+}
+
+func (s *SQLiteStorage) DeleteDashboard(ctx context.Context, chatID int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM dashboards WHERE chat_id = ?`, chatID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SetChatInactive(ctx context.Context, chatID int64, reason string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO inactive_chats (chat_id, reason) VALUES (?, ?)
+		ON CONFLICT (chat_id) DO UPDATE SET reason = excluded.reason`,
+		chatID, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SetChatActive(ctx context.Context, chatID int64) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM inactive_chats WHERE chat_id = ?`, chatID); err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetInactiveChats(ctx context.Context) (map[int64]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, reason FROM inactive_chats`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	chats := make(map[int64]string)
+	for rows.Next() {
+		var chatID int64
+		var reason string
+		if err := rows.Scan(&chatID, &reason); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		chats[chatID] = reason
+	}
+	return chats, nil
+}
+
+func (s *SQLiteStorage) ClaimUpdate(ctx context.Context, updateID int) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO handled_updates (update_id, handled_at) VALUES (?, ?)
+		ON CONFLICT (update_id) DO NOTHING`,
+		updateID, time.Now().UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (s *SQLiteStorage) GetLastUpdateID(ctx context.Context) (int, error) {
+	var updateID int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(update_id), 0) FROM handled_updates`).Scan(&updateID); err != nil {
+		return 0, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	return updateID, nil
+}
+
+func (s *SQLiteStorage) DeleteHandledUpdates(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM handled_updates
+		WHERE handled_at < ? AND update_id < (SELECT MAX(update_id) FROM handled_updates)`,
+		before.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) AddAttachment(ctx context.Context, attachment domain.Attachment) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_attachments (task_id, chat_id, kind, file_id, file_unique_id, file_name, mime_type, file_size, local_path)
+		VALUES (NULLIF(?, 0), ?, ?, ?, ?, ?, ?, ?, ?)`,
+		attachment.TaskID, attachment.ChatID, attachment.Kind, attachment.FileID, attachment.FileUniqueID,
+		attachment.FileName, attachment.MimeType, attachment.FileSize, attachment.LocalPath)
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	attachmentID, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.LastInsertId: %w", err)
+	}
+	return int(attachmentID), nil
+}
+
+func (s *SQLiteStorage) BindAttachments(ctx context.Context, chatID int64, taskID int) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE task_attachments SET task_id = ? WHERE task_id IS NULL AND chat_id = ?`, taskID, chatID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
 
-func (s *SQLiteStorage) DeleteTask(ctx context.Context, taskID int) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, taskID)
+func (s *SQLiteStorage) DeletePendingAttachments(ctx context.Context, chatID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM task_attachments WHERE task_id IS NULL AND chat_id = ?`, chatID)
 	if err != nil {
 		return fmt.Errorf("sqlite.Exec: %w", err)
 	}
 	return nil
 }
 
-func (s *SQLiteStorage) ChangeTaskDeadline(ctx context.Context, taskID int, newDeadline time.Time) error {
-	_, err := s.db.ExecContext(ctx, `UPDATE tasks SET deadline = ?, expired = false WHERE id = ?`, newDeadline, taskID)
-	if err != nil {
-		return fmt.Errorf("sqlite.Exec: %w", err)
+func (s *SQLiteStorage) GetAttachments(ctx context.Context, taskID int) ([]domain.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, task_id, chat_id, kind, file_id, file_unique_id, file_name, mime_type, file_size, local_path, created_at
+		FROM task_attachments WHERE task_id = ? ORDER BY id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []domain.Attachment
+	for rows.Next() {
+		var a domain.Attachment
+		if err := rows.Scan(&a.ID, &a.TaskID, &a.ChatID, &a.Kind, &a.FileID, &a.FileUniqueID,
+			&a.FileName, &a.MimeType, &a.FileSize, &a.LocalPath, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+func (s *SQLiteStorage) AddProof(ctx context.Context, proof domain.Proof) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_proofs (task_id, chat_id, author, kind, file_id, text, latitude, longitude)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		proof.TaskID, proof.ChatID, proof.Author, proof.Kind, proof.FileID, proof.Text, proof.Latitude, proof.Longitude)
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	proofID, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("sqlite.LastInsertId: %w", err)
+	}
+	return int(proofID), nil
+}
+
+func (s *SQLiteStorage) GetProofs(ctx context.Context, taskID int) ([]domain.Proof, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, task_id, chat_id, author, kind, file_id, text, latitude, longitude, created_at
+		FROM task_proofs WHERE task_id = ? ORDER BY id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var proofs []domain.Proof
+	for rows.Next() {
+		var p domain.Proof
+		if err := rows.Scan(&p.ID, &p.TaskID, &p.ChatID, &p.Author, &p.Kind, &p.FileID, &p.Text, &p.Latitude, &p.Longitude, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		proofs = append(proofs, p)
 	}
-	return nil
+	return proofs, nil
 }
 
 func (s *SQLiteStorage) GetTaskInProgress(ctx context.Context, chatID int64) (domain.Task, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT title, executor_contact, executor_chat_id, deadline FROM tasks_in_progress WHERE chat_id = ?`, chatID)
+	row := s.db.QueryRowContext(ctx, `SELECT title, executor_contact, executor_chat_id, deadline, priority FROM tasks_in_progress WHERE chat_id = ?`, chatID)
 	var task domain.Task
 	var deadline sql.NullTime
-	if err := row.Scan(&task.Title, &task.ExecutorContact, &task.ExecutorChatID, &deadline); err != nil {
+	if err := row.Scan(&task.Title, &task.ExecutorContact, &task.ExecutorChatID, &deadline, &task.Priority); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Task{}, errs.ErrNotFound
 		}
@@ -405,6 +1918,16 @@ func (s *SQLiteStorage) SetTaskInProgressDeadline(ctx context.Context, chatID in
 	return nil
 }
 
+func (s *SQLiteStorage) SetTaskInProgressPriority(ctx context.Context, chatID int64, priority domain.Priority) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO tasks_in_progress (chat_id, priority) VALUES (?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET priority = EXCLUDED.priority`, chatID, priority)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
 func (s *SQLiteStorage) AddMessage(ctx context.Context, message domain.Message) error {
 	return nil
 }
@@ -416,3 +1939,487 @@ func (s *SQLiteStorage) RetrieveMessages(ctx context.Context) ([]domain.Message,
 func (s *SQLiteStorage) SetHandledMessage(ctx context.Context, messageID int) error {
 	return nil
 }
+
+func (s *SQLiteStorage) AddInvite(ctx context.Context, invite domain.Invite) error {
+	var expiresAt sql.NullTime
+	if !invite.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: invite.ExpiresAt, Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO invites (code, team_id, role, username, created_by, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		invite.Code, invite.TeamID, int(invite.Role), invite.Username, invite.CreatedBy, expiresAt)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetActiveInvites(ctx context.Context, teamID int64) ([]domain.Invite, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT code, team_id, role, username, created_by, redeemed_by, redeemed_at, expires_at, revoked
+		FROM invites WHERE team_id = ? AND revoked = false AND redeemed_by IS NULL`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var invites []domain.Invite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		if invite.IsActive(now) {
+			invites = append(invites, invite)
+		}
+	}
+	return invites, nil
+}
+
+func (s *SQLiteStorage) RedeemInvite(ctx context.Context, code string, chatID int64, username string) (domain.Invite, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT code, team_id, role, username, created_by, redeemed_by, redeemed_at, expires_at, revoked
+		FROM invites WHERE code = ?`, code)
+	invite, err := scanInvite(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Invite{}, errs.ErrNotFound
+		}
+		return domain.Invite{}, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+
+	now := time.Now()
+	if !invite.IsActive(now) || !invite.CanBeRedeemedBy(username) {
+		return domain.Invite{}, errs.ErrNotFound
+	}
+
+	// redeemed_by condition guarantees that the code is used only once even with concurrent requests
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE invites SET redeemed_by = ?, redeemed_at = ? WHERE code = ? AND redeemed_by IS NULL AND revoked = false`,
+		chatID, now, code)
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return domain.Invite{}, errs.ErrNotFound
+	}
+
+	invite.RedeemedBy = chatID
+	invite.RedeemedAt = now
+	return invite, nil
+}
+
+func (s *SQLiteStorage) RevokeInvite(ctx context.Context, teamID int64, code string) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE invites SET revoked = true WHERE code = ? AND team_id = ? AND redeemed_by IS NULL`, code, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *SQLiteStorage) AddRecurrence(ctx context.Context, recurrence domain.Recurrence) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO recurrences (team_id, title, executor_contact, executor_chat_id, schedule, deadline_in, next_run, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		recurrence.TeamID,
+		recurrence.Title,
+		recurrence.ExecutorContact,
+		recurrence.ExecutorChatID,
+		recurrence.Schedule.String(),
+		int64(recurrence.DeadlineIn.Seconds()),
+		recurrence.NextRun.UTC(),
+		recurrence.CreatedBy,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	recurrenceID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite.LastInsertId: %w", err)
+	}
+	return recurrenceID, nil
+}
+
+const recurrenceColumns = `id, team_id, title, executor_contact, executor_chat_id, schedule, deadline_in, next_run, paused, created_by`
+
+func (s *SQLiteStorage) GetRecurrence(ctx context.Context, teamID, recurrenceID int64) (domain.Recurrence, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+recurrenceColumns+` FROM recurrences WHERE id = ? AND team_id = ?`, recurrenceID, teamID)
+	recurrence, err := scanRecurrence(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Recurrence{}, errs.ErrNotFound
+		}
+		return domain.Recurrence{}, fmt.Errorf("sqlite.Scan: %w", err)
+	}
+	return recurrence, nil
+}
+
+func (s *SQLiteStorage) GetRecurrences(ctx context.Context, teamID int64) ([]domain.Recurrence, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+recurrenceColumns+` FROM recurrences WHERE team_id = ? ORDER BY id`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var recurrences []domain.Recurrence
+	for rows.Next() {
+		recurrence, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	return recurrences, nil
+}
+
+func (s *SQLiteStorage) GetDueRecurrences(ctx context.Context, now time.Time) ([]domain.Recurrence, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+recurrenceColumns+` FROM recurrences WHERE paused = false AND next_run <= ?`, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var recurrences []domain.Recurrence
+	for rows.Next() {
+		recurrence, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	return recurrences, nil
+}
+
+func (s *SQLiteStorage) AdvanceRecurrence(ctx context.Context, recurrenceID int64, from, to time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `UPDATE recurrences SET next_run = ? WHERE id = ? AND next_run = ?`, to.UTC(), recurrenceID, from.UTC())
+	if err != nil {
+		return false, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	return affectedRows > 0, nil
+}
+
+func (s *SQLiteStorage) SetRecurrencePaused(ctx context.Context, teamID, recurrenceID int64, paused bool, nextRun time.Time) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE recurrences SET paused = ?, next_run = ? WHERE id = ? AND team_id = ?`, paused, nextRun.UTC(), recurrenceID, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteRecurrence(ctx context.Context, teamID, recurrenceID int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM recurrences WHERE id = ? AND team_id = ?`, recurrenceID, teamID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func scanRecurrence(row scanner) (domain.Recurrence, error) {
+	var recurrence domain.Recurrence
+	var executorChatID sql.NullInt64
+	var schedule string
+	var deadlineIn int64
+	err := row.Scan(
+		&recurrence.ID,
+		&recurrence.TeamID,
+		&recurrence.Title,
+		&recurrence.ExecutorContact,
+		&executorChatID,
+		&schedule,
+		&deadlineIn,
+		&recurrence.NextRun,
+		&recurrence.Paused,
+		&recurrence.CreatedBy,
+	)
+	if err != nil {
+		return domain.Recurrence{}, err
+	}
+	if recurrence.Schedule, err = domain.ParseSchedule(schedule); err != nil {
+		return domain.Recurrence{}, fmt.Errorf("domain.ParseSchedule: %w", err)
+	}
+	recurrence.ExecutorChatID = executorChatID.Int64
+	recurrence.DeadlineIn = time.Duration(deadlineIn) * time.Second
+	recurrence.NextRun = recurrence.NextRun.Local()
+	return recurrence, nil
+}
+
+func (s *SQLiteStorage) SaveTemplate(ctx context.Context, template domain.TaskTemplate) error {
+	checklist, err := json.Marshal(template.Checklist)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO task_templates (team_id, name, title_pattern, executor_contact, deadline_in, priority, description, checklist, created_by, proof_required)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(team_id, name) DO UPDATE SET
+			title_pattern = EXCLUDED.title_pattern,
+			executor_contact = EXCLUDED.executor_contact,
+			deadline_in = EXCLUDED.deadline_in,
+			priority = EXCLUDED.priority,
+			description = EXCLUDED.description,
+			checklist = EXCLUDED.checklist,
+			created_by = EXCLUDED.created_by,
+			proof_required = EXCLUDED.proof_required`,
+		template.TeamID,
+		template.Name,
+		template.TitlePattern,
+		template.ExecutorContact,
+		int64(template.DeadlineIn.Seconds()),
+		template.Priority,
+		template.Description,
+		string(checklist),
+		template.CreatedBy,
+		template.ProofRequired,
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+const templateColumns = `team_id, name, title_pattern, executor_contact, deadline_in, priority, description, checklist, created_by, proof_required`
+
+func (s *SQLiteStorage) GetTemplate(ctx context.Context, teamID int64, name string) (domain.TaskTemplate, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM task_templates WHERE team_id = ? AND name = ?`, teamID, name)
+	template, err := scanTemplate(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.TaskTemplate{}, errs.ErrNotFound
+		}
+		return domain.TaskTemplate{}, fmt.Errorf("sqlite.Scan: %w", err)
+	}
+	return template, nil
+}
+
+func (s *SQLiteStorage) GetTemplates(ctx context.Context, teamID int64) ([]domain.TaskTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+templateColumns+` FROM task_templates WHERE team_id = ? ORDER BY name`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []domain.TaskTemplate
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func (s *SQLiteStorage) DeleteTemplate(ctx context.Context, teamID int64, name string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM task_templates WHERE team_id = ? AND name = ?`, teamID, name)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func scanTemplate(row scanner) (domain.TaskTemplate, error) {
+	var template domain.TaskTemplate
+	var deadlineIn int64
+	var checklist string
+	err := row.Scan(
+		&template.TeamID,
+		&template.Name,
+		&template.TitlePattern,
+		&template.ExecutorContact,
+		&deadlineIn,
+		&template.Priority,
+		&template.Description,
+		&checklist,
+		&template.CreatedBy,
+		&template.ProofRequired,
+	)
+	if err != nil {
+		return domain.TaskTemplate{}, err
+	}
+	if err := json.Unmarshal([]byte(checklist), &template.Checklist); err != nil {
+		return domain.TaskTemplate{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	template.DeadlineIn = time.Duration(deadlineIn) * time.Second
+	return template, nil
+}
+
+func scanInvite(row scanner) (domain.Invite, error) {
+	var invite domain.Invite
+	var role int
+	var username sql.NullString
+	var redeemedBy sql.NullInt64
+	var redeemedAt, expiresAt sql.NullTime
+	if err := row.Scan(&invite.Code, &invite.TeamID, &role, &username, &invite.CreatedBy, &redeemedBy, &redeemedAt, &expiresAt, &invite.Revoked); err != nil {
+		return domain.Invite{}, err
+	}
+	invite.Role = domain.Role(role)
+	invite.Username = username.String
+	invite.RedeemedBy = redeemedBy.Int64
+	invite.RedeemedAt = redeemedAt.Time
+	invite.ExpiresAt = expiresAt.Time
+	return invite, nil
+}
+
+func (s *SQLiteStorage) AddAdminAction(ctx context.Context, action domain.AdminAction) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO admin_actions (team_id, admin_chat_id, target_chat_id, action, details) VALUES (?, ?, ?, ?, ?)`,
+		action.TeamID, action.AdminChatID, action.TargetChatID, action.Action, action.Details)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetAdminActions(ctx context.Context, teamID int64, limit int) ([]domain.AdminAction, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, team_id, admin_chat_id, target_chat_id, action, details, created_at
+		FROM admin_actions WHERE team_id = ? ORDER BY id DESC LIMIT ?`, teamID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []domain.AdminAction
+	for rows.Next() {
+		var action domain.AdminAction
+		if err := rows.Scan(&action.ID, &action.TeamID, &action.AdminChatID, &action.TargetChatID, &action.Action, &action.Details, &action.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func (s *SQLiteStorage) GetPasswordAttempts(ctx context.Context, chatID int64) (domain.PasswordAttempts, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT chat_id, failures, lockouts, locked_until FROM password_attempts WHERE chat_id = ?`, chatID)
+	attempts, err := scanPasswordAttempts(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PasswordAttempts{ChatID: chatID}, errs.ErrNotFound
+		}
+		return domain.PasswordAttempts{ChatID: chatID}, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	return attempts, nil
+}
+
+func (s *SQLiteStorage) RegisterPasswordFailure(ctx context.Context, chatID int64, now time.Time) (domain.PasswordAttempts, error) {
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO password_attempts (chat_id, failures, lockouts, updated_at) VALUES (?, 1, 0, CURRENT_TIMESTAMP)
+		ON CONFLICT(chat_id) DO UPDATE SET
+			failures = failures + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE locked_until IS NULL OR locked_until <= ?
+		RETURNING chat_id, failures, lockouts, locked_until`,
+		chatID, now.Unix())
+	attempts, err := scanPasswordAttempts(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the chat is locked, the attempt is not counted
+			return s.GetPasswordAttempts(ctx, chatID)
+		}
+		return domain.PasswordAttempts{ChatID: chatID}, fmt.Errorf("sqlite.QueryRow: %w", err)
+	}
+	return attempts, nil
+}
+
+func (s *SQLiteStorage) LockPasswordAttempts(ctx context.Context, chatID int64, lockouts int, until time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE password_attempts SET failures = 0, lockouts = lockouts + 1, locked_until = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE chat_id = ? AND lockouts = ?`,
+		until.Unix(), chatID, lockouts)
+	if err != nil {
+		return false, fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	return affectedRows == 1, nil
+}
+
+func (s *SQLiteStorage) GetFailedPasswordAttempts(ctx context.Context) ([]domain.PasswordAttempts, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT chat_id, failures, lockouts, locked_until FROM password_attempts WHERE failures > 0 OR lockouts > 0`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.PasswordAttempts
+	for rows.Next() {
+		attempts, err := scanPasswordAttempts(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite.Scan: %w", err)
+		}
+		result = append(result, attempts)
+	}
+	return result, nil
+}
+
+func (s *SQLiteStorage) ResetPasswordAttempts(ctx context.Context, chatID int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM password_attempts WHERE chat_id = ?`, chatID)
+	if err != nil {
+		return fmt.Errorf("sqlite.Exec: %w", err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite.RowsAffected: %w", err)
+	}
+	if affectedRows == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func scanPasswordAttempts(row scanner) (domain.PasswordAttempts, error) {
+	var attempts domain.PasswordAttempts
+	var lockedUntil sql.NullInt64
+	if err := row.Scan(&attempts.ChatID, &attempts.Failures, &attempts.Lockouts, &lockedUntil); err != nil {
+		return domain.PasswordAttempts{}, err
+	}
+	if lockedUntil.Valid {
+		attempts.LockedUntil = time.Unix(lockedUntil.Int64, 0)
+	}
+	return attempts, nil
+}
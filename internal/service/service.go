@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
 	"tasks_bot/internal/reconciler"
 	"tasks_bot/internal/repository"
 	"tasks_bot/internal/telegram"
@@ -14,11 +17,25 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// handledUpdatesTTL is how long handled updates are kept for dedupe, Telegram keeps undelivered updates for 24 hours
+	handledUpdatesTTL = 48 * time.Hour
+	// handledUpdatesCleanup is how often old handled updates are deleted
+	handledUpdatesCleanup = time.Hour
+)
+
 type Service struct {
 	bot     *telegram.Bot
 	rec     *reconciler.Reconciler
 	storage repository.Storage
 
+	// nextDigest is the next run of expired low priority tasks digest, a digest missed during downtime is not sent
+	nextDigest time.Time
+	// nextDashboards is the next refresh of pinned dashboards, they show overdue tasks as time goes
+	nextDashboards time.Time
+	// nextUpdatesCleanup is the next deletion of old handled updates
+	nextUpdatesCleanup time.Time
+
 	logger *log.Entry
 }
 
@@ -69,14 +86,28 @@ func (s *Service) Close() {
 	s.bot.Stop()
 }
 
+// loop runs the periodic jobs, they are independent, so a failed job doesn't stop the others.
+// Returns errors of the failed jobs joined.
 func (s *Service) loop(ctx context.Context) error {
-	if err := s.processMessages(ctx); err != nil {
-		return fmt.Errorf("s.processMessages: %s", err)
+	jobs := []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{name: "s.processMessages", run: s.processMessages},
+		{name: "s.processExpiredTasks", run: s.processExpiredTasks},
+		{name: "s.processRecurrences", run: s.processRecurrences},
+		{name: "s.processReminders", run: s.processReminders},
+		{name: "s.processExpiredDigest", run: s.processExpiredDigest},
+		{name: "s.processDashboards", run: s.processDashboards},
+		{name: "s.processHandledUpdates", run: s.processHandledUpdates},
 	}
-	if err := s.processExpiredTasks(ctx); err != nil {
-		return fmt.Errorf("s.processExpiredTasks: %w", err)
+	var failures []error
+	for _, job := range jobs {
+		if err := job.run(ctx); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", job.name, err))
+		}
 	}
-	return nil
+	return errors.Join(failures...)
 }
 
 func (s *Service) processMessages(ctx context.Context) error {
@@ -95,15 +126,224 @@ func (s *Service) processMessages(ctx context.Context) error {
 	return nil
 }
 
+// processRecurrences creates tasks of the recurrences whose run has come. Runs missed
+// during downtime are collapsed into the latest of them, so no duplicates are created.
+// A failed recurrence doesn't stop the others.
+func (s *Service) processRecurrences(ctx context.Context) error {
+	now := time.Now()
+	recurrences, err := s.storage.GetDueRecurrences(ctx, now)
+	if err != nil {
+		return fmt.Errorf("s.storage.GetDueRecurrences: %w", err)
+	}
+
+	for _, recurrence := range recurrences {
+		if err := s.processRecurrence(ctx, recurrence, now); err != nil {
+			s.logger.WithError(err).WithField("recurrenceID", recurrence.ID).Error("failed to process recurrence")
+		}
+	}
+	return nil
+}
+
+func (s *Service) processRecurrence(ctx context.Context, recurrence domain.Recurrence, now time.Time) error {
+	run, next := recurrence.NextRun, recurrence.Schedule.Next(recurrence.NextRun)
+	for !next.IsZero() && !next.After(now) {
+		run, next = next, recurrence.Schedule.Next(next)
+	}
+	if next.IsZero() {
+		s.logger.WithField("recurrenceID", recurrence.ID).Warn("recurrence has no more runs, pausing it")
+		if err := s.storage.SetRecurrencePaused(ctx, recurrence.TeamID, recurrence.ID, true, recurrence.NextRun); err != nil {
+			return fmt.Errorf("s.storage.SetRecurrencePaused: %w", err)
+		}
+		return nil
+	}
+	// the run is claimed before the task is created, so concurrent loops can't create it twice
+	claimed, err := s.storage.AdvanceRecurrence(ctx, recurrence.ID, recurrence.NextRun, next)
+	if err != nil {
+		return fmt.Errorf("s.storage.AdvanceRecurrence: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+	if err := s.createOccurrence(ctx, recurrence, run); err != nil {
+		// the task is not created, the run is given back to be retried by the next loop
+		if _, rollbackErr := s.storage.AdvanceRecurrence(ctx, recurrence.ID, next, recurrence.NextRun); rollbackErr != nil {
+			return fmt.Errorf("s.createOccurrence: %w, s.storage.AdvanceRecurrence: %w", err, rollbackErr)
+		}
+		return fmt.Errorf("s.createOccurrence: %w", err)
+	}
+	return nil
+}
+
+// createOccurrence creates the task of the run. Returns error only if the task is not created,
+// failures after that are logged, so the retried run doesn't create a duplicate.
+func (s *Service) createOccurrence(ctx context.Context, recurrence domain.Recurrence, run time.Time) error {
+	task := recurrence.Occurrence(run)
+	// executor could start the bot after the recurrence was added
+	if task.ExecutorChatID == 0 {
+		chat, err := s.storage.GetChat(ctx, task.ExecutorContact, task.ExecutorContact)
+		if err != nil && !errors.Is(err, errs.ErrNotFound) {
+			return fmt.Errorf("s.storage.GetChat: %w", err)
+		}
+		if chat != nil {
+			task.ExecutorChatID = chat.ID
+		}
+	}
+
+	taskID, err := s.storage.AddTask(ctx, task)
+	if err != nil {
+		return fmt.Errorf("s.storage.AddTask: %w", err)
+	}
+	task.ID = taskID
+
+	// the occurrence is already created, so failures below don't fail it
+	logger := s.logger.WithField("taskID", taskID)
+	if task.ExecutorChatID != 0 {
+		participant := domain.TaskParticipant{TaskID: taskID, ChatID: task.ExecutorChatID, Role: domain.ExecutorParticipant}
+		if err := s.storage.AddTaskParticipant(ctx, participant); err != nil {
+			logger.WithError(err).Error("failed to add task participant")
+		}
+	}
+	if task.Tags = domain.ParseTags(task.Title); len(task.Tags) > 0 {
+		if err := s.storage.SetTaskTags(ctx, task.TeamID, taskID, task.Tags); err != nil {
+			logger.WithError(err).Error("failed to set task tags")
+		}
+	}
+	if err := s.bot.NotifyTaskCreated(ctx, task, task.ExecutorChatID); err != nil {
+		logger.WithError(err).Error("failed to notify about created task")
+	}
+	if task.ExecutorChatID == 0 {
+		return nil
+	}
+	if err := s.bot.NotifyExecutor(ctx, task); err != nil {
+		logger.WithError(err).Error("failed to notify executor")
+	}
+	return nil
+}
+
 func (s *Service) processExpiredTasks(ctx context.Context) error {
 	tasks, err := s.storage.GetExpiredTasksToMark(ctx)
 	if err != nil {
 		return fmt.Errorf("s.storage.GetExpiredTasks: %w", err)
 	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	// overdue task waiting for another one mentions its upstream blockers
+	taskIDs := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	blockers, err := s.storage.GetBlockers(ctx, taskIDs)
+	if err != nil {
+		return fmt.Errorf("s.storage.GetBlockers: %w", err)
+	}
+	for _, task := range tasks {
+		// low priority tasks are reported in the digest instead of at once
+		if task.Priority == domain.LowPriority {
+			continue
+		}
+		task.BlockedBy = domain.OpenBlockers(blockers[task.ID])
+		// the task is already marked expired, so a failed notification is logged and the rest of the batch goes on
+		logger := s.logger.WithField("taskID", task.ID)
+		if err := s.bot.NotifyTaskExpired(ctx, task); err != nil {
+			logger.WithError(err).Error("failed to notify about expired task")
+		}
+		if err := s.bot.NotifyChief(ctx, task); err != nil {
+			logger.WithError(err).Error("failed to notify chief")
+		}
+		if task.Priority == domain.CriticalPriority {
+			if err := s.bot.NotifyAdmin(ctx, task); err != nil {
+				logger.WithError(err).Error("failed to notify admin")
+			}
+		}
+	}
+	return nil
+}
+
+// processReminders reminds executors about open tasks as often as their priority requires.
+// The first reminder of the task is only scheduled, so the executor is not reminded right after the task is created.
+func (s *Service) processReminders(ctx context.Context) error {
+	now := time.Now()
+	reminders, err := s.storage.GetTasksToRemind(ctx, now)
+	if err != nil {
+		return fmt.Errorf("s.storage.GetTasksToRemind: %w", err)
+	}
+
+	policy := s.bot.PriorityPolicy()
+	for _, reminder := range reminders {
+		// reminder is moved before it is sent, so a failed send is not repeated every loop
+		if err := s.storage.SetTaskReminder(ctx, reminder.Task.ID, policy.NextReminder(reminder.Task, now)); err != nil {
+			s.logger.WithError(err).WithField("taskID", reminder.Task.ID).Error("failed to set task reminder")
+			continue
+		}
+		if reminder.At.IsZero() {
+			continue
+		}
+		if err := s.bot.NotifyReminder(ctx, reminder.Task); err != nil {
+			s.logger.WithError(err).WithField("taskID", reminder.Task.ID).Error("failed to send reminder")
+		}
+	}
+	return nil
+}
+
+// processExpiredDigest sends observers the digest of expired low priority tasks by the digest schedule.
+func (s *Service) processExpiredDigest(ctx context.Context) error {
+	now := time.Now()
+	schedule := s.bot.PriorityPolicy().Digest
+	if s.nextDigest.IsZero() {
+		s.nextDigest = schedule.Next(now)
+	}
+	if s.nextDigest.IsZero() || now.Before(s.nextDigest) {
+		return nil
+	}
+	s.nextDigest = schedule.Next(now)
+
+	tasks, err := s.storage.GetOverdueTasks(ctx, domain.LowPriority)
+	if err != nil {
+		return fmt.Errorf("s.storage.GetOverdueTasks: %w", err)
+	}
+	teams := make(map[int64][]domain.Task)
+	var teamIDs []int64
 	for _, task := range tasks {
-		if err := s.bot.NotifyObservers(ctx, task); err != nil {
-			return fmt.Errorf("s.bot.NotifyObservers: %w", err)
+		if _, ok := teams[task.TeamID]; !ok {
+			teamIDs = append(teamIDs, task.TeamID)
+		}
+		teams[task.TeamID] = append(teams[task.TeamID], task)
+	}
+	for _, teamID := range teamIDs {
+		if err := s.bot.NotifyExpiredDigest(ctx, teamID, teams[teamID]); err != nil {
+			s.logger.WithError(err).WithField("teamID", teamID).Error("failed to send expired digest")
 		}
 	}
 	return nil
 }
+
+// processDashboards refreshes pinned dashboards every dashboard interval.
+func (s *Service) processDashboards(ctx context.Context) error {
+	interval := s.bot.DashboardInterval()
+	now := time.Now()
+	if interval <= 0 || now.Before(s.nextDashboards) {
+		return nil
+	}
+	s.nextDashboards = now.Add(interval)
+
+	if err := s.bot.RefreshDashboards(ctx); err != nil {
+		return fmt.Errorf("s.bot.RefreshDashboards: %w", err)
+	}
+	return nil
+}
+
+// processHandledUpdates deletes handled updates which Telegram can't repeat anymore, the last one stays as the offset.
+func (s *Service) processHandledUpdates(ctx context.Context) error {
+	now := time.Now()
+	if now.Before(s.nextUpdatesCleanup) {
+		return nil
+	}
+	s.nextUpdatesCleanup = now.Add(handledUpdatesCleanup)
+
+	if err := s.storage.DeleteHandledUpdates(ctx, now.Add(-handledUpdatesTTL)); err != nil {
+		return fmt.Errorf("s.storage.DeleteHandledUpdates: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	auditLimit   = 30
+	bannedText   = "Доступ к команде ограничен администратором. Перейти в другую команду: /" + teamCmd + " название"
+	userNotFound = "Пользователь не найден. Укажите @username, номер телефона или id чата"
+	// passwordTeamText is the answer in teams other than the default one, passwords are common for the whole bot
+	passwordTeamText = "Пароли ролей действуют только в основной команде. Роль в этой команде назначает её администратор"
+)
+
+// checkChatAccess rejects chats banned in their active team and updates last activity of others,
+// banned chats may only switch to another team. Returns false if message should not be handled.
+func (b *Bot) checkChatAccess(ctx context.Context, message *tgbotapi.Message) bool {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	chat, err := b.storage.GetChatByID(ctx, message.Chat.ID)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to get chat")
+		}
+		return true
+	}
+
+	if chat.Banned && !(message.IsCommand() && message.Command() == teamCmd) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, bannedText)
+		if _, err := b.bot.Send(msg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+		return false
+	}
+
+	if err := b.storage.TouchChat(ctx, message.Chat.ID, time.Now()); err != nil {
+		logger.WithError(err).Error("failed to update last activity")
+	}
+	return true
+}
+
+func (b *Bot) handleUsersCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	chats, err := b.storage.GetChatsStats(ctx, teamID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get chats stats")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if len(chats) == 0 {
+		responseMsg.Text = "Нет пользователей"
+		return
+	}
+
+	builder := strings.Builder{}
+	for _, chat := range chats {
+		builder.WriteString(chat.String())
+		builder.WriteString("\n\n")
+	}
+	responseMsg.Text = builder.String()
+}
+
+func (b *Bot) handleAuditCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	actions, err := b.storage.GetAdminActions(ctx, teamID, auditLimit)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get admin actions")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if len(actions) == 0 {
+		responseMsg.Text = "Журнал действий пуст"
+		return
+	}
+
+	builder := strings.Builder{}
+	for _, action := range actions {
+		builder.WriteString(action.String())
+		builder.WriteString("\n")
+	}
+	responseMsg.Text = builder.String()
+}
+
+// handleManageUserCommand changes target chat by admin: /set_role <user> <role>, /revoke_role <user>,
+// /ban <user>, /unban <user>, /reset_stage <user>.
+func (b *Bot) handleManageUserCommand(ctx context.Context, message *tgbotapi.Message, command string) {
+	logger := b.logger.WithField("chatID", message.Chat.ID).WithField("command", command)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	expectedArgs := 1
+	if command == setRoleCmd {
+		expectedArgs = 2
+	}
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != expectedArgs {
+		responseMsg.Text = manageUserUsage(command)
+		return
+	}
+
+	target, err := b.findChat(ctx, args[0])
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = userNotFound
+			return
+		}
+		logger.WithError(err).Error("failed to find chat")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if target.ID == message.Chat.ID && command != resetStageCmd {
+		responseMsg.Text = "Нельзя изменить собственную учётную запись"
+		return
+	}
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	// admin manages only members of own team, set_role also adds a new member to it
+	targetRole, err := b.storage.GetRole(ctx, teamID, target.ID)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to get target role")
+			responseMsg.Text = errorReponse
+			return
+		}
+		if command != setRoleCmd {
+			responseMsg.Text = "Пользователь не состоит в вашей команде"
+			return
+		}
+	}
+
+	action := domain.AdminAction{
+		TeamID:       teamID,
+		AdminChatID:  message.Chat.ID,
+		TargetChatID: target.ID,
+	}
+	var targetNotification string
+	switch command {
+	case setRoleCmd:
+		role, err := domain.ParseRole(args[1])
+		if err != nil {
+			responseMsg.Text = manageUserUsage(command)
+			return
+		}
+		if err := b.storage.SetRole(ctx, teamID, target.ID, role); err != nil {
+			logger.WithError(err).Error("failed to set role")
+			responseMsg.Text = errorReponse
+			return
+		}
+		if target.TeamID == teamID {
+			if err := b.setCommands(ctx, target.ID, role); err != nil {
+				logger.WithError(err).Error("failed to set commands")
+			}
+		}
+		action.Action, action.Details = domain.SetRoleAction, role.String()
+		targetNotification = fmt.Sprintf("Администратор изменил вашу роль на \"%s\"", role)
+		responseMsg.Text = fmt.Sprintf("Роль пользователя %d изменена на \"%s\"", target.ID, role)
+
+	case revokeRoleCmd:
+		if err := b.storage.SetRole(ctx, teamID, target.ID, domain.UnknownRole); err != nil {
+			logger.WithError(err).Error("failed to revoke role")
+			responseMsg.Text = errorReponse
+			return
+		}
+		if target.TeamID == teamID {
+			if err := b.setCommands(ctx, target.ID, domain.UnknownRole); err != nil {
+				logger.WithError(err).Error("failed to set commands")
+			}
+		}
+		action.Action, action.Details = domain.RevokeRoleAction, targetRole.String()
+		targetNotification = "Администратор отозвал вашу роль"
+		responseMsg.Text = fmt.Sprintf("Роль пользователя %d отозвана", target.ID)
+
+	case banCmd, unbanCmd:
+		banned := command == banCmd
+		if banned && target.ID == b.cfg.AdminID {
+			responseMsg.Text = "Нельзя заблокировать главного администратора"
+			return
+		}
+		// the ban closes only the admin's team, other teams of the user are not affected
+		if err := b.storage.SetBanned(ctx, teamID, target.ID, banned); err != nil {
+			logger.WithError(err).Error("failed to set banned")
+			responseMsg.Text = errorReponse
+			return
+		}
+		if banned {
+			action.Action = domain.BanAction
+			responseMsg.Text = fmt.Sprintf("Пользователь %d заблокирован в команде", target.ID)
+		} else {
+			action.Action = domain.UnbanAction
+			targetNotification = "Администратор снял ограничения доступа к команде"
+			responseMsg.Text = fmt.Sprintf("Пользователь %d разблокирован в команде", target.ID)
+		}
+
+	case resetStageCmd:
+		if err := b.storage.SetStage(ctx, target.ID, domain.Default); err != nil {
+			logger.WithError(err).Error("failed to reset stage")
+			responseMsg.Text = errorReponse
+			return
+		}
+		action.Action = domain.ResetStageAction
+		responseMsg.Text = fmt.Sprintf("Состояние диалога пользователя %d сброшено", target.ID)
+	}
+
+	if err := b.storage.AddAdminAction(ctx, action); err != nil {
+		logger.WithError(err).Error("failed to record admin action")
+	}
+
+	if targetNotification != "" {
+		msg := tgbotapi.NewMessage(target.ID, targetNotification)
+		if _, err := b.bot.SendNotification(msg); err != nil {
+			logger.WithError(err).Error("failed to notify target user")
+		}
+	}
+}
+
+func (b *Bot) handleLockoutsCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	if !b.managesPasswords(ctx, message.Chat.ID) {
+		responseMsg.Text = passwordTeamText
+		return
+	}
+	attempts, err := b.storage.GetFailedPasswordAttempts(ctx)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get failed password attempts")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if len(attempts) == 0 {
+		responseMsg.Text = "Нет неверных попыток ввода пароля"
+		return
+	}
+
+	builder := strings.Builder{}
+	for _, chatAttempts := range attempts {
+		builder.WriteString(chatAttempts.String())
+		builder.WriteString("\n\n")
+	}
+	responseMsg.Text = builder.String()
+}
+
+// handleUnlockCommand clears failed password attempts and lockout of the chat: /unlock <chat id>.
+func (b *Bot) handleUnlockCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	chatID, err := strconv.ParseInt(strings.TrimSpace(message.CommandArguments()), 10, 64)
+	if err != nil {
+		responseMsg.Text = fmt.Sprintf("Использование: /%s id чата", unlockCmd)
+		return
+	}
+
+	if !b.managesPasswords(ctx, message.Chat.ID) {
+		responseMsg.Text = passwordTeamText
+		return
+	}
+	if err := b.storage.ResetPasswordAttempts(ctx, chatID); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = "У этого чата нет неверных попыток ввода пароля"
+			return
+		}
+		logger.WithError(err).Error("failed to reset password attempts")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if err := b.storage.AddAdminAction(ctx, domain.AdminAction{
+		TeamID:       domain.DefaultTeamID,
+		AdminChatID:  message.Chat.ID,
+		TargetChatID: chatID,
+		Action:       domain.UnlockAction,
+	}); err != nil {
+		logger.WithError(err).Error("failed to record admin action")
+	}
+	responseMsg.Text = fmt.Sprintf("Блокировка чата %d снята", chatID)
+}
+
+// managesPasswords reports whether the admin works in the default team, password attempts are made only to join it
+// and are not shown to admins of other teams.
+func (b *Bot) managesPasswords(ctx context.Context, chatID int64) bool {
+	teamID, err := b.activeTeamID(ctx, chatID)
+	if err != nil {
+		b.logger.WithError(err).WithField("chatID", chatID).Error("failed to get active team")
+		return false
+	}
+	return teamID == domain.DefaultTeamID
+}
+
+func manageUserUsage(command string) string {
+	if command == setRoleCmd {
+		return "Использование: /set_role пользователь роль\nРоли: executor, observer, chief, admin"
+	}
+	return fmt.Sprintf("Использование: /%s пользователь", command)
+}
+
+// findChat looks for the chat by id, @username or phone number.
+func (b *Bot) findChat(ctx context.Context, target string) (*domain.Chat, error) {
+	if chatID, err := strconv.ParseInt(target, 10, 64); err == nil {
+		return b.storage.GetChatByID(ctx, chatID)
+	}
+
+	contact := strings.Trim(target, "@")
+	if phone, err := domain.NormalizePhone(contact); err == nil {
+		contact = phone
+	}
+	chat, err := b.storage.GetChat(ctx, contact, contact)
+	if err != nil {
+		return nil, err
+	}
+	if chat == nil {
+		return nil, errs.ErrNotFound
+	}
+	return chat, nil
+}
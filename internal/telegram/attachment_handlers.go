@@ -0,0 +1,317 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const attachUsage = `Использование: отправьте фото или документ с подписью /attach номер_задачи
+или ответьте /attach номер_задачи на сообщение с файлом.
+Файл, отправленный ответом на уведомление о задаче, тоже прикрепляется к ней`
+
+// mediaGroupLimit is the most files Telegram sends in one media group
+const mediaGroupLimit = 10
+
+// captionAsCommand makes the command in the caption of the file, like "/attach 12", the text of the message,
+// so it is handled as any other command.
+func captionAsCommand(message *tgbotapi.Message) {
+	if message.Text != "" || len(message.CaptionEntities) == 0 {
+		return
+	}
+	if entity := message.CaptionEntities[0]; entity.Offset == 0 && entity.IsCommand() {
+		message.Text = message.Caption
+		message.Entities = message.CaptionEntities
+	}
+}
+
+// messageAttachment returns the photo or document of the message, the largest size of the photo is kept.
+func messageAttachment(message *tgbotapi.Message) (domain.Attachment, bool) {
+	switch {
+	case len(message.Photo) > 0:
+		photo := message.Photo[len(message.Photo)-1]
+		return domain.Attachment{
+			Kind:         domain.PhotoAttachment,
+			FileID:       photo.FileID,
+			FileUniqueID: photo.FileUniqueID,
+			MimeType:     "image/jpeg",
+			FileSize:     photo.FileSize,
+		}, true
+	case message.Document != nil:
+		return domain.Attachment{
+			Kind:         domain.DocumentAttachment,
+			FileID:       message.Document.FileID,
+			FileUniqueID: message.Document.FileUniqueID,
+			FileName:     message.Document.FileName,
+			MimeType:     message.Document.MimeType,
+			FileSize:     message.Document.FileSize,
+		}, true
+	default:
+		return domain.Attachment{}, false
+	}
+}
+
+// saveAttachment copies the file to the local cache when it is turned on and stores the attachment.
+// The file is stored even if the copy fails, Telegram keeps it anyway.
+func (b *Bot) saveAttachment(ctx context.Context, logger *log.Entry, attachment domain.Attachment) error {
+	if b.cfg.FilesDir != "" {
+		path, err := b.cacheFile(ctx, attachment)
+		if err != nil {
+			logger.WithError(err).Warn("failed to cache attachment")
+		}
+		attachment.LocalPath = path
+	}
+	if _, err := b.storage.AddAttachment(ctx, attachment); err != nil {
+		return fmt.Errorf("b.storage.AddAttachment: %w", err)
+	}
+	return nil
+}
+
+// cacheFile downloads the file to the files directory and returns its path.
+func (b *Bot) cacheFile(ctx context.Context, attachment domain.Attachment) (string, error) {
+	url, err := b.bot.GetFileDirectURL(attachment.FileID)
+	if err != nil {
+		return "", fmt.Errorf("b.bot.GetFileDirectURL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("http.Do: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download file: unexpected status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(b.cfg.FilesDir, 0o755); err != nil {
+		return "", fmt.Errorf("os.MkdirAll: %w", err)
+	}
+	ext := filepath.Ext(attachment.FileName)
+	if attachment.Kind == domain.PhotoAttachment {
+		ext = ".jpg"
+	}
+	path := filepath.Join(b.cfg.FilesDir, attachment.FileUniqueID+ext)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("os.Create: %w", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return "", fmt.Errorf("io.Copy: %w", err)
+	}
+	return path, nil
+}
+
+// attachToTask stores the file of the message as the attachment of the task sent by the chat.
+// Returns text of the response.
+func (b *Bot) attachToTask(ctx context.Context, logger *log.Entry, chatID int64, message *tgbotapi.Message, task domain.Task) string {
+	attachment, ok := messageAttachment(message)
+	if !ok {
+		return attachUsage
+	}
+	attachment.TaskID = task.ID
+	attachment.ChatID = chatID
+	if err := b.saveAttachment(ctx, logger, attachment); err != nil {
+		logger.WithError(err).Error("failed to save attachment")
+		return errorReponse
+	}
+	return fmt.Sprintf("📎 Файл приложен к задаче №%d, вложения: /%s %d", task.ID, filesCmd, task.ID)
+}
+
+// handleAttachCommand attaches the file with the command in the caption or the file the command replies to: /attach task.
+func (b *Bot) handleAttachCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	fileMessage := message
+	if _, ok := messageAttachment(message); !ok && message.ReplyToMessage != nil {
+		fileMessage = message.ReplyToMessage
+	}
+	if _, ok := messageAttachment(fileMessage); !ok {
+		responseMsg.Text = attachUsage
+		return
+	}
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, message.CommandArguments())
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	responseMsg.Text = b.attachToTask(ctx, logger, message.Chat.ID, fileMessage, task)
+}
+
+// handleAttachmentMessage attaches the file sent as a reply to the task notification or while the task is being created.
+// Returns false when the message has no file.
+func (b *Bot) handleAttachmentMessage(ctx context.Context, message *tgbotapi.Message, stage domain.Stage) bool {
+	attachment, ok := messageAttachment(message)
+	if !ok {
+		return false
+	}
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, attachUsage)
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	switch stage {
+	case domain.AddTaskName, domain.AddTaskUser, domain.AddTaskPriority, domain.AddTaskDeadline:
+		// the file waits for the task in the chat, createTask binds it
+		attachment.ChatID = message.Chat.ID
+		if err := b.saveAttachment(ctx, logger, attachment); err != nil {
+			logger.WithError(err).Error("failed to save pending attachment")
+			responseMsg.Text = errorReponse
+			return true
+		}
+		responseMsg.Text = "📎 Файл будет приложен к создаваемой задаче, продолжайте создание"
+		return true
+	}
+
+	if message.ReplyToMessage == nil {
+		return true
+	}
+	teamID, taskID, err := b.storage.GetMessageTask(ctx, message.Chat.ID, message.ReplyToMessage.MessageID)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to get task of the message")
+			responseMsg.Text = errorReponse
+		}
+		return true
+	}
+	task, text := b.messageTask(ctx, logger, message.Chat.ID, teamID, taskID)
+	if text != "" {
+		responseMsg.Text = text
+		return true
+	}
+	responseMsg.Text = b.attachToTask(ctx, logger, message.Chat.ID, message, task)
+	return true
+}
+
+// handleFilesCommand sends attachments of the task: /files task.
+func (b *Bot) handleFilesCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, message.CommandArguments())
+	if text == "" {
+		text = b.sendAttachments(ctx, logger, message.Chat.ID, task)
+	}
+	if text == "" {
+		return
+	}
+	if _, err := b.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text)); err != nil {
+		logger.WithError(err).Error("failed to send response")
+	}
+}
+
+// handleFilesCallback sends attachments of the task, payload is the task number.
+// Returns text of the answer to callback.
+func (b *Bot) handleFilesCallback(ctx context.Context, logger *log.Entry, message *tgbotapi.Message, payload string) string {
+	task, text := b.callbackTask(ctx, logger, message.Chat.ID, payload)
+	if text != "" {
+		return text
+	}
+	if text := b.sendAttachments(ctx, logger, message.Chat.ID, task); text != "" {
+		return text
+	}
+	return fmt.Sprintf("Вложения задачи №%d", task.ID)
+}
+
+// sendAttachments sends files of the task as media groups, photos and documents can't be mixed in one group.
+// Returns text of the response when the files are not sent.
+func (b *Bot) sendAttachments(ctx context.Context, logger *log.Entry, chatID int64, task domain.Task) string {
+	attachments, err := b.storage.GetAttachments(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get attachments")
+		return errorReponse
+	}
+	if len(attachments) == 0 {
+		return fmt.Sprintf("У задачи №%d нет вложений\n\n%s", task.ID, attachUsage)
+	}
+
+	header := tgbotapi.NewMessage(chatID, fmt.Sprintf("📎 Вложения задачи №%d %s", task.ID, html.EscapeString(task.Title)))
+	header.ParseMode = tgbotapi.ModeHTML
+	if _, err := b.bot.Send(header); err != nil {
+		logger.WithError(err).Error("failed to send attachments header")
+		return errorReponse
+	}
+
+	var photos, documents []domain.Attachment
+	for _, attachment := range attachments {
+		if attachment.Kind == domain.PhotoAttachment {
+			photos = append(photos, attachment)
+		} else {
+			documents = append(documents, attachment)
+		}
+	}
+	for _, group := range [][]domain.Attachment{photos, documents} {
+		for chunk := range slices.Chunk(group, mediaGroupLimit) {
+			err := b.sendMediaGroup(chatID, chunk, false)
+			if err != nil && slices.ContainsFunc(chunk, func(a domain.Attachment) bool { return a.LocalPath != "" }) {
+				// file ids may stop working, e.g. after the bot token is changed, local copies are sent instead
+				logger.WithError(err).Warn("failed to send attachments by file id, sending local copies")
+				err = b.sendMediaGroup(chatID, chunk, true)
+			}
+			if err != nil {
+				logger.WithError(err).Error("failed to send attachments")
+				return errorReponse
+			}
+		}
+	}
+	return ""
+}
+
+// sendMediaGroup sends up to mediaGroupLimit files of the same kind, a single file is sent on its own.
+func (b *Bot) sendMediaGroup(chatID int64, attachments []domain.Attachment, local bool) error {
+	file := func(attachment domain.Attachment) tgbotapi.RequestFileData {
+		if local && attachment.LocalPath != "" {
+			return tgbotapi.FilePath(attachment.LocalPath)
+		}
+		return tgbotapi.FileID(attachment.FileID)
+	}
+
+	if len(attachments) == 1 {
+		var msg tgbotapi.Chattable = tgbotapi.NewDocument(chatID, file(attachments[0]))
+		if attachments[0].Kind == domain.PhotoAttachment {
+			msg = tgbotapi.NewPhoto(chatID, file(attachments[0]))
+		}
+		if _, err := b.bot.Send(msg); err != nil {
+			return fmt.Errorf("b.bot.Send (%d): %w", chatID, err)
+		}
+		return nil
+	}
+
+	media := make([]any, 0, len(attachments))
+	for _, attachment := range attachments {
+		if attachment.Kind == domain.PhotoAttachment {
+			media = append(media, tgbotapi.NewInputMediaPhoto(file(attachment)))
+		} else {
+			media = append(media, tgbotapi.NewInputMediaDocument(file(attachment)))
+		}
+	}
+	if _, err := b.bot.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media)); err != nil {
+		return fmt.Errorf("b.bot.SendMediaGroup (%d): %w", chatID, err)
+	}
+	return nil
+}
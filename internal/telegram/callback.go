@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// callback data is "<action>:<payload>", payload format depends on the action
+const (
+	checklistCallback = "check"
+	searchCallback    = "search"
+	commentsCallback  = "comments"
+	filesCallback     = "files"
+)
+
+func (b *Bot) handleCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	logger := b.logger.WithField("chatID", query.From.ID).WithField("callback", query.Data)
+	if query.Message == nil || !b.checkChatAccess(ctx, query.Message) {
+		return
+	}
+
+	var answer string
+	action, payload, _ := strings.Cut(query.Data, ":")
+	switch action {
+	case checklistCallback:
+		answer = b.handleChecklistCallback(ctx, logger, query.Message, payload)
+	case searchCallback:
+		answer = b.handleSearchCallback(ctx, logger, query.Message, payload)
+	case commentsCallback:
+		answer = b.handleCommentsCallback(ctx, logger, query.Message, payload)
+	case filesCallback:
+		answer = b.handleFilesCallback(ctx, logger, query.Message, payload)
+	default:
+		answer = unknownCommandText
+	}
+
+	if _, err := b.bot.Request(tgbotapi.NewCallback(query.ID, answer)); err != nil {
+		logger.WithError(err).Error("failed to answer callback")
+	}
+}
+
+// callbackTask returns the task of the active team with number from the payload if the chat has a role in the team,
+// otherwise it returns text of the answer to callback.
+func (b *Bot) callbackTask(ctx context.Context, logger *log.Entry, chatID int64, payload string) (domain.Task, string) {
+	taskID, err := strconv.Atoi(payload)
+	if err != nil {
+		return domain.Task{}, errorReponse
+	}
+
+	teamID, err := b.activeTeamID(ctx, chatID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		return domain.Task{}, errorReponse
+	}
+	role, err := b.storage.GetRole(ctx, teamID, chatID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get role")
+		return domain.Task{}, errorReponse
+	}
+	if role == domain.UnknownRole {
+		return domain.Task{}, unknownCommandText
+	}
+	task, err := b.storage.GetTask(ctx, teamID, taskID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.Task{}, fmt.Sprintf("Задача с номером %d не найдена", taskID)
+		}
+		logger.WithError(err).Error("failed to get task")
+		return domain.Task{}, errorReponse
+	}
+	return task, ""
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"tasks_bot/internal/domain"
 	"tasks_bot/internal/errs"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -14,6 +16,11 @@ import (
 func (b *Bot) handleStart(ctx context.Context, message *tgbotapi.Message) {
 	logger := b.logger.WithField("chatID", message.Chat.ID)
 
+	// the chat which blocked the bot is notified again after /start
+	if err := b.storage.SetChatActive(ctx, message.Chat.ID); err != nil {
+		logger.WithError(err).Error("failed to set chat active")
+	}
+
 	msg := tgbotapi.NewMessage(message.Chat.ID, startMessage)
 	msg.ParseMode = tgbotapi.ModeHTML
 	if _, err := b.bot.Send(msg); err != nil {
@@ -21,7 +28,16 @@ func (b *Bot) handleStart(ctx context.Context, message *tgbotapi.Message) {
 		return
 	}
 
-	role, err := b.storage.GetRole(ctx, message.Chat.ID)
+	if code := strings.TrimSpace(message.CommandArguments()); code != "" {
+		b.redeemInvite(ctx, message, code)
+	}
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		return
+	}
+	role, err := b.storage.GetRole(ctx, teamID, message.Chat.ID)
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to get role")
 		return
@@ -35,6 +51,20 @@ func (b *Bot) handleStart(ctx context.Context, message *tgbotapi.Message) {
 	}
 }
 
+func (b *Bot) handleHealthCommand(_ context.Context, message *tgbotapi.Message) {
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Status Ok!")
+	if _, err := b.bot.Send(msg); err != nil {
+		b.logger.WithError(err).WithField("chatID", message.Chat.ID).Error("unable to send response")
+	}
+}
+
+func (b *Bot) handleDebugCommand(_ context.Context, message *tgbotapi.Message) {
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.debugCommand(message))
+	if _, err := b.bot.Send(msg); err != nil {
+		b.logger.WithError(err).WithField("chatID", message.Chat.ID).Error("unable to send response")
+	}
+}
+
 func (b *Bot) debugCommand(message *tgbotapi.Message) string {
 	storageDump, err := b.storage.DebugStorage(b.logger.Context)
 	if err != nil {
@@ -68,6 +98,28 @@ func (b *Bot) handleBecomeCommand(ctx context.Context, message *tgbotapi.Message
 		}
 	}()
 
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		b.logger.WithError(err).Error("b.activeTeamID")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if teamID != domain.DefaultTeamID {
+		responseMsg.Text = passwordTeamText
+		return
+	}
+
+	attempts, err := b.storage.GetPasswordAttempts(ctx, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		b.logger.WithError(err).Error("b.storage.GetPasswordAttempts")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if attempts.IsLocked(time.Now()) {
+		responseMsg.Text = lockedOutText(attempts)
+		return
+	}
+
 	if err := b.storage.SetStage(ctx, message.Chat.ID, stage); err != nil && !errors.Is(err, errs.ErrNotFound) {
 		b.logger.WithError(err).Error("b.storage.SetStage: %w", err)
 		responseMsg.Text = errorReponse
@@ -76,7 +128,7 @@ func (b *Bot) handleBecomeCommand(ctx context.Context, message *tgbotapi.Message
 	responseMsg.Text = "Введите пароль для идентификации"
 }
 
-func (b *Bot) handleGetRoleCommand(_ context.Context, message *tgbotapi.Message) {
+func (b *Bot) handleGetRoleCommand(ctx context.Context, message *tgbotapi.Message) {
 	logger := b.logger.WithField("chatID", message.Chat.ID)
 
 	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
@@ -87,7 +139,13 @@ func (b *Bot) handleGetRoleCommand(_ context.Context, message *tgbotapi.Message)
 		}
 	}()
 
-	role, err := b.storage.GetRole(context.Background(), message.Chat.ID)
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	role, err := b.storage.GetRole(ctx, teamID, message.Chat.ID)
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		responseMsg.Text = errorReponse
 		return
@@ -106,7 +164,7 @@ func (b *Bot) handleGetAllTasksCommand(ctx context.Context, message *tgbotapi.Me
 		}
 	}()
 
-	tasks, err := b.storage.GetAllTasks(ctx)
+	tasks, err := b.visibleTasks(ctx, message.Chat.ID, b.storage.GetAllTasks, nil)
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to get all tasks")
 		responseMsg.Text = errorReponse
@@ -137,7 +195,7 @@ func (b *Bot) handleGetOpenTasksCommand(ctx context.Context, message *tgbotapi.M
 		}
 	}()
 
-	tasks, err := b.storage.GetOpenTasks(ctx)
+	tasks, err := b.visibleTasks(ctx, message.Chat.ID, b.storage.GetOpenTasks, func(task domain.Task) bool { return !task.Closed })
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to get open tasks")
 		responseMsg.Text = errorReponse
@@ -168,7 +226,7 @@ func (b *Bot) handleGetClosedTasksCommand(ctx context.Context, message *tgbotapi
 		}
 	}()
 
-	tasks, err := b.storage.GetClosedTasks(ctx)
+	tasks, err := b.visibleTasks(ctx, message.Chat.ID, b.storage.GetClosedTasks, func(task domain.Task) bool { return task.Closed })
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to get closed tasks")
 		responseMsg.Text = errorReponse
@@ -199,7 +257,7 @@ func (b *Bot) handleGetDoneTasksCommand(ctx context.Context, message *tgbotapi.M
 		}
 	}()
 
-	tasks, err := b.storage.GetDoneTasks(ctx)
+	tasks, err := b.visibleTasks(ctx, message.Chat.ID, b.storage.GetDoneTasks, func(task domain.Task) bool { return task.Done })
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to get done tasks")
 		responseMsg.Text = errorReponse
@@ -230,7 +288,7 @@ func (b *Bot) handleGetExpiredTasksCommand(ctx context.Context, message *tgbotap
 		}
 	}()
 
-	tasks, err := b.storage.GetExpiredTasks(ctx)
+	tasks, err := b.visibleTasks(ctx, message.Chat.ID, b.storage.GetExpiredTasks, func(task domain.Task) bool { return task.Expired })
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to get expired tasks")
 		responseMsg.Text = errorReponse
@@ -261,19 +319,41 @@ func (b *Bot) handleGetSelfTasksCommand(ctx context.Context, message *tgbotapi.M
 		}
 	}()
 
-	// chat, err := b.storage.GetChat(ctx, message.Chat.UserName, "")
-	// if err != nil {
-	// 	logger.WithError(err).Error("failed to get user's chat")
-	// 	responseMsg.Text = errorReponse
-	// 	return
-	// }
+	chat, err := b.storage.GetChatByID(ctx, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get user's chat")
+		responseMsg.Text = errorReponse
+		return
+	}
+	phone, teamID := "", domain.DefaultTeamID
+	if chat != nil {
+		phone, teamID = chat.Phone, chat.TeamID
+	}
 
-	tasks, err := b.storage.GetUserTasks(ctx, message.Chat.UserName, "")
+	tasks, err := b.storage.GetUserTasks(ctx, teamID, message.Chat.UserName, phone)
 	if err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to get user's tasks")
 		responseMsg.Text = errorReponse
 		return
 	}
+	// tasks where the user is one of co-executors
+	participantTasks, err := b.storage.GetParticipantTasks(ctx, teamID, message.Chat.ID, domain.ExecutorParticipant)
+	if err != nil {
+		logger.WithError(err).Error("failed to get participant's tasks")
+		responseMsg.Text = errorReponse
+		return
+	}
+	for _, task := range participantTasks {
+		if !slices.ContainsFunc(tasks, func(t domain.Task) bool { return t.ID == task.ID }) {
+			tasks = append(tasks, task)
+		}
+	}
+	if err := b.fillTaskDetails(ctx, tasks); err != nil {
+		logger.WithError(err).Error("failed to get tasks progress")
+		responseMsg.Text = errorReponse
+		return
+	}
+	domain.SortByPriority(tasks)
 
 	if len(tasks) == 0 {
 		responseMsg.Text = "У вас пока нет задач"
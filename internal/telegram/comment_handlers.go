@@ -0,0 +1,160 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const commentUsage = `Использование: /comment номер_задачи текст
+Также можно ответить на уведомление о задаче. Ответы "готово", "закрыть", "перенеси на пятницу" и "файлы" меняют задачу без её номера`
+
+// commentAuthor returns the name shown with comments of the message sender.
+func commentAuthor(message *tgbotapi.Message) string {
+	if message.From == nil {
+		return message.Chat.Title
+	}
+	if message.From.UserName != "" {
+		return "@" + message.From.UserName
+	}
+	return strings.TrimSpace(message.From.FirstName + " " + message.From.LastName)
+}
+
+// addComment stores the comment of the message sender and forwards it to the task participants.
+// Returns text of the response.
+func (b *Bot) addComment(ctx context.Context, logger *log.Entry, message *tgbotapi.Message, task domain.Task, text string) string {
+	comment := domain.Comment{
+		TaskID:    task.ID,
+		ChatID:    message.Chat.ID,
+		Author:    commentAuthor(message),
+		Text:      text,
+		CreatedAt: time.Now(),
+	}
+	id, err := b.storage.AddComment(ctx, comment)
+	if err != nil {
+		logger.WithError(err).Error("failed to add comment")
+		return errorReponse
+	}
+	comment.ID = id
+
+	if err := b.NotifyComment(ctx, task, comment); err != nil {
+		logger.WithError(err).Error("failed to notify about comment")
+	}
+	return fmt.Sprintf("Комментарий к задаче №%d добавлен", task.ID)
+}
+
+// handleCommentCommand adds the comment to the task: /comment task text.
+func (b *Bot) handleCommentCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	rawTaskID, text, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	text = strings.TrimSpace(text)
+	if rawTaskID == "" || text == "" {
+		responseMsg.Text = commentUsage
+		return
+	}
+	task, errText := b.findTask(ctx, logger, message.Chat.ID, rawTaskID)
+	if errText != "" {
+		responseMsg.Text = errText
+		return
+	}
+	responseMsg.Text = b.addComment(ctx, logger, message, task, text)
+}
+
+// handleTaskReply runs the action of the reply to the task notification, like "готово" or "перенеси на пятницу",
+// other replies are added as comments.
+// Returns false when the message is not a reply to a task notification.
+func (b *Bot) handleTaskReply(ctx context.Context, message *tgbotapi.Message) bool {
+	if message.ReplyToMessage == nil || message.Text == "" || message.IsCommand() {
+		return false
+	}
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	teamID, taskID, err := b.storage.GetMessageTask(ctx, message.Chat.ID, message.ReplyToMessage.MessageID)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to get task of the message")
+		}
+		return false
+	}
+
+	task, errText := b.messageTask(ctx, logger, message.Chat.ID, teamID, taskID)
+	if errText == "" && b.handleReplyAction(ctx, logger, message, task) {
+		return true
+	}
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	if errText != "" {
+		responseMsg.Text = errText
+		return true
+	}
+	// the reply is the same as /comment, so it is allowed to the same roles
+	role, err := b.storage.GetRole(ctx, task.TeamID, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get role")
+		responseMsg.Text = errorReponse
+		return true
+	}
+	if !b.commands.allowed(commentCmd, role) {
+		responseMsg.Text = unknownCommandText
+		return true
+	}
+	responseMsg.Text = b.addComment(ctx, logger, message, task, message.Text)
+	return true
+}
+
+// handleCommentsCallback sends the comments of the task, payload is the task number.
+// Returns text of the answer to callback.
+func (b *Bot) handleCommentsCallback(ctx context.Context, logger *log.Entry, message *tgbotapi.Message, payload string) string {
+	task, text := b.callbackTask(ctx, logger, message.Chat.ID, payload)
+	if text != "" {
+		return text
+	}
+
+	comments, err := b.storage.GetComments(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get comments")
+		return errorReponse
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("💬 Комментарии к задаче №%d %s", task.ID, html.EscapeString(task.Title)))
+	if len(comments) == 0 {
+		builder.WriteString(fmt.Sprintf("\n\nКомментариев пока нет, добавить: /%s %d текст", commentCmd, task.ID))
+	}
+	for _, comment := range comments {
+		builder.WriteString("\n\n")
+		builder.WriteString(comment.String())
+	}
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get task keyboard")
+		return errorReponse
+	}
+	if err := b.sendTaskMessage(ctx, message.Chat.ID, task.ID, builder.String(), keyboard); err != nil {
+		logger.WithError(err).Error("failed to send comments")
+		return errorReponse
+	}
+	return fmt.Sprintf("Комментариев: %d", len(comments))
+}
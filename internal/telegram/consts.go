@@ -1,11 +1,5 @@
 package telegram
 
-import (
-	"tasks_bot/internal/domain"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-)
-
 const (
 	startCmd                  = "start"
 	becomeExecutorCmd         = "become_executor"
@@ -13,6 +7,8 @@ const (
 	becomeChiefCmd            = "become_chief"
 	becomeAdminCmd            = "become_admin"
 	getRoleCmd                = "get_role"
+	teamCmd                   = "team"
+	subordinatesCmd           = "subordinates"
 	addTaskCmd                = "add_task"
 	getAllTasksCmd            = "get_all_tasks"
 	getOpenTasks              = "get_open_tasks"
@@ -24,68 +20,46 @@ const (
 	markTaskAsClosedCommand   = "close_task"
 	deleteTaskCommand         = "delete_task"
 	changeTaskDeadlineCommand = "change_deadline"
+	addParticipantCmd         = "add_participant"
+	removeParticipantCmd      = "remove_participant"
+	participantsCmd           = "participants"
+	setParentCmd              = "set_parent"
+	subtasksCmd               = "subtasks"
+	addChecklistItemCmd       = "add_item"
+	checklistCmd              = "checklist"
+	addBlockerCmd             = "add_blocker"
+	removeBlockerCmd          = "remove_blocker"
+	addRecurrenceCmd          = "add_recurrence"
+	recurrencesCmd            = "recurrences"
+	pauseRecurrenceCmd        = "pause_recurrence"
+	resumeRecurrenceCmd       = "resume_recurrence"
+	deleteRecurrenceCmd       = "delete_recurrence"
+	templatesCmd              = "templates"
+	tasksCmd                  = "tasks"
+	viewCmd                   = "view"
+	setTagsCmd                = "set_tags"
+	searchCmd                 = "search"
+	commentCmd                = "comment"
+	attachCmd                 = "attach"
+	filesCmd                  = "files"
+	requireProofCmd           = "require_proof"
+	proofsCmd                 = "proofs"
+	dashboardCmd              = "dashboard"
 	// admin commands
-	healthCmd    = "healthz"
-	debugStorage = "debug"
+	healthCmd       = "healthz"
+	debugStorage    = "debug"
+	inviteCmd       = "invite"
+	listInvitesCmd  = "invites"
+	revokeInviteCmd = "revoke_invite"
+	usersCmd        = "users"
+	setRoleCmd      = "set_role"
+	revokeRoleCmd   = "revoke_role"
+	banCmd          = "ban"
+	unbanCmd        = "unban"
+	resetStageCmd   = "reset_stage"
+	auditCmd        = "audit"
+	lockoutsCmd     = "lockouts"
+	unlockCmd       = "unlock"
+	createTeamCmd   = "create_team"
+	setChiefCmd     = "set_chief"
 )
-
-var role2commands = map[domain.Role][]tgbotapi.BotCommand{
-	domain.UnknownRole: {
-		{Command: startCmd, Description: "Начать"},
-		{Command: getRoleCmd, Description: "Узнать свою роль"},
-		{Command: becomeExecutorCmd, Description: "Стать исполнителем"},
-		{Command: becomeChiefCmd, Description: "Стать шефом"},
-		{Command: becomeObserverCmd, Description: "Стать наблюдателем"},
-	},
-	domain.Executor: {
-		{Command: getRoleCmd, Description: "Узнать свою роль"},
-		{Command: getSelfTasksCmd, Description: "Получить свои задачи"},
-		{Command: becomeChiefCmd, Description: "Стать шефом"},
-		{Command: becomeObserverCmd, Description: "Стать наблюдателем"},
-	},
-	domain.Chief: {
-		{Command: getRoleCmd, Description: "Узнать свою роль"},
-		{Command: addTaskCmd, Description: "Добавить задачу"},
-		{Command: getAllTasksCmd, Description: "Получить все задачи"},
-		{Command: getExpiredTasksCmd, Description: "Получить просроченные задачи"},
-		{Command: getOpenTasks, Description: "Получить открытые задачи"},
-		{Command: getDoneTasks, Description: "Получить выполненные задачи"},
-		{Command: markTaskAsDoneCommand, Description: "Отметить задачу выполненной"},
-		{Command: changeTaskDeadlineCommand, Description: "Изменить дедлайн задачи"},
-		{Command: becomeExecutorCmd, Description: "Стать исполнителем"},
-		{Command: becomeObserverCmd, Description: "Стать наблюдателем"},
-	},
-	domain.Observer: {
-		{Command: getRoleCmd, Description: "Узнать свою роль"},
-		{Command: addTaskCmd, Description: "Добавить задачу"},
-		{Command: getAllTasksCmd, Description: "Получить все задачи"},
-		{Command: getExpiredTasksCmd, Description: "Получить просроченные задачи"},
-		{Command: getOpenTasks, Description: "Получить открытые задачи"},
-		{Command: getDoneTasks, Description: "Получить выполненные задачи"},
-		{Command: getClosedTasks, Description: "Получить закрытые задачи"},
-		{Command: markTaskAsClosedCommand, Description: "Закрыть задачу"},
-		{Command: deleteTaskCommand, Description: "Удалить задачу"},
-		{Command: markTaskAsDoneCommand, Description: "Отметить задачу выполненной"},
-		{Command: changeTaskDeadlineCommand, Description: "Изменить дедлайн задачи"},
-		{Command: becomeExecutorCmd, Description: "Стать исполнителем"},
-		{Command: becomeChiefCmd, Description: "Стать шефом"},
-	},
-	domain.Admin: {
-		{Command: getRoleCmd, Description: "Узнать свою роль"},
-		{Command: addTaskCmd, Description: "Добавить задачу"},
-		{Command: getAllTasksCmd, Description: "Получить все задачи"},
-		{Command: getExpiredTasksCmd, Description: "Получить просроченные задачи"},
-		{Command: getOpenTasks, Description: "Получить открытые задачи"},
-		{Command: getDoneTasks, Description: "Получить выполненные задачи"},
-		{Command: getClosedTasks, Description: "Получить закрытые задачи"},
-		{Command: markTaskAsClosedCommand, Description: "Закрыть задачу"},
-		{Command: deleteTaskCommand, Description: "Удалить задачу"},
-		{Command: markTaskAsDoneCommand, Description: "Отметить задачу выполненной"},
-		{Command: changeTaskDeadlineCommand, Description: "Изменить дедлайн задачи"},
-		{Command: becomeExecutorCmd, Description: "Стать исполнителем"},
-		{Command: becomeChiefCmd, Description: "Стать шефом"},
-		{Command: becomeObserverCmd, Description: "Стать наблюдателем"},
-		{Command: healthCmd, Description: "Проверить состояние"},
-		{Command: debugStorage, Description: "Отладка хранилища"},
-	},
-}
@@ -0,0 +1,209 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const dashboardUsage = "Использование: /dashboard [off] - закрепить доску задач команды, off убирает её"
+
+// handleDashboardCommand posts and pins the dashboard of the active team, the previous dashboard of the chat
+// is unpinned: /dashboard [off].
+func (b *Bot) handleDashboardCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		// the dashboard itself is the response
+		if responseMsg.Text == "" {
+			return
+		}
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	enabled := true
+	if args := message.CommandArguments(); args != "" {
+		value, err := parseSwitch(args)
+		if err != nil {
+			responseMsg.Text = dashboardUsage
+			return
+		}
+		enabled = value
+	}
+
+	previous, err := b.chatDashboard(ctx, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get dashboard")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if previous.MessageID != 0 {
+		unpin := tgbotapi.UnpinChatMessageConfig{ChatID: message.Chat.ID, MessageID: previous.MessageID}
+		if _, err := b.bot.Request(unpin); err != nil {
+			logger.WithError(err).Warn("failed to unpin previous dashboard")
+		}
+	}
+
+	if !enabled {
+		if err := b.storage.DeleteDashboard(ctx, message.Chat.ID); err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				responseMsg.Text = "Доска задач не закреплена"
+				return
+			}
+			logger.WithError(err).Error("failed to delete dashboard")
+			responseMsg.Text = errorReponse
+			return
+		}
+		responseMsg.Text = "Доска задач больше не обновляется"
+		return
+	}
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	role, err := b.storage.GetRole(ctx, teamID, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get role")
+		responseMsg.Text = errorReponse
+		return
+	}
+	dashboard := domain.Dashboard{ChatID: message.Chat.ID, TeamID: teamID}
+	text, err := b.dashboardText(ctx, dashboard, role)
+	if err != nil {
+		logger.WithError(err).Error("failed to build dashboard")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	sent, err := b.bot.Send(msg)
+	if err != nil {
+		logger.WithError(err).Error("failed to send dashboard")
+		return
+	}
+	dashboard.MessageID = sent.MessageID
+	if err := b.storage.SaveDashboard(ctx, dashboard); err != nil {
+		logger.WithError(err).Error("failed to save dashboard")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	pin := tgbotapi.PinChatMessageConfig{ChatID: message.Chat.ID, MessageID: sent.MessageID, DisableNotification: true}
+	if _, err := b.bot.Request(pin); err != nil {
+		// in groups the bot needs the right to pin messages, the dashboard is updated anyway
+		logger.WithError(err).Warn("failed to pin dashboard")
+		responseMsg.Text = "Не удалось закрепить доску задач, дайте боту право закреплять сообщения. Доска всё равно будет обновляться"
+	}
+}
+
+// chatDashboard returns the dashboard of the chat or errs.ErrNotFound.
+func (b *Bot) chatDashboard(ctx context.Context, chatID int64) (domain.Dashboard, error) {
+	dashboards, err := b.storage.GetDashboards(ctx)
+	if err != nil {
+		return domain.Dashboard{}, fmt.Errorf("b.storage.GetDashboards: %w", err)
+	}
+	for _, dashboard := range dashboards {
+		if dashboard.ChatID == chatID {
+			return dashboard, nil
+		}
+	}
+	return domain.Dashboard{}, errs.ErrNotFound
+}
+
+// dashboardText builds the dashboard of the tasks the role sees in the team of the dashboard,
+// chiefs see tasks of their subordinates only.
+func (b *Bot) dashboardText(ctx context.Context, dashboard domain.Dashboard, role domain.Role) (string, error) {
+	var tasks []domain.Task
+	var err error
+	if role == domain.Chief {
+		tasks, err = b.storage.GetSubordinatesTasks(ctx, dashboard.TeamID, dashboard.ChatID)
+	} else {
+		tasks, err = b.storage.GetAllTasks(ctx, dashboard.TeamID)
+	}
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return "", fmt.Errorf("b.storage.GetTasks: %w", err)
+	}
+	return domain.DashboardText(tasks, time.Now()), nil
+}
+
+// RefreshDashboards edits dashboards of all chats, so time based groups like overdue tasks stay current.
+func (b *Bot) RefreshDashboards(ctx context.Context) error {
+	return b.refreshDashboards(ctx, func(domain.Dashboard) bool { return true })
+}
+
+// taskChanged is called after every change of tasks of the team, including deletion.
+// It keeps views built from the tasks of the team, e.g. dashboards, current.
+func (b *Bot) taskChanged(ctx context.Context, teamID int64) {
+	if err := b.refreshTeamDashboards(ctx, teamID); err != nil {
+		b.logger.WithError(err).WithField("teamID", teamID).Warn("failed to refresh dashboards")
+	}
+}
+
+// refreshTeamDashboards edits dashboards of the team after its tasks change.
+func (b *Bot) refreshTeamDashboards(ctx context.Context, teamID int64) error {
+	return b.refreshDashboards(ctx, func(dashboard domain.Dashboard) bool { return dashboard.TeamID == teamID })
+}
+
+func (b *Bot) refreshDashboards(ctx context.Context, keep func(domain.Dashboard) bool) error {
+	dashboards, err := b.storage.GetDashboards(ctx)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetDashboards: %w", err)
+	}
+	for _, dashboard := range dashboards {
+		if !keep(dashboard) {
+			continue
+		}
+		// one failed dashboard, e.g. in the chat which blocked the bot, doesn't stop the others
+		if err := b.updateDashboard(ctx, dashboard); err != nil {
+			b.logger.WithError(err).WithField("chatID", dashboard.ChatID).Warn("failed to update dashboard")
+		}
+	}
+	return nil
+}
+
+// updateDashboard edits the dashboard message, the dashboard is forgotten when the message is gone
+// or the chat has lost the role which allows the dashboard.
+func (b *Bot) updateDashboard(ctx context.Context, dashboard domain.Dashboard) error {
+	role, err := b.storage.GetRole(ctx, dashboard.TeamID, dashboard.ChatID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return fmt.Errorf("b.storage.GetRole: %w", err)
+	}
+	if !b.commands.allowed(dashboardCmd, role) {
+		return b.forgetDashboard(ctx, dashboard)
+	}
+	text, err := b.dashboardText(ctx, dashboard, role)
+	if err != nil {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(dashboard.ChatID, dashboard.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeHTML
+	edited, err := b.editMessage(edit)
+	if err != nil {
+		return err
+	}
+	if !edited {
+		return b.forgetDashboard(ctx, dashboard)
+	}
+	return nil
+}
+
+func (b *Bot) forgetDashboard(ctx context.Context, dashboard domain.Dashboard) error {
+	b.logger.WithField("chatID", dashboard.ChatID).Info("dashboard is gone, it is not updated anymore")
+	if err := b.storage.DeleteDashboard(ctx, dashboard.ChatID); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return fmt.Errorf("b.storage.DeleteDashboard: %w", err)
+	}
+	return nil
+}
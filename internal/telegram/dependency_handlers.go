@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleAddBlockerCommand declares that the task can't be started until another one is finished: /add_blocker task blocker.
+func (b *Bot) handleAddBlockerCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		responseMsg.Text = "Использование: /add_blocker номер_задачи номер_блокирующей_задачи"
+		return
+	}
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, args[0])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	blocker, text := b.findTask(ctx, logger, message.Chat.ID, args[1])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	if task.ID == blocker.ID {
+		responseMsg.Text = "Задача не может блокировать сама себя"
+		return
+	}
+
+	// walk through everything the blocker depends on to be sure the task is not among it
+	visited := []int{blocker.ID}
+	for queue := []int{blocker.ID}; len(queue) > 0; {
+		if slices.Contains(queue, task.ID) {
+			responseMsg.Text = fmt.Sprintf("Задача №%d уже зависит от задачи №%d, зависимость образует цикл", blocker.ID, task.ID)
+			return
+		}
+		blockers, err := b.storage.GetBlockers(ctx, queue)
+		if err != nil {
+			logger.WithError(err).Error("failed to get blockers")
+			responseMsg.Text = errorReponse
+			return
+		}
+		queue = queue[:0]
+		for _, upstream := range blockers {
+			for _, upstreamTask := range upstream {
+				if !slices.Contains(visited, upstreamTask.ID) {
+					visited = append(visited, upstreamTask.ID)
+					queue = append(queue, upstreamTask.ID)
+				}
+			}
+		}
+	}
+
+	if err := b.storage.AddTaskDependency(ctx, task.ID, blocker.ID); err != nil {
+		logger.WithError(err).Error("failed to add task dependency")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	responseMsg.Text = fmt.Sprintf("Задача №%d теперь ожидает выполнения задачи №%d", task.ID, blocker.ID)
+	if blocker.IsFinished() {
+		responseMsg.Text += fmt.Sprintf(", но она уже %s", blocker.GetStatus())
+	}
+}
+
+func (b *Bot) handleRemoveBlockerCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		responseMsg.Text = "Использование: /remove_blocker номер_задачи номер_блокирующей_задачи"
+		return
+	}
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, args[0])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	blocker, text := b.findTask(ctx, logger, message.Chat.ID, args[1])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	if err := b.storage.RemoveTaskDependency(ctx, task.ID, blocker.ID); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = fmt.Sprintf("Задача №%d не зависит от задачи №%d", task.ID, blocker.ID)
+			return
+		}
+		logger.WithError(err).Error("failed to remove task dependency")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	responseMsg.Text = fmt.Sprintf("Задача №%d больше не ожидает задачу №%d", task.ID, blocker.ID)
+}
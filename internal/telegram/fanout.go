@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// fanOut sends the notification to every recipient, a failed recipient doesn't stop the others.
+// Chats which blocked the bot or don't exist anymore are marked inactive and skipped until they /start the bot again.
+// Returns errors of the failed recipients joined.
+func (b *Bot) fanOut(ctx context.Context, event string, recipients []int64, send func(chatID int64) error) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	inactive, err := b.storage.GetInactiveChats(ctx)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetInactiveChats: %w", err)
+	}
+
+	var sent, skipped, deactivated int
+	var failures []error
+	for _, chatID := range recipients {
+		if _, ok := inactive[chatID]; ok {
+			skipped++
+			continue
+		}
+		err := send(chatID)
+		if err == nil {
+			sent++
+			continue
+		}
+		if reason, ok := unreachableChat(err); ok {
+			deactivated++
+			b.logger.WithField("chatID", chatID).WithField("reason", reason).Info("chat is unreachable, it is not notified until /start")
+			if err := b.storage.SetChatInactive(ctx, chatID, reason); err != nil {
+				failures = append(failures, fmt.Errorf("b.storage.SetChatInactive (%d): %w", chatID, err))
+			}
+			continue
+		}
+		failures = append(failures, fmt.Errorf("chat %d: %w", chatID, err))
+	}
+
+	logger := b.logger.WithFields(log.Fields{
+		"event":       event,
+		"recipients":  len(recipients),
+		"sent":        sent,
+		"skipped":     skipped,
+		"deactivated": deactivated,
+		"failed":      len(failures),
+	})
+	if len(failures) > 0 {
+		logger.Warn("notification is not delivered to some chats")
+	} else {
+		logger.Info("notification is delivered")
+	}
+	return errors.Join(failures...)
+}
+
+// unreachableChat reports whether the request failed because the chat blocked the bot or doesn't exist,
+// such chats get nothing until they /start the bot again. Returns the reason given by Telegram.
+func unreachableChat(err error) (string, bool) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return "", false
+	}
+	if apiErr.Code == 403 || strings.Contains(apiErr.Message, "chat not found") {
+		return apiErr.Message, true
+	}
+	return "", false
+}
@@ -0,0 +1,199 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const noChief = "-"
+
+// visibleTasks returns tasks of the active team the chat may see with their progress, the most important first.
+// Observers and admins get everything from list, chiefs get only tasks of their subordinates matching keep (nil keeps all of them).
+func (b *Bot) visibleTasks(
+	ctx context.Context,
+	chatID int64,
+	list func(ctx context.Context, teamID int64) ([]domain.Task, error),
+	keep func(task domain.Task) bool,
+) ([]domain.Task, error) {
+	teamID, err := b.activeTeamID(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("b.activeTeamID: %w", err)
+	}
+	role, err := b.storage.GetRole(ctx, teamID, chatID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return nil, fmt.Errorf("b.storage.GetRole: %w", err)
+	}
+
+	var visible []domain.Task
+	if role != domain.Chief {
+		if visible, err = list(ctx, teamID); err != nil {
+			return nil, err
+		}
+	} else {
+		tasks, err := b.storage.GetSubordinatesTasks(ctx, teamID, chatID)
+		if err != nil {
+			return nil, fmt.Errorf("b.storage.GetSubordinatesTasks: %w", err)
+		}
+		for _, task := range tasks {
+			if keep == nil || keep(task) {
+				visible = append(visible, task)
+			}
+		}
+	}
+
+	if err := b.fillTaskDetails(ctx, visible); err != nil {
+		return nil, fmt.Errorf("b.fillTaskDetails: %w", err)
+	}
+	domain.SortByPriority(visible)
+	return visible, nil
+}
+
+func (b *Bot) handleSubordinatesCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	subordinates, err := b.storage.GetSubordinates(ctx, teamID, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get subordinates")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if len(subordinates) == 0 {
+		responseMsg.Text = "За вами пока не закреплены исполнители"
+		return
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("Ваши исполнители:\n")
+	for _, subordinate := range subordinates {
+		builder.WriteString(fmt.Sprintf("\n%s", subordinate.Mention()))
+	}
+	responseMsg.Text = builder.String()
+}
+
+// handleSetChiefCommand moves executor to another chief of the team: /set_chief executor chief|-.
+func (b *Bot) handleSetChiefCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		responseMsg.Text = setChiefUsage
+		return
+	}
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	executor, err := b.findTeamMember(ctx, teamID, args[0], domain.Executor)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) && !errors.Is(err, errs.ErrInvalidInput) {
+			logger.WithError(err).Error("failed to find executor")
+		}
+		responseMsg.Text = teamMemberErrorText(args[0], domain.Executor, err)
+		return
+	}
+	var chief *domain.Chat
+	if args[1] != noChief {
+		if chief, err = b.findTeamMember(ctx, teamID, args[1], domain.Chief); err != nil {
+			if !errors.Is(err, errs.ErrNotFound) && !errors.Is(err, errs.ErrInvalidInput) {
+				logger.WithError(err).Error("failed to find chief")
+			}
+			responseMsg.Text = teamMemberErrorText(args[1], domain.Chief, err)
+			return
+		}
+	}
+
+	var chiefID int64
+	details := noChief
+	if chief != nil {
+		chiefID, details = chief.ID, strconv.FormatInt(chief.ID, 10)
+	}
+	if err := b.storage.SetManager(ctx, teamID, executor.ID, chiefID); err != nil {
+		logger.WithError(err).Error("failed to set manager")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	action := domain.AdminAction{
+		TeamID:       teamID,
+		AdminChatID:  message.Chat.ID,
+		TargetChatID: executor.ID,
+		Action:       domain.SetChiefAction,
+		Details:      details,
+	}
+	if err := b.storage.AddAdminAction(ctx, action); err != nil {
+		logger.WithError(err).Error("failed to record admin action")
+	}
+
+	if chief == nil {
+		responseMsg.Text = fmt.Sprintf("Исполнитель %s откреплён от шефа", executor.Mention())
+		return
+	}
+	responseMsg.Text = fmt.Sprintf("Исполнитель %s закреплён за шефом %s", executor.Mention(), chief.Mention())
+
+	msg := tgbotapi.NewMessage(chief.ID, fmt.Sprintf("За вами закреплён исполнитель %s", executor.Mention()))
+	if _, err := b.bot.SendNotification(msg); err != nil {
+		logger.WithError(err).Error("failed to notify chief")
+	}
+}
+
+// findTeamMember looks for the chat having the role in the team.
+// It returns errs.ErrNotFound for unknown chats and errs.ErrInvalidInput when the chat has another role.
+func (b *Bot) findTeamMember(ctx context.Context, teamID int64, target string, role domain.Role) (*domain.Chat, error) {
+	chat, err := b.findChat(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("b.findChat: %w", err)
+	}
+	memberRole, err := b.storage.GetRole(ctx, teamID, chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return nil, fmt.Errorf("b.storage.GetRole: %w", err)
+	}
+	if memberRole != role {
+		return chat, errs.ErrInvalidInput
+	}
+	return chat, nil
+}
+
+func teamMemberErrorText(target string, role domain.Role, err error) string {
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		return userNotFound
+	case errors.Is(err, errs.ErrInvalidInput):
+		return fmt.Sprintf("Пользователь %s не состоит в вашей команде с ролью \"%s\"", target, role)
+	default:
+		return errorReponse
+	}
+}
+
+const setChiefUsage = "Использование: /set_chief исполнитель шеф\nЧтобы открепить исполнителя от шефа, укажите \"-\" вместо шефа"
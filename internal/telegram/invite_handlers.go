@@ -0,0 +1,236 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	inviteCodeBytes = 8
+	inviteUsageText = "Использование: /invite роль [@username] [срок действия, например 12h или 7d]\nРоли: executor, observer, chief, admin"
+)
+
+// handleInviteCommand creates single-use invite code: /invite <role> [@username] [ttl].
+func (b *Bot) handleInviteCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	invite, err := b.parseInvite(message)
+	if err != nil {
+		responseMsg.Text = inviteUsageText
+		return
+	}
+	if invite.TeamID, err = b.activeTeamID(ctx, message.Chat.ID); err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if err := b.storage.AddInvite(ctx, invite); err != nil {
+		logger.WithError(err).Error("failed to add invite")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	responseMsg.Text = fmt.Sprintf("Приглашение создано:\n\n%s\n\n<b>Ссылка:</b> %s", invite, b.inviteLink(invite.Code))
+}
+
+func (b *Bot) parseInvite(message *tgbotapi.Message) (domain.Invite, error) {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 || len(args) > 3 {
+		return domain.Invite{}, errs.ErrInvalidInput
+	}
+
+	role, err := domain.ParseRole(args[0])
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("domain.ParseRole: %w", err)
+	}
+	code, err := generateInviteCode()
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("generateInviteCode: %w", err)
+	}
+
+	invite := domain.Invite{
+		Code:      code,
+		Role:      role,
+		CreatedBy: message.Chat.ID,
+	}
+	ttl := b.cfg.InviteTTL
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			invite.Username = strings.Trim(arg, "@")
+			continue
+		}
+		if ttl, err = parseTTL(arg); err != nil {
+			return domain.Invite{}, fmt.Errorf("parseTTL: %w", err)
+		}
+	}
+	if ttl > 0 {
+		invite.ExpiresAt = time.Now().Add(ttl)
+	}
+	return invite, nil
+}
+
+// parseTTL parses duration allowing days suffix, e.g. 7d.
+func parseTTL(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid days %q: %w", raw, errs.ErrInvalidInput)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid duration %q: %w", raw, errs.ErrInvalidInput)
+	}
+	return ttl, nil
+}
+
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (b *Bot) inviteLink(code string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", b.bot.Self.UserName, code)
+}
+
+func (b *Bot) handleListInvitesCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	invites, err := b.storage.GetActiveInvites(ctx, teamID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get active invites")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if len(invites) == 0 {
+		responseMsg.Text = "Нет активных приглашений"
+		return
+	}
+
+	builder := strings.Builder{}
+	for _, invite := range invites {
+		builder.WriteString(invite.String())
+		builder.WriteString("\n\n")
+	}
+	responseMsg.Text = builder.String()
+}
+
+func (b *Bot) handleRevokeInviteCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "Приглашение отозвано")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	code := strings.TrimSpace(message.CommandArguments())
+	if code == "" {
+		responseMsg.Text = "Использование: /revoke_invite код"
+		return
+	}
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if err := b.storage.RevokeInvite(ctx, teamID, code); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = "Активное приглашение с таким кодом не найдено"
+			return
+		}
+		logger.WithError(err).Error("failed to revoke invite")
+		responseMsg.Text = errorReponse
+	}
+}
+
+// redeemInvite grants role from invite code, received with /start deep link.
+func (b *Bot) redeemInvite(ctx context.Context, message *tgbotapi.Message, code string) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	if err := b.storage.AddChat(ctx, message.Chat.ID, message.Chat.UserName, ""); err != nil {
+		logger.WithError(err).Error("failed to add chat")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	invite, err := b.storage.RedeemInvite(ctx, code, message.Chat.ID, message.Chat.UserName)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = "Код приглашения недействителен или уже использован"
+			return
+		}
+		logger.WithError(err).Error("failed to redeem invite")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if err := b.storage.SetRole(ctx, invite.TeamID, message.Chat.ID, invite.Role); err != nil {
+		logger.WithError(err).Error("failed to set role")
+		responseMsg.Text = errorReponse
+		return
+	}
+	// the invited user starts working in the team right away
+	if err := b.storage.SetActiveTeam(ctx, message.Chat.ID, invite.TeamID); err != nil {
+		logger.WithError(err).Error("failed to set active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.linkTasksByPhone(ctx, message.Chat.ID)
+	responseMsg.Text = fmt.Sprintf("Приглашение принято. Ваша роль - %s", invite.Role)
+
+	notifyMsg := tgbotapi.NewMessage(invite.CreatedBy,
+		fmt.Sprintf("Приглашение %s использовано пользователем @%s, роль - %s", invite.Code, message.Chat.UserName, invite.Role),
+	)
+	if _, err := b.bot.SendNotification(notifyMsg); err != nil {
+		logger.WithError(err).Error("failed to notify invite creator")
+	}
+}
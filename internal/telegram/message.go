@@ -17,6 +17,9 @@ const (
 // TODO add context to handlers
 func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
 	logger := b.logger.WithField("chatID", message.Chat.ID)
+	if !b.checkChatAccess(ctx, message) {
+		return
+	}
 
 	stage, err := b.storage.GetStage(ctx, message.Chat.ID)
 	if err != nil {
@@ -26,6 +29,15 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
 		}
 	}
 
+	// files answering the proof request are proofs, not attachments
+	if stage != domain.MarkTaskProof && b.handleAttachmentMessage(ctx, message, stage) {
+		return
+	}
+	// replies to task notifications are comments unless the chat is in the middle of a dialog
+	if (stage == domain.Unknown || stage == domain.Default) && b.handleTaskReply(ctx, message) {
+		return
+	}
+
 	switch stage {
 	case domain.Unknown:
 		b.handleUnknownStage(ctx, message)
@@ -39,12 +51,15 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
 	case domain.BecomeChief, domain.BecomeExecutor, domain.BecomeObserver, domain.BecomeAdmin:
 		b.handleBecomeStage(ctx, message, stage)
 
-	case domain.AddTaskName, domain.AddTaskUser, domain.AddTaskDeadline:
+	case domain.AddTaskName, domain.AddTaskUser, domain.AddTaskPriority, domain.AddTaskDeadline:
 		b.handleAddTaskStage(ctx, message, stage)
 
 	case domain.MarkTaskAsClosed, domain.MarkTaskAsDone:
 		b.handleMarkTaskStage(ctx, message, stage)
 
+	case domain.MarkTaskProof:
+		b.handleTaskProofStage(ctx, message)
+
 	case domain.DeleteTask:
 		b.handleDeleteTaskStage(ctx, message)
 
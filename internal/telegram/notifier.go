@@ -2,43 +2,421 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"maps"
 	"slices"
+	"strings"
 	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (b *Bot) NotifyObservers(ctx context.Context, task domain.Task, excludeChatIDs ...int64) error {
-	observers, err := b.storage.GetObservers(ctx)
+// NotifyTaskCreated sends new cards of the created task to observers of its team and participants of the task.
+func (b *Bot) NotifyTaskCreated(ctx context.Context, task domain.Task, excludeChatIDs ...int64) error {
+	return b.notifyTaskCards(ctx, "task created", task, fmt.Sprintf("Создана задача: \n\n%s", task.String()), true, excludeChatIDs...)
+}
+
+// NotifyTaskUpdate updates cards of the task in chats of observers of its team and participants of the task.
+// Cards are edited in place, chats without the card are not notified, the update doesn't need attention.
+func (b *Bot) NotifyTaskUpdate(ctx context.Context, task domain.Task, excludeChatIDs ...int64) error {
+	return b.notifyTaskCards(ctx, "task update", task, fmt.Sprintf("UPD: \n\n%s", task.String()), false, excludeChatIDs...)
+}
+
+// NotifyTaskExpired sends new cards of the expired task to observers of its team and participants of the task,
+// the expiry needs attention, so the cards are not edited.
+func (b *Bot) NotifyTaskExpired(ctx context.Context, task domain.Task) error {
+	return b.notifyTaskCards(ctx, "task expired", task, fmt.Sprintf("⌛ Задача просрочена: \n\n%s", task.String()), true)
+}
+
+// notifyTaskCards sends the card to observers and participants, fresh cards are sent as new messages,
+// otherwise only existing cards are edited and the card which is gone is forgotten.
+// Cards in chats which are not notified, e.g. of the author of the change, are kept up to date silently.
+func (b *Bot) notifyTaskCards(ctx context.Context, event string, task domain.Task, text string, fresh bool, excludeChatIDs ...int64) error {
+	observers, err := b.storage.GetObservers(ctx, task.TeamID)
 	if err != nil {
 		return fmt.Errorf("b.storage.GetObservers: %w", err)
 	}
+	participants, err := b.storage.GetTaskParticipants(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetTaskParticipants: %w", err)
+	}
 
+	recipients := make([]int64, 0, len(observers)+len(participants))
 	for chatID := range observers {
+		recipients = append(recipients, chatID)
+	}
+	for _, participant := range participants {
+		if _, ok := observers[participant.ChatID]; !ok {
+			recipients = append(recipients, participant.ChatID)
+		}
+	}
+
+	cards, err := b.storage.GetTaskCards(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetTaskCards: %w", err)
+	}
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	notified := make([]int64, 0, len(recipients))
+	notifiedCards := make(map[int64]int)
+	for _, chatID := range recipients {
 		if slices.Contains(excludeChatIDs, chatID) {
 			continue
 		}
-		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("UPD: \n\n%s", task.String()))
-		msg.ParseMode = tgbotapi.ModeHTML
-		if _, err = b.bot.Send(msg); err != nil {
-			return fmt.Errorf("b.bot.Send (%d): %w", msg.ChatID, err)
+		messageID, ok := cards[chatID]
+		if !ok && !fresh {
+			continue
 		}
+		if ok {
+			notifiedCards[chatID] = messageID
+			delete(cards, chatID)
+		}
+		notified = append(notified, chatID)
 	}
+	deliveryErr := b.fanOut(ctx, event, notified, func(chatID int64) error {
+		if !fresh {
+			return b.editTaskCard(ctx, chatID, task.ID, notifiedCards[chatID], text, keyboard)
+		}
+		return b.sendTaskCard(ctx, chatID, task.ID, text, keyboard)
+	})
+	for chatID, messageID := range cards {
+		if err := b.editTaskCard(ctx, chatID, task.ID, messageID, text, keyboard); err != nil {
+			b.logger.WithError(err).WithField("chatID", chatID).Warn("failed to edit task card")
+		}
+	}
+	b.taskChanged(ctx, task.TeamID)
+	return deliveryErr
+}
 
-	return nil
+// NotifyTaskDeleted edits cards of the deleted task, so they don't offer actions on the task which is gone,
+// and forgets them. Cards are read before the task is deleted, they are deleted together with it.
+func (b *Bot) NotifyTaskDeleted(ctx context.Context, task domain.Task, cards map[int64]int) error {
+	text := fmt.Sprintf("🗑 Задача №%d \"%s\" удалена", task.ID, html.EscapeString(task.Title))
+	var failures []error
+	for chatID, messageID := range cards {
+		// the edit without buttons removes them from the card
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+		edit.ParseMode = tgbotapi.ModeHTML
+		if _, err := b.editMessage(edit); err != nil {
+			failures = append(failures, err)
+		}
+		if err := b.storage.DeleteTaskCard(ctx, task.ID, chatID); err != nil {
+			failures = append(failures, fmt.Errorf("b.storage.DeleteTaskCard: %w", err))
+		}
+	}
+	b.taskChanged(ctx, task.TeamID)
+	return errors.Join(failures...)
+}
+
+// NotifyChief sends the expired task to the chief responsible for its executor, if there is one.
+func (b *Bot) NotifyChief(ctx context.Context, task domain.Task) error {
+	chiefID, err := b.storage.GetExecutorManager(ctx, task.TeamID, task.ExecutorChatID, task.ExecutorContact)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("b.storage.GetExecutorManager: %w", err)
+	}
+
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("Просрочена задача вашего исполнителя: \n\n%s", task.String())
+	return b.fanOut(ctx, "chief escalation", []int64{chiefID}, func(chatID int64) error {
+		return b.sendTaskCard(ctx, chatID, task.ID, text, keyboard)
+	})
+}
+
+// NotifyUnblocked tells executors of the tasks depending on the finished one that all their blockers are done.
+func (b *Bot) NotifyUnblocked(ctx context.Context, finished domain.Task) error {
+	dependents, err := b.storage.GetDependentTasks(ctx, finished.ID)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetDependentTasks: %w", err)
+	}
+	if len(dependents) == 0 {
+		return nil
+	}
+	taskIDs := make([]int, 0, len(dependents))
+	for _, task := range dependents {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	blockers, err := b.storage.GetBlockers(ctx, taskIDs)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetBlockers: %w", err)
+	}
+
+	var failures []error
+	for _, task := range dependents {
+		if task.IsFinished() || len(domain.OpenBlockers(blockers[task.ID])) > 0 {
+			continue
+		}
+		recipients, err := b.executorChats(ctx, task)
+		if err != nil {
+			return err
+		}
+		keyboard, err := b.taskKeyboard(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		text := fmt.Sprintf("🔓 Задача разблокирована, все блокирующие задачи завершены: \n\n%s", task.String())
+		err = b.fanOut(ctx, "task unblocked", recipients, func(chatID int64) error {
+			return b.sendTaskCard(ctx, chatID, task.ID, text, keyboard)
+		})
+		if err != nil {
+			failures = append(failures, fmt.Errorf("task %d: %w", task.ID, err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// NotifyReminder reminds executors of the open task about its deadline.
+func (b *Bot) NotifyReminder(ctx context.Context, task domain.Task) error {
+	recipients, err := b.executorChats(ctx, task)
+	if err != nil {
+		return err
+	}
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("⏰ Напоминание о задаче, дедлайн %s: \n\n%s", task.Deadline.Format(domain.DeadlineLayout), task.String())
+	return b.fanOut(ctx, "reminder", recipients, func(chatID int64) error {
+		return b.sendTaskCard(ctx, chatID, task.ID, text, keyboard)
+	})
+}
+
+// NotifyAdmin escalates the expired critical task to the bot admin.
+func (b *Bot) NotifyAdmin(ctx context.Context, task domain.Task) error {
+	if b.cfg.AdminID == 0 {
+		return nil
+	}
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("🔴 Просрочена критическая задача: \n\n%s", task.String())
+	return b.fanOut(ctx, "admin escalation", []int64{b.cfg.AdminID}, func(chatID int64) error {
+		return b.sendTaskCard(ctx, chatID, task.ID, text, keyboard)
+	})
+}
+
+// NotifyExpiredDigest sends observers of the team one message with all its expired low priority tasks.
+func (b *Bot) NotifyExpiredDigest(ctx context.Context, teamID int64, tasks []domain.Task) error {
+	observers, err := b.storage.GetObservers(ctx, teamID)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetObservers: %w", err)
+	}
+	texts := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		texts = append(texts, task.String())
+	}
+	text := fmt.Sprintf("Просроченные задачи с низким приоритетом (%d): \n\n%s", len(tasks), strings.Join(texts, "\n\n"))
+
+	return b.fanOut(ctx, "expired digest", slices.Collect(maps.Keys(observers)), func(chatID int64) error {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		if _, err := b.bot.SendNotification(msg); err != nil {
+			return fmt.Errorf("b.bot.SendNotification (%d): %w", msg.ChatID, err)
+		}
+		return nil
+	})
+}
+
+// executorChats returns chats of the task executor and its co-executors.
+func (b *Bot) executorChats(ctx context.Context, task domain.Task) ([]int64, error) {
+	participants, err := b.storage.GetTaskParticipants(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("b.storage.GetTaskParticipants: %w", err)
+	}
+	var recipients []int64
+	if task.ExecutorChatID != 0 {
+		recipients = append(recipients, task.ExecutorChatID)
+	}
+	for _, participant := range participants {
+		if participant.Role == domain.ExecutorParticipant && !slices.Contains(recipients, participant.ChatID) {
+			recipients = append(recipients, participant.ChatID)
+		}
+	}
+	return recipients, nil
 }
 
 func (b *Bot) NotifyExecutor(ctx context.Context, createdTask domain.Task, excludeChatIDs ...int64) error {
 	if slices.Contains(excludeChatIDs, createdTask.ExecutorChatID) {
 		return nil
 	}
-	msg := tgbotapi.NewMessage(createdTask.ExecutorChatID,
-		fmt.Sprintf("Создана задача, в которой вы являетесь исполнителем: \n\n%s", createdTask.String()),
-	)
+	keyboard, err := b.taskKeyboard(ctx, createdTask.ID)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("Создана задача, в которой вы являетесь исполнителем: \n\n%s", createdTask.String())
+	return b.fanOut(ctx, "task created", []int64{createdTask.ExecutorChatID}, func(chatID int64) error {
+		return b.sendTaskCard(ctx, chatID, createdTask.ID, text, keyboard)
+	})
+}
+
+// NotifyParticipant sends the card of the task to the chat added to it.
+func (b *Bot) NotifyParticipant(ctx context.Context, task domain.Task, participant domain.TaskParticipant) error {
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("Вас добавили в задачу, ваша роль - %s: \n\n%s", participant.Role, task.String())
+	return b.fanOut(ctx, "participant added", []int64{participant.ChatID}, func(chatID int64) error {
+		return b.sendTaskCard(ctx, chatID, task.ID, text, keyboard)
+	})
+}
+
+// NotifyComment forwards the new comment to the executors, the creator and the watchers of the task except its author.
+func (b *Bot) NotifyComment(ctx context.Context, task domain.Task, comment domain.Comment) error {
+	recipients, err := b.executorChats(ctx, task)
+	if err != nil {
+		return err
+	}
+	followers, err := b.taskFollowers(ctx, task)
+	if err != nil {
+		return err
+	}
+	recipients = append(recipients, followers...)
+	slices.Sort(recipients)
+	recipients = slices.Compact(recipients)
+
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("💬 Комментарий к задаче №%d %s\n\n%s\n\nОтветьте на это сообщение, чтобы прокомментировать", task.ID, html.EscapeString(task.Title), comment)
+	recipients = slices.DeleteFunc(recipients, func(chatID int64) bool { return chatID == comment.ChatID })
+	return b.fanOut(ctx, "comment", recipients, func(chatID int64) error {
+		return b.sendTaskMessage(notificationContext(ctx), chatID, task.ID, text, keyboard)
+	})
+}
+
+// NotifyProof forwards the proof of completion to the creator and the watchers of the task except its author.
+func (b *Bot) NotifyProof(ctx context.Context, task domain.Task, proof domain.Proof) error {
+	recipients, err := b.taskFollowers(ctx, task)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("✅ Подтверждение выполнения задачи №%d %s", task.ID, html.EscapeString(task.Title))
+	recipients = slices.DeleteFunc(recipients, func(chatID int64) bool { return chatID == proof.ChatID })
+	return b.fanOut(ctx, "proof", recipients, func(chatID int64) error {
+		return b.sendProof(notificationContext(ctx), chatID, task, proof, header)
+	})
+}
+
+// taskFollowers returns the creator and the watchers of the task.
+func (b *Bot) taskFollowers(ctx context.Context, task domain.Task) ([]int64, error) {
+	participants, err := b.storage.GetTaskParticipants(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("b.storage.GetTaskParticipants: %w", err)
+	}
+	var followers []int64
+	if task.CreatedBy != 0 {
+		followers = append(followers, task.CreatedBy)
+	}
+	for _, participant := range participants {
+		if participant.Role == domain.WatcherParticipant && !slices.Contains(followers, participant.ChatID) {
+			followers = append(followers, participant.ChatID)
+		}
+	}
+	return followers, nil
+}
+
+// taskKeyboard returns buttons under messages about the task: its comments and attachments if there are any.
+func (b *Bot) taskKeyboard(ctx context.Context, taskID int) (tgbotapi.InlineKeyboardMarkup, error) {
+	count, err := b.storage.GetCommentsCount(ctx, taskID)
+	if err != nil {
+		return tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("b.storage.GetCommentsCount: %w", err)
+	}
+	attachments, err := b.storage.GetAttachments(ctx, taskID)
+	if err != nil {
+		return tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("b.storage.GetAttachments: %w", err)
+	}
+	buttons := []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Комментарии (%d)", count), fmt.Sprintf("%s:%d", commentsCallback, taskID)),
+	}
+	if len(attachments) > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Вложения (%d)", len(attachments)), fmt.Sprintf("%s:%d", filesCallback, taskID)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(buttons), nil
+}
+
+// sendTaskMessage sends the message about the task and remembers it, so replies to it are matched back to the task.
+func (b *Bot) sendTaskMessage(ctx context.Context, chatID int64, taskID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	_, err := b.postTaskMessage(ctx, chatID, taskID, text, keyboard)
+	return err
+}
+
+// postTaskMessage sends the message about the task, remembers it and returns its id.
+func (b *Bot) postTaskMessage(ctx context.Context, chatID int64, taskID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
-	if _, err := b.bot.Send(msg); err != nil {
-		return fmt.Errorf("b.bot.Send (%d): %w", msg.ChatID, err)
+	msg.ReplyMarkup = keyboard
+	sent, err := b.bot.SendContext(ctx, msg)
+	if err != nil {
+		return 0, fmt.Errorf("b.bot.SendContext (%d): %w", msg.ChatID, err)
+	}
+	if err := b.storage.AddTaskMessage(ctx, chatID, sent.MessageID, taskID); err != nil {
+		return 0, fmt.Errorf("b.storage.AddTaskMessage: %w", err)
+	}
+	return sent.MessageID, nil
+}
+
+// sendTaskCard sends the new card of the task for the event which needs attention,
+// later changes of the task are edited into this message.
+func (b *Bot) sendTaskCard(ctx context.Context, chatID int64, taskID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	messageID, err := b.postTaskMessage(notificationContext(ctx), chatID, taskID, text, keyboard)
+	if err != nil {
+		return err
+	}
+	if err := b.storage.SetTaskCard(ctx, taskID, chatID, messageID); err != nil {
+		return fmt.Errorf("b.storage.SetTaskCard: %w", err)
 	}
 	return nil
 }
+
+// editTaskCard edits the card without sending a new one, the card which is gone is forgotten.
+func (b *Bot) editTaskCard(ctx context.Context, chatID int64, taskID, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	edited, err := b.editTaskCardMessage(chatID, messageID, text, keyboard)
+	if err != nil {
+		return err
+	}
+	if !edited {
+		if err := b.storage.DeleteTaskCard(ctx, taskID, chatID); err != nil {
+			return fmt.Errorf("b.storage.DeleteTaskCard: %w", err)
+		}
+	}
+	return nil
+}
+
+// editTaskCardMessage edits text and buttons of the card. Returns false when the message is gone.
+func (b *Bot) editTaskCardMessage(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (bool, error) {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	edit.ParseMode = tgbotapi.ModeHTML
+	return b.editMessage(edit)
+}
+
+// editMessage edits the message sent by the bot. Returns false when the message is gone:
+// it is deleted by the user or the chat is cleared, so it can't be edited anymore.
+func (b *Bot) editMessage(edit tgbotapi.EditMessageTextConfig) (bool, error) {
+	_, err := b.bot.SendNotification(edit)
+	var apiErr *tgbotapi.Error
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "message is not modified"):
+		// the message already shows the same text
+		return true, nil
+	case errors.As(err, &apiErr) && (strings.Contains(apiErr.Message, "message to edit not found") ||
+		strings.Contains(apiErr.Message, "message can't be edited")):
+		return false, nil
+	default:
+		return false, fmt.Errorf("b.bot.SendNotification (%d): %w", edit.ChatID, err)
+	}
+}
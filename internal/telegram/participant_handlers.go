@@ -0,0 +1,216 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// handleAddParticipantCommand adds executor or watcher to the task: /add_participant task user [role].
+func (b *Bot) handleAddParticipantCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < 2 || len(args) > 3 {
+		responseMsg.Text = addParticipantUsage
+		return
+	}
+	role := domain.WatcherParticipant
+	if len(args) == 3 {
+		var err error
+		if role, err = domain.ParseParticipantRole(args[2]); err != nil {
+			responseMsg.Text = addParticipantUsage
+			return
+		}
+	}
+
+	task, participant, text := b.findTaskParticipant(ctx, logger, message.Chat.ID, args[0], args[1])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	// only members of the team may take part in its tasks
+	participantRole, err := b.storage.GetRole(ctx, task.TeamID, participant.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get participant role")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if participantRole == domain.UnknownRole {
+		responseMsg.Text = fmt.Sprintf("%s не состоит в команде задачи", participant.Mention())
+		return
+	}
+
+	added := domain.TaskParticipant{TaskID: task.ID, ChatID: participant.ID, Role: role}
+	if err := b.storage.AddTaskParticipant(ctx, added); err != nil {
+		logger.WithError(err).Error("failed to add task participant")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	responseMsg.Text = fmt.Sprintf("%s добавлен в задачу №%d, роль - %s", participant.Mention(), task.ID, role)
+
+	if err := b.NotifyParticipant(ctx, task, added); err != nil {
+		logger.WithError(err).Error("failed to notify participant")
+	}
+}
+
+// handleRemoveParticipantCommand removes participant from the task: /remove_participant task user.
+func (b *Bot) handleRemoveParticipantCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		responseMsg.Text = "Использование: /remove_participant номер_задачи пользователь"
+		return
+	}
+
+	task, participant, text := b.findTaskParticipant(ctx, logger, message.Chat.ID, args[0], args[1])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	if err := b.storage.RemoveTaskParticipant(ctx, task.ID, participant.ID); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = fmt.Sprintf("%s не участвует в задаче №%d", participant.Mention(), task.ID)
+			return
+		}
+		logger.WithError(err).Error("failed to remove task participant")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	responseMsg.Text = fmt.Sprintf("%s удалён из задачи №%d", participant.Mention(), task.ID)
+}
+
+func (b *Bot) handleParticipantsCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, strings.TrimSpace(message.CommandArguments()))
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	participants, err := b.storage.GetTaskParticipants(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get task participants")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if len(participants) == 0 {
+		responseMsg.Text = fmt.Sprintf("У задачи №%d нет участников", task.ID)
+		return
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Участники задачи №%d:\n", task.ID))
+	for _, participant := range participants {
+		mention := strconv.FormatInt(participant.ChatID, 10)
+		if chat, err := b.storage.GetChatByID(ctx, participant.ChatID); err == nil {
+			mention = chat.Mention()
+		}
+		builder.WriteString(fmt.Sprintf("\n%s - %s", mention, participant.Role))
+		if participant.Role == domain.ExecutorParticipant && participant.Done {
+			builder.WriteString(" ✅")
+		}
+	}
+	responseMsg.Text = builder.String()
+}
+
+// findTask returns task of the chat's active team by its number or the text explaining why it can't be found.
+func (b *Bot) findTask(ctx context.Context, logger *log.Entry, chatID int64, rawTaskID string) (domain.Task, string) {
+	taskID, err := strconv.Atoi(rawTaskID)
+	if err != nil {
+		return domain.Task{}, "Некорректный номер задачи, должно быть число"
+	}
+	teamID, err := b.activeTeamID(ctx, chatID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		return domain.Task{}, errorReponse
+	}
+	task, err := b.storage.GetTask(ctx, teamID, taskID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.Task{}, fmt.Sprintf("Задача с номером %d не найдена", taskID)
+		}
+		logger.WithError(err).Error("failed to get task")
+		return domain.Task{}, errorReponse
+	}
+	return task, ""
+}
+
+// messageTask returns task of the team by its number if the chat is a member of the team,
+// replies to messages about tasks are matched to their team rather than to the active one.
+// Otherwise it returns the text explaining why the task can't be used.
+func (b *Bot) messageTask(ctx context.Context, logger *log.Entry, chatID, teamID int64, taskID int) (domain.Task, string) {
+	role, err := b.storage.GetRole(ctx, teamID, chatID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get role")
+		return domain.Task{}, errorReponse
+	}
+	if role == domain.UnknownRole {
+		return domain.Task{}, unknownCommandText
+	}
+	task, err := b.storage.GetTask(ctx, teamID, taskID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.Task{}, fmt.Sprintf("Задача с номером %d не найдена", taskID)
+		}
+		logger.WithError(err).Error("failed to get task")
+		return domain.Task{}, errorReponse
+	}
+	return task, ""
+}
+
+func (b *Bot) findTaskParticipant(
+	ctx context.Context,
+	logger *log.Entry,
+	chatID int64,
+	rawTaskID, target string,
+) (domain.Task, *domain.Chat, string) {
+	task, text := b.findTask(ctx, logger, chatID, rawTaskID)
+	if text != "" {
+		return domain.Task{}, nil, text
+	}
+	participant, err := b.findChat(ctx, target)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.Task{}, nil, userNotFound
+		}
+		logger.WithError(err).Error("failed to find chat")
+		return domain.Task{}, nil, errorReponse
+	}
+	return task, participant, ""
+}
+
+const addParticipantUsage = "Использование: /add_participant номер_задачи пользователь [роль]\nРоли: executor, watcher (по умолчанию)"
@@ -0,0 +1,250 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const proofRequestText = "📋 Задача №%d %s требует подтверждения выполнения.\nОтветьте на это сообщение фото, документом, геопозицией или текстовым отчётом"
+
+const requireProofUsage = "Использование: /require_proof номер_задачи [off] - требовать подтверждение выполнения задачи, off отменяет требование"
+
+// requestProof asks the chat for the proof of completion of the task, the request is sent as a message about the task
+// and the reply to it is taken as the proof. Returns text of the response when the request is not sent.
+func (b *Bot) requestProof(ctx context.Context, logger *log.Entry, chatID int64, task domain.Task) string {
+	if err := b.storage.SetStage(ctx, chatID, domain.MarkTaskProof); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to set proof stage")
+		return errorReponse
+	}
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get task keyboard")
+		return errorReponse
+	}
+	if err := b.sendTaskMessage(ctx, chatID, task.ID, fmt.Sprintf(proofRequestText, task.ID, html.EscapeString(task.Title)), keyboard); err != nil {
+		logger.WithError(err).Error("failed to request proof")
+		return errorReponse
+	}
+	return ""
+}
+
+// messageProof returns the proof of completion in the message: photo, document, location or text report.
+func messageProof(message *tgbotapi.Message) (domain.Proof, bool) {
+	proof := domain.Proof{
+		ChatID: message.Chat.ID,
+		Author: commentAuthor(message),
+		Text:   message.Caption,
+	}
+	switch {
+	case len(message.Photo) > 0:
+		proof.Kind = domain.PhotoProof
+		proof.FileID = message.Photo[len(message.Photo)-1].FileID
+	case message.Document != nil:
+		proof.Kind = domain.DocumentProof
+		proof.FileID = message.Document.FileID
+	case message.Location != nil:
+		proof.Kind = domain.LocationProof
+		proof.Latitude = message.Location.Latitude
+		proof.Longitude = message.Location.Longitude
+	case strings.TrimSpace(message.Text) != "":
+		proof.Kind = domain.TextProof
+		proof.Text = strings.TrimSpace(message.Text)
+	default:
+		return domain.Proof{}, false
+	}
+	return proof, true
+}
+
+// handleTaskProofStage takes the reply to the proof request as the proof of completion and marks the task as done.
+func (b *Bot) handleTaskProofStage(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	const replyText = "Ответьте на сообщение с запросом подтверждения фото, документом, геопозицией или текстовым отчётом"
+	if message.ReplyToMessage == nil {
+		responseMsg.Text = replyText
+		return
+	}
+	teamID, taskID, err := b.storage.GetMessageTask(ctx, message.Chat.ID, message.ReplyToMessage.MessageID)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to get task of the message")
+			responseMsg.Text = errorReponse
+			return
+		}
+		responseMsg.Text = replyText
+		return
+	}
+	task, text := b.messageTask(ctx, logger, message.Chat.ID, teamID, taskID)
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	allowed, err := b.mayFinishTask(ctx, task, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to check task access")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if !allowed {
+		responseMsg.Text = finishForbiddenText
+		return
+	}
+	proof, ok := messageProof(message)
+	if !ok {
+		responseMsg.Text = replyText
+		return
+	}
+
+	proof.TaskID = task.ID
+	if proof.ID, err = b.storage.AddProof(ctx, proof); err != nil {
+		logger.WithError(err).Error("failed to add proof")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if task.IsFinished() {
+		if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to set next stage")
+		}
+		responseMsg.Text = fmt.Sprintf("Подтверждение сохранено, задача №%d уже завершена", task.ID)
+		return
+	}
+	responseMsg.Text = b.finishTask(ctx, logger, message.Chat.ID, task)
+
+	if err := b.NotifyProof(ctx, task, proof); err != nil {
+		logger.WithError(err).Error("failed to notify about proof")
+	}
+}
+
+// sendProof sends the proof of completion with the header, files and locations follow the text.
+func (b *Bot) sendProof(ctx context.Context, chatID int64, task domain.Task, proof domain.Proof, header string) error {
+	keyboard, err := b.taskKeyboard(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	if err := b.sendTaskMessage(ctx, chatID, task.ID, header+"\n\n"+proof.String(), keyboard); err != nil {
+		return err
+	}
+
+	var msg tgbotapi.Chattable
+	switch proof.Kind {
+	case domain.PhotoProof:
+		msg = tgbotapi.NewPhoto(chatID, tgbotapi.FileID(proof.FileID))
+	case domain.DocumentProof:
+		msg = tgbotapi.NewDocument(chatID, tgbotapi.FileID(proof.FileID))
+	case domain.LocationProof:
+		msg = tgbotapi.NewLocation(chatID, proof.Latitude, proof.Longitude)
+	default:
+		return nil
+	}
+	if _, err := b.bot.SendContext(ctx, msg); err != nil {
+		return fmt.Errorf("b.bot.SendContext (%d): %w", chatID, err)
+	}
+	return nil
+}
+
+// handleRequireProofCommand turns on or off the proof of completion for the task: /require_proof task [off].
+func (b *Bot) handleRequireProofCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		responseMsg.Text = requireProofUsage
+		return
+	}
+	required := true
+	if len(args) == 2 {
+		value, err := parseSwitch(args[1])
+		if err != nil {
+			responseMsg.Text = requireProofUsage
+			return
+		}
+		required = value
+	}
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, args[0])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	if err := b.storage.SetTaskProofRequired(ctx, task.TeamID, task.ID, required); err != nil {
+		logger.WithError(err).Error("failed to set task proof required")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	if !required {
+		responseMsg.Text = fmt.Sprintf("Подтверждение выполнения задачи №%d больше не требуется", task.ID)
+		return
+	}
+	responseMsg.Text = fmt.Sprintf("Задача №%d будет выполнена только с подтверждением: фото, документом, геопозицией или отчётом", task.ID)
+}
+
+// handleProofsCommand sends proofs of completion of the task for audit: /proofs task.
+func (b *Bot) handleProofsCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, message.CommandArguments())
+	if text == "" {
+		text = b.sendProofs(ctx, logger, message.Chat.ID, task)
+	}
+	if text == "" {
+		return
+	}
+	if _, err := b.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text)); err != nil {
+		logger.WithError(err).Error("failed to send response")
+	}
+}
+
+// sendProofs sends every proof of the task, returns text of the response when there is nothing sent.
+func (b *Bot) sendProofs(ctx context.Context, logger *log.Entry, chatID int64, task domain.Task) string {
+	proofs, err := b.storage.GetProofs(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get proofs")
+		return errorReponse
+	}
+	if len(proofs) == 0 {
+		return fmt.Sprintf("Подтверждений выполнения задачи №%d нет", task.ID)
+	}
+	for i, proof := range proofs {
+		header := fmt.Sprintf("📋 Подтверждение %d/%d задачи №%d %s", i+1, len(proofs), task.ID, html.EscapeString(task.Title))
+		if err := b.sendProof(ctx, chatID, task, proof, header); err != nil {
+			logger.WithError(err).Error("failed to send proof")
+			return errorReponse
+		}
+	}
+	return ""
+}
+
+// parseSwitch parses on and off values of command arguments and template fields.
+func parseSwitch(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "on", "yes", "true", "да", "вкл":
+		return true, nil
+	case "off", "no", "false", "нет", "выкл":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown switch value %q: %w", raw, errs.ErrInvalidInput)
+	}
+}
@@ -0,0 +1,210 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const addRecurrenceUsage = `Использование: /add_recurrence расписание; исполнитель; срок; название
+Расписание:
+daily 09:00 - каждый день
+weekly пн,пт 09:00 - по дням недели
+monthly 15 09:00 - по числам месяца, в месяцах без этого числа задача не создаётся
+cron 0 9 * * 1-5 - cron-выражение
+Срок отсчитывается от создания задачи, например 4h или 2d
+Пример: /add_recurrence weekly пт 10:00; @username; 1d; Еженедельный отчёт`
+
+// handleAddRecurrenceCommand adds recurring task: /add_recurrence schedule; executor; deadline; title.
+func (b *Bot) handleAddRecurrenceCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.SplitN(message.CommandArguments(), ";", 4)
+	if len(args) != 4 {
+		responseMsg.Text = addRecurrenceUsage
+		return
+	}
+	schedule, err := domain.ParseSchedule(args[0])
+	if err != nil {
+		responseMsg.Text = fmt.Sprintf("Некорректное расписание \"%s\"\n\n%s", strings.TrimSpace(args[0]), addRecurrenceUsage)
+		return
+	}
+	deadlineIn, err := parseTTL(strings.TrimSpace(args[2]))
+	if err != nil || deadlineIn == 0 {
+		responseMsg.Text = fmt.Sprintf("Некорректный срок \"%s\"\n\n%s", strings.TrimSpace(args[2]), addRecurrenceUsage)
+		return
+	}
+	title := strings.TrimSpace(args[3])
+	if title == "" {
+		responseMsg.Text = addRecurrenceUsage
+		return
+	}
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	executorContact, executorChatID, err := b.findExecutor(ctx, args[1])
+	if err != nil {
+		logger.WithError(err).Error("failed to find executor")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	recurrence := domain.Recurrence{
+		TeamID:          teamID,
+		Title:           title,
+		ExecutorContact: executorContact,
+		ExecutorChatID:  executorChatID,
+		Schedule:        schedule,
+		DeadlineIn:      deadlineIn,
+		NextRun:         schedule.Next(time.Now()),
+		CreatedBy:       message.Chat.ID,
+	}
+	if recurrence.ID, err = b.storage.AddRecurrence(ctx, recurrence); err != nil {
+		logger.WithError(err).Error("failed to add recurrence")
+		responseMsg.Text = errorReponse
+		return
+	}
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	responseMsg.Text = fmt.Sprintf("Повторяющаяся задача добавлена: \n\n%s", recurrence)
+}
+
+func (b *Bot) handleRecurrencesCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	recurrences, err := b.storage.GetRecurrences(ctx, teamID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get recurrences")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if len(recurrences) == 0 {
+		responseMsg.Text = "Повторяющихся задач нет"
+		return
+	}
+
+	texts := make([]string, 0, len(recurrences))
+	for _, recurrence := range recurrences {
+		texts = append(texts, recurrence.String())
+	}
+	responseMsg.Text = strings.Join(texts, "\n\n")
+}
+
+func (b *Bot) handlePauseRecurrenceCommand(ctx context.Context, message *tgbotapi.Message) {
+	b.setRecurrencePaused(ctx, message, true)
+}
+
+func (b *Bot) handleResumeRecurrenceCommand(ctx context.Context, message *tgbotapi.Message) {
+	b.setRecurrencePaused(ctx, message, false)
+}
+
+// setRecurrencePaused pauses or resumes recurrence. Resumed recurrence continues from its next run
+// after now, runs missed during the pause are not created.
+func (b *Bot) setRecurrencePaused(ctx context.Context, message *tgbotapi.Message, paused bool) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	recurrence, text := b.findRecurrence(ctx, logger, message.Chat.ID, strings.TrimSpace(message.CommandArguments()))
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	nextRun := recurrence.NextRun
+	if !paused {
+		nextRun = recurrence.Schedule.Next(time.Now())
+	}
+	if err := b.storage.SetRecurrencePaused(ctx, recurrence.TeamID, recurrence.ID, paused, nextRun); err != nil {
+		logger.WithError(err).Error("failed to set recurrence paused")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if paused {
+		responseMsg.Text = fmt.Sprintf("Повторение №%d приостановлено", recurrence.ID)
+		return
+	}
+	responseMsg.Text = fmt.Sprintf("Повторение №%d возобновлено, следующий запуск %s", recurrence.ID, nextRun.Format(domain.DeadlineLayout))
+}
+
+func (b *Bot) handleDeleteRecurrenceCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	recurrence, text := b.findRecurrence(ctx, logger, message.Chat.ID, strings.TrimSpace(message.CommandArguments()))
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	if err := b.storage.DeleteRecurrence(ctx, recurrence.TeamID, recurrence.ID); err != nil {
+		logger.WithError(err).Error("failed to delete recurrence")
+		responseMsg.Text = errorReponse
+		return
+	}
+	responseMsg.Text = fmt.Sprintf("Повторение №%d удалено, уже созданные задачи остались", recurrence.ID)
+}
+
+// findRecurrence returns recurrence of the chat's active team by its number or the text explaining why it can't be found.
+func (b *Bot) findRecurrence(ctx context.Context, logger *log.Entry, chatID int64, rawID string) (domain.Recurrence, string) {
+	recurrenceID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return domain.Recurrence{}, "Некорректный номер повторения, должно быть число"
+	}
+	teamID, err := b.activeTeamID(ctx, chatID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		return domain.Recurrence{}, errorReponse
+	}
+	recurrence, err := b.storage.GetRecurrence(ctx, teamID, recurrenceID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.Recurrence{}, fmt.Sprintf("Повторение с номером %d не найдено", recurrenceID)
+		}
+		logger.WithError(err).Error("failed to get recurrence")
+		return domain.Recurrence{}, errorReponse
+	}
+	return recurrence, ""
+}
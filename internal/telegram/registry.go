@@ -0,0 +1,420 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"tasks_bot/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type commandHandler = func(b *Bot, ctx context.Context, message *tgbotapi.Message)
+
+type commandArg struct {
+	name     string
+	optional bool
+}
+
+// command declares everything about bot command: handler, roles allowed to use it,
+// menu text and arguments. Menus, dispatch and permission checks are built from it.
+type command struct {
+	name        string
+	description string
+	roles       []domain.Role
+	args        []commandArg
+	handler     commandHandler
+}
+
+func (c command) allows(role domain.Role) bool {
+	return slices.Contains(c.roles, role)
+}
+
+func (c command) usage() string {
+	builder := strings.Builder{}
+	builder.WriteString("Использование: /")
+	builder.WriteString(c.name)
+	for _, arg := range c.args {
+		if arg.optional {
+			builder.WriteString(fmt.Sprintf(" [%s]", arg.name))
+		} else {
+			builder.WriteString(fmt.Sprintf(" %s", arg.name))
+		}
+	}
+	return builder.String()
+}
+
+func (c command) hasRequiredArgs(message *tgbotapi.Message) bool {
+	required := 0
+	for _, arg := range c.args {
+		if !arg.optional {
+			required++
+		}
+	}
+	return len(strings.Fields(message.CommandArguments())) >= required
+}
+
+var (
+	allRoles      = []domain.Role{domain.UnknownRole, domain.Executor, domain.Observer, domain.Chief, domain.Admin}
+	taskManagers  = []domain.Role{domain.Chief, domain.Observer, domain.Admin}
+	taskObservers = []domain.Role{domain.Observer, domain.Admin}
+	taskExecutors = []domain.Role{domain.Executor, domain.Chief, domain.Observer, domain.Admin}
+	admins        = []domain.Role{domain.Admin}
+)
+
+const enterDeadlineText = "Введите номер задачи и новый дедлайн в формате \"21 21.12.2024 12:20:00\""
+
+func exceptRole(role domain.Role) []domain.Role {
+	roles := make([]domain.Role, 0, len(allRoles))
+	for _, r := range allRoles {
+		if r != role {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+func nextStage(stage domain.Stage, text string) commandHandler {
+	return func(b *Bot, ctx context.Context, message *tgbotapi.Message) {
+		b.setNextStageWithMessage(ctx, message, stage, text)
+	}
+}
+
+func withCommandName(handler func(b *Bot, ctx context.Context, message *tgbotapi.Message, command string)) commandHandler {
+	return func(b *Bot, ctx context.Context, message *tgbotapi.Message) {
+		handler(b, ctx, message, message.Command())
+	}
+}
+
+// defaultCommands is the list of all bot commands in menu order.
+func defaultCommands() []command {
+	return []command{
+		{
+			name: startCmd, description: "Начать", roles: allRoles,
+			args:    []commandArg{{name: "код приглашения", optional: true}},
+			handler: (*Bot).handleStart,
+		},
+		{name: getRoleCmd, description: "Узнать свою роль", roles: allRoles, handler: (*Bot).handleGetRoleCommand},
+		{
+			name: teamCmd, description: "Мои команды и переход в другую команду", roles: allRoles,
+			args:    []commandArg{{name: "название", optional: true}},
+			handler: (*Bot).handleTeamCommand,
+		},
+		{
+			name: getSelfTasksCmd, description: "Получить свои задачи",
+			roles:   []domain.Role{domain.Executor},
+			handler: (*Bot).handleGetSelfTasksCommand,
+		},
+		{
+			name: addTaskCmd, description: "Добавить задачу", roles: taskManagers,
+			args:    []commandArg{{name: "tpl:шаблон", optional: true}, {name: "поле:значение", optional: true}},
+			handler: (*Bot).handleAddTaskCommand,
+		},
+		{
+			name: subordinatesCmd, description: "Мои исполнители",
+			roles:   []domain.Role{domain.Chief},
+			handler: (*Bot).handleSubordinatesCommand,
+		},
+		{name: getAllTasksCmd, description: "Получить все задачи", roles: taskManagers, handler: (*Bot).handleGetAllTasksCommand},
+		{name: getExpiredTasksCmd, description: "Получить просроченные задачи", roles: taskManagers, handler: (*Bot).handleGetExpiredTasksCommand},
+		{name: getOpenTasks, description: "Получить открытые задачи", roles: taskManagers, handler: (*Bot).handleGetOpenTasksCommand},
+		{name: getDoneTasks, description: "Получить выполненные задачи", roles: taskManagers, handler: (*Bot).handleGetDoneTasksCommand},
+		{name: getClosedTasks, description: "Получить закрытые задачи", roles: taskObservers, handler: (*Bot).handleGetClosedTasksCommand},
+		{
+			name: markTaskAsDoneCommand, description: "Отметить задачу выполненной", roles: taskExecutors,
+			handler: nextStage(domain.MarkTaskAsDone, enterTaskNumberText),
+		},
+		{
+			name: markTaskAsClosedCommand, description: "Закрыть задачу", roles: taskObservers,
+			handler: nextStage(domain.MarkTaskAsClosed, enterTaskNumberText),
+		},
+		{
+			name: deleteTaskCommand, description: "Удалить задачу", roles: taskObservers,
+			handler: nextStage(domain.DeleteTask, enterTaskNumberText),
+		},
+		{
+			name: changeTaskDeadlineCommand, description: "Изменить дедлайн задачи", roles: taskManagers,
+			handler: nextStage(domain.ChangeDeadline, enterDeadlineText),
+		},
+		{
+			name: addParticipantCmd, description: "Добавить участника задачи", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "пользователь"}, {name: "роль", optional: true}},
+			handler: (*Bot).handleAddParticipantCommand,
+		},
+		{
+			name: removeParticipantCmd, description: "Удалить участника задачи", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "пользователь"}},
+			handler: (*Bot).handleRemoveParticipantCommand,
+		},
+		{
+			name: participantsCmd, description: "Участники задачи", roles: taskExecutors,
+			args:    []commandArg{{name: "номер задачи"}},
+			handler: (*Bot).handleParticipantsCommand,
+		},
+		{
+			name: setParentCmd, description: "Сделать задачу подзадачей", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "номер родительской задачи"}},
+			handler: (*Bot).handleSetParentCommand,
+		},
+		{
+			name: subtasksCmd, description: "Подзадачи задачи", roles: taskExecutors,
+			args:    []commandArg{{name: "номер задачи"}},
+			handler: (*Bot).handleSubtasksCommand,
+		},
+		{
+			name: addChecklistItemCmd, description: "Добавить пункт чек-листа", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "текст"}},
+			handler: (*Bot).handleAddChecklistItemCommand,
+		},
+		{
+			name: checklistCmd, description: "Чек-лист задачи", roles: taskExecutors,
+			args:    []commandArg{{name: "номер задачи"}},
+			handler: (*Bot).handleChecklistCommand,
+		},
+		{
+			name: addBlockerCmd, description: "Добавить блокирующую задачу", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "номер блокирующей задачи"}},
+			handler: (*Bot).handleAddBlockerCommand,
+		},
+		{
+			name: removeBlockerCmd, description: "Убрать блокирующую задачу", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "номер блокирующей задачи"}},
+			handler: (*Bot).handleRemoveBlockerCommand,
+		},
+		{
+			name: addRecurrenceCmd, description: "Добавить повторяющуюся задачу", roles: taskManagers,
+			args: []commandArg{
+				{name: "расписание;"},
+				{name: "исполнитель;"},
+				{name: "срок;"},
+				{name: "название"},
+			},
+			handler: (*Bot).handleAddRecurrenceCommand,
+		},
+		{name: recurrencesCmd, description: "Повторяющиеся задачи", roles: taskManagers, handler: (*Bot).handleRecurrencesCommand},
+		{
+			name: pauseRecurrenceCmd, description: "Приостановить повторяющуюся задачу", roles: taskManagers,
+			args:    []commandArg{{name: "номер повторения"}},
+			handler: (*Bot).handlePauseRecurrenceCommand,
+		},
+		{
+			name: resumeRecurrenceCmd, description: "Возобновить повторяющуюся задачу", roles: taskManagers,
+			args:    []commandArg{{name: "номер повторения"}},
+			handler: (*Bot).handleResumeRecurrenceCommand,
+		},
+		{
+			name: deleteRecurrenceCmd, description: "Удалить повторяющуюся задачу", roles: taskManagers,
+			args:    []commandArg{{name: "номер повторения"}},
+			handler: (*Bot).handleDeleteRecurrenceCommand,
+		},
+		{
+			name: templatesCmd, description: "Шаблоны задач", roles: taskManagers,
+			args:    []commandArg{{name: "add|delete", optional: true}, {name: "имя", optional: true}},
+			handler: (*Bot).handleTemplatesCommand,
+		},
+		{
+			name: tasksCmd, description: "Задачи по фильтру", roles: taskExecutors,
+			args:    []commandArg{{name: "фильтр", optional: true}},
+			handler: (*Bot).handleTasksCommand,
+		},
+		{
+			name: viewCmd, description: "Сохранённые фильтры задач", roles: taskExecutors,
+			args:    []commandArg{{name: "save|delete", optional: true}, {name: "имя", optional: true}, {name: "фильтр", optional: true}},
+			handler: (*Bot).handleViewCommand,
+		},
+		{
+			name: setTagsCmd, description: "Заменить теги задачи", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "#тег ...", optional: true}},
+			handler: (*Bot).handleSetTagsCommand,
+		},
+		{
+			name: searchCmd, description: "Поиск задач", roles: taskExecutors,
+			args:    []commandArg{{name: "запрос"}},
+			handler: (*Bot).handleSearchCommand,
+		},
+		{
+			name: commentCmd, description: "Комментировать задачу", roles: taskExecutors,
+			args:    []commandArg{{name: "номер задачи"}, {name: "текст"}},
+			handler: (*Bot).handleCommentCommand,
+		},
+		{
+			name: attachCmd, description: "Приложить файл к задаче", roles: taskExecutors,
+			args:    []commandArg{{name: "номер задачи"}},
+			handler: (*Bot).handleAttachCommand,
+		},
+		{
+			name: filesCmd, description: "Вложения задачи", roles: taskExecutors,
+			args:    []commandArg{{name: "номер задачи"}},
+			handler: (*Bot).handleFilesCommand,
+		},
+		{
+			name: requireProofCmd, description: "Требовать подтверждение выполнения", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}, {name: "off", optional: true}},
+			handler: (*Bot).handleRequireProofCommand,
+		},
+		{
+			name: proofsCmd, description: "Подтверждения выполнения задачи", roles: taskManagers,
+			args:    []commandArg{{name: "номер задачи"}},
+			handler: (*Bot).handleProofsCommand,
+		},
+		{
+			name: dashboardCmd, description: "Закрепить доску задач", roles: taskManagers,
+			args:    []commandArg{{name: "off", optional: true}},
+			handler: (*Bot).handleDashboardCommand,
+		},
+		{
+			name: becomeExecutorCmd, description: "Стать исполнителем", roles: exceptRole(domain.Executor),
+			handler: withCommandName((*Bot).handleBecomeCommand),
+		},
+		{
+			name: becomeChiefCmd, description: "Стать шефом", roles: exceptRole(domain.Chief),
+			handler: withCommandName((*Bot).handleBecomeCommand),
+		},
+		{
+			name: becomeObserverCmd, description: "Стать наблюдателем", roles: exceptRole(domain.Observer),
+			handler: withCommandName((*Bot).handleBecomeCommand),
+		},
+		{
+			name: becomeAdminCmd, description: "Стать администратором", roles: exceptRole(domain.Admin),
+			handler: withCommandName((*Bot).handleBecomeCommand),
+		},
+		// admin commands
+		{name: healthCmd, description: "Проверить состояние", roles: admins, handler: (*Bot).handleHealthCommand},
+		{name: debugStorage, description: "Отладка хранилища", roles: admins, handler: (*Bot).handleDebugCommand},
+		{
+			name: inviteCmd, description: "Создать код приглашения", roles: admins,
+			args: []commandArg{
+				{name: "роль"},
+				{name: "@username", optional: true},
+				{name: "срок действия", optional: true},
+			},
+			handler: (*Bot).handleInviteCommand,
+		},
+		{name: listInvitesCmd, description: "Активные приглашения", roles: admins, handler: (*Bot).handleListInvitesCommand},
+		{
+			name: revokeInviteCmd, description: "Отозвать приглашение", roles: admins,
+			args:    []commandArg{{name: "код"}},
+			handler: (*Bot).handleRevokeInviteCommand,
+		},
+		{name: usersCmd, description: "Список пользователей", roles: admins, handler: (*Bot).handleUsersCommand},
+		{
+			name: setRoleCmd, description: "Назначить роль пользователю", roles: admins,
+			args:    []commandArg{{name: "пользователь"}, {name: "роль"}},
+			handler: withCommandName((*Bot).handleManageUserCommand),
+		},
+		{
+			name: revokeRoleCmd, description: "Отозвать роль пользователя", roles: admins,
+			args:    []commandArg{{name: "пользователь"}},
+			handler: withCommandName((*Bot).handleManageUserCommand),
+		},
+		{
+			name: banCmd, description: "Заблокировать пользователя", roles: admins,
+			args:    []commandArg{{name: "пользователь"}},
+			handler: withCommandName((*Bot).handleManageUserCommand),
+		},
+		{
+			name: unbanCmd, description: "Разблокировать пользователя", roles: admins,
+			args:    []commandArg{{name: "пользователь"}},
+			handler: withCommandName((*Bot).handleManageUserCommand),
+		},
+		{
+			name: resetStageCmd, description: "Сбросить состояние диалога пользователя", roles: admins,
+			args:    []commandArg{{name: "пользователь"}},
+			handler: withCommandName((*Bot).handleManageUserCommand),
+		},
+		{name: auditCmd, description: "Журнал действий администраторов", roles: admins, handler: (*Bot).handleAuditCommand},
+		{name: lockoutsCmd, description: "Неверные попытки ввода пароля", roles: admins, handler: (*Bot).handleLockoutsCommand},
+		{
+			name: unlockCmd, description: "Снять блокировку ввода пароля", roles: admins,
+			args:    []commandArg{{name: "id чата"}},
+			handler: (*Bot).handleUnlockCommand,
+		},
+		{
+			name: createTeamCmd, description: "Создать команду", roles: admins,
+			args:    []commandArg{{name: "название"}},
+			handler: (*Bot).handleCreateTeamCommand,
+		},
+		{
+			name: setChiefCmd, description: "Закрепить исполнителя за шефом", roles: admins,
+			args:    []commandArg{{name: "исполнитель"}, {name: "шеф"}},
+			handler: (*Bot).handleSetChiefCommand,
+		},
+	}
+}
+
+type commandRegistry struct {
+	commands []command
+	byName   map[string]int
+}
+
+// newCommandRegistry builds registry from default commands with permissions overridden from config.
+// Overrides map command name to roles separated by "|", "guest" stands for users without role.
+func newCommandRegistry(commands []command, overrides map[string]string) (*commandRegistry, error) {
+	registry := &commandRegistry{
+		commands: commands,
+		byName:   make(map[string]int, len(commands)),
+	}
+	for i, cmd := range commands {
+		if _, ok := registry.byName[cmd.name]; ok {
+			return nil, fmt.Errorf("duplicated command %s", cmd.name)
+		}
+		registry.byName[cmd.name] = i
+	}
+
+	for name, rawRoles := range overrides {
+		i, ok := registry.byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown command %s in permissions override", name)
+		}
+		roles, err := parseRoles(rawRoles)
+		if err != nil {
+			return nil, fmt.Errorf("parseRoles (%s): %w", name, err)
+		}
+		registry.commands[i].roles = roles
+	}
+	return registry, nil
+}
+
+func parseRoles(raw string) ([]domain.Role, error) {
+	roles := make([]domain.Role, 0, len(allRoles))
+	for _, name := range strings.Split(raw, "|") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "guest" {
+			roles = append(roles, domain.UnknownRole)
+			continue
+		}
+		role, err := domain.ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("domain.ParseRole: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (r *commandRegistry) lookup(name string) (command, bool) {
+	i, ok := r.byName[name]
+	if !ok {
+		return command{}, false
+	}
+	return r.commands[i], true
+}
+
+func (r *commandRegistry) allowed(name string, role domain.Role) bool {
+	cmd, ok := r.lookup(name)
+	return ok && cmd.allows(role)
+}
+
+func (r *commandRegistry) menu(role domain.Role) []tgbotapi.BotCommand {
+	menu := make([]tgbotapi.BotCommand, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		if cmd.allows(role) {
+			menu = append(menu, tgbotapi.BotCommand{Command: cmd.name, Description: cmd.description})
+		}
+	}
+	return menu
+}
@@ -0,0 +1,239 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"sync"
+	"tasks_bot/internal/config"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/repository"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// fakeTelegram answers Bot API requests and records sent messages and the last menu set with setMyCommands.
+type fakeTelegram struct {
+	mu    sync.Mutex
+	texts []string
+	menu  []string
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := `true`
+	switch path.Base(r.URL.Path) {
+	case "getMe":
+		result = `{"id":1,"is_bot":true,"username":"test_bot"}`
+	case "sendMessage":
+		f.texts = append(f.texts, r.Form.Get("text"))
+		result = `{"message_id":1,"date":0,"chat":{"id":` + r.Form.Get("chat_id") + `}}`
+	case "setMyCommands":
+		var commands []tgbotapi.BotCommand
+		if err := json.Unmarshal([]byte(r.Form.Get("commands")), &commands); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.menu = f.menu[:0]
+		for _, cmd := range commands {
+			f.menu = append(f.menu, cmd.Command)
+		}
+	}
+	io.WriteString(w, `{"ok":true,"result":`+result+`}`)
+}
+
+func (f *fakeTelegram) sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	texts := f.texts
+	f.texts = nil
+	return texts
+}
+
+func (f *fakeTelegram) lastMenu() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.menu)
+}
+
+// newDispatchBot creates the bot whose command handlers only record the dispatched command.
+func newDispatchBot(t *testing.T, overrides map[string]string) (*Bot, *fakeTelegram, *[]string) {
+	t.Helper()
+
+	api := &fakeTelegram{}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	botAPI, err := tgbotapi.NewBotAPIWithClient("token", server.URL+"/bot%s/%s", server.Client())
+	if err != nil {
+		t.Fatalf("tgbotapi.NewBotAPIWithClient: %v", err)
+	}
+
+	dispatched := &[]string{}
+	commands := defaultCommands()
+	for i := range commands {
+		name := commands[i].name
+		commands[i].handler = func(*Bot, context.Context, *tgbotapi.Message) {
+			*dispatched = append(*dispatched, name)
+		}
+	}
+	registry, err := newCommandRegistry(commands, overrides)
+	if err != nil {
+		t.Fatalf("newCommandRegistry: %v", err)
+	}
+
+	storage, err := repository.NewMemoryStorage(context.Background())
+	if err != nil {
+		t.Fatalf("repository.NewMemoryStorage: %v", err)
+	}
+	logger := log.NewEntry(log.New())
+	logger.Logger.SetOutput(io.Discard)
+	queue := newSendQueue(0, 0)
+	t.Cleanup(queue.close)
+
+	return &Bot{
+		bot:      newBotAPI(botAPI, queue),
+		storage:  storage,
+		cfg:      &config.TelegramConfig{},
+		commands: registry,
+		logger:   logger,
+	}, api, dispatched
+}
+
+func commandMessage(chatID int64, name string) *tgbotapi.Message {
+	// arguments satisfy commands with required ones, so the handler is reached
+	return &tgbotapi.Message{
+		MessageID: 1,
+		Chat:      &tgbotapi.Chat{ID: chatID},
+		Text:      "/" + name + " 1 2 3 4 5",
+		Entities:  []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(name) + 1}},
+	}
+}
+
+func TestMenuMatchesDispatch(t *testing.T) {
+	overrides := map[string]map[string]string{
+		"default": nil,
+		"overridden": {
+			getSelfTasksCmd: "executor|chief",
+			addTaskCmd:      "admin",
+			getRoleCmd:      "guest",
+		},
+	}
+
+	for name, override := range overrides {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			bot, api, dispatched := newDispatchBot(t, override)
+
+			menus := make(map[domain.Role][]string, len(allRoles))
+			for i, role := range allRoles {
+				chatID := int64(100 + i)
+				if err := bot.storage.AddChat(ctx, chatID, role.String(), ""); err != nil {
+					t.Fatalf("AddChat: %v", err)
+				}
+				if role != domain.UnknownRole {
+					if err := bot.storage.SetRole(ctx, domain.DefaultTeamID, chatID, role); err != nil {
+						t.Fatalf("SetRole: %v", err)
+					}
+				}
+
+				if err := bot.setCommands(ctx, chatID, role); err != nil {
+					t.Fatalf("setCommands: %v", err)
+				}
+				menu := api.lastMenu()
+				menus[role] = menu
+
+				for _, cmd := range bot.commands.commands {
+					*dispatched = nil
+					bot.handleCommand(ctx, commandMessage(chatID, cmd.name))
+					rejected := slices.Contains(api.sent(), unknownCommandText)
+					handled := slices.Equal(*dispatched, []string{cmd.name})
+
+					if slices.Contains(menu, cmd.name) {
+						if rejected || !handled {
+							t.Errorf("role %s: command %s is in menu, but it is not dispatched", role, cmd.name)
+						}
+						continue
+					}
+					if !rejected || len(*dispatched) > 0 {
+						t.Errorf("role %s: command %s is not in menu, but it is not rejected", role, cmd.name)
+					}
+				}
+			}
+
+			if override == nil {
+				return
+			}
+			for role, want := range map[domain.Role]bool{
+				domain.Executor: true,
+				domain.Chief:    true,
+				domain.Observer: false,
+				domain.Admin:    false,
+			} {
+				if got := slices.Contains(menus[role], getSelfTasksCmd); got != want {
+					t.Errorf("role %s: %s in menu = %t, want %t", role, getSelfTasksCmd, got, want)
+				}
+			}
+			for _, role := range allRoles {
+				if got, want := slices.Contains(menus[role], addTaskCmd), role == domain.Admin; got != want {
+					t.Errorf("role %s: %s in menu = %t, want %t", role, addTaskCmd, got, want)
+				}
+				if got, want := slices.Contains(menus[role], getRoleCmd), role == domain.UnknownRole; got != want {
+					t.Errorf("role %s: %s in menu = %t, want %t", role, getRoleCmd, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandsAreValid(t *testing.T) {
+	registry, err := newCommandRegistry(defaultCommands(), nil)
+	if err != nil {
+		t.Fatalf("newCommandRegistry: %v", err)
+	}
+
+	for _, cmd := range registry.commands {
+		if cmd.handler == nil {
+			t.Errorf("command %s has no handler", cmd.name)
+		}
+		if len([]rune(cmd.description)) < 3 || len([]rune(cmd.description)) > 256 {
+			t.Errorf("command %s has description of invalid length", cmd.name)
+		}
+		if len(cmd.roles) == 0 {
+			t.Errorf("command %s is not allowed for any role", cmd.name)
+		}
+	}
+}
+
+func TestCommandPermissionsOverride(t *testing.T) {
+	registry, err := newCommandRegistry(defaultCommands(), map[string]string{
+		getSelfTasksCmd: "executor|chief",
+	})
+	if err != nil {
+		t.Fatalf("newCommandRegistry: %v", err)
+	}
+	if !registry.allowed(getSelfTasksCmd, domain.Chief) {
+		t.Errorf("chief should be allowed to use %s", getSelfTasksCmd)
+	}
+	if registry.allowed(getSelfTasksCmd, domain.Observer) {
+		t.Errorf("observer should not be allowed to use %s", getSelfTasksCmd)
+	}
+
+	if _, err := newCommandRegistry(defaultCommands(), map[string]string{"unknown_cmd": "admin"}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+	if _, err := newCommandRegistry(defaultCommands(), map[string]string{addTaskCmd: "boss"}); err == nil {
+		t.Error("expected error for unknown role")
+	}
+}
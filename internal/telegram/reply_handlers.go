@@ -0,0 +1,152 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const replyDeadlineUsage = `Не удалось распознать новый дедлайн. Примеры: "перенеси на пятницу", "перенеси на завтра 18:00", "перенеси на 21.12.2024 12:20", "перенеси через 2 дня"`
+
+type replyHandler = func(b *Bot, ctx context.Context, message *tgbotapi.Message, task domain.Task, args string)
+
+// replyAction is the command on the task given by the reply to its notification, the first word of the reply is the verb.
+// The action is allowed to the roles of its registry command.
+type replyAction struct {
+	verbs   []string
+	command string
+	// args reports whether the rest of the reply fits the action, otherwise the reply is a comment
+	args    func(args string) bool
+	handler replyHandler
+}
+
+var replyActions = []replyAction{
+	{
+		verbs:   []string{"готово", "сделано", "выполнено", "done"},
+		command: markTaskAsDoneCommand,
+		args:    func(args string) bool { return args == "" || slices.Contains(forceFlags, strings.ToLower(args)) },
+		handler: markTaskReply(domain.MarkTaskAsDone),
+	},
+	{
+		verbs:   []string{"закрыть", "закрой", "закрыта", "close"},
+		command: markTaskAsClosedCommand,
+		args:    noReplyArgs,
+		handler: markTaskReply(domain.MarkTaskAsClosed),
+	},
+	{
+		verbs:   []string{"перенеси", "перенести", "продли", "продлить"},
+		command: changeTaskDeadlineCommand,
+		args:    func(args string) bool { return args != "" },
+		handler: (*Bot).handleDeadlineReply,
+	},
+	{
+		verbs:   []string{"файлы", "вложения"},
+		command: filesCmd,
+		args:    noReplyArgs,
+		handler: (*Bot).handleFilesReply,
+	},
+}
+
+func noReplyArgs(args string) bool {
+	return args == ""
+}
+
+// markTaskReply marks the task the same way as the task number entered after /done or /close,
+// the task is the one of the replied message, so it may belong to a team other than the active one.
+func markTaskReply(stage domain.Stage) replyHandler {
+	return func(b *Bot, ctx context.Context, message *tgbotapi.Message, task domain.Task, args string) {
+		logger := b.logger.WithField("chatID", message.Chat.ID)
+
+		text := b.markTask(ctx, logger, message.Chat.ID, task, stage, slices.Contains(forceFlags, strings.ToLower(args)))
+		if text == "" {
+			return
+		}
+		if _, err := b.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text)); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}
+}
+
+// handleReplyAction runs the action named by the first word of the reply to the task notification.
+// Returns false when the reply is not an action, then it is a comment.
+func (b *Bot) handleReplyAction(ctx context.Context, logger *log.Entry, message *tgbotapi.Message, task domain.Task) bool {
+	verb, args, _ := strings.Cut(strings.TrimSpace(message.Text), " ")
+	verb = strings.ToLower(strings.TrimFunc(verb, unicode.IsPunct))
+	args = strings.TrimSpace(args)
+
+	i := slices.IndexFunc(replyActions, func(action replyAction) bool { return slices.Contains(action.verbs, verb) })
+	if i < 0 || !replyActions[i].args(args) {
+		return false
+	}
+	action := replyActions[i]
+
+	role, err := b.storage.GetRole(ctx, task.TeamID, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get role")
+		return false
+	}
+	if !b.commands.allowed(action.command, role) {
+		if _, err := b.bot.Send(tgbotapi.NewMessage(message.Chat.ID, unknownCommandText)); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+		return true
+	}
+	action.handler(b, ctx, message, task, args)
+	return true
+}
+
+// handleDeadlineReply moves the deadline of the task: "перенеси на пятницу".
+func (b *Bot) handleDeadlineReply(ctx context.Context, message *tgbotapi.Message, task domain.Task, args string) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	now := time.Now()
+	deadline, err := domain.ParseDeadline(args, task.Deadline, now)
+	if err != nil {
+		responseMsg.Text = replyDeadlineUsage
+		return
+	}
+	if deadline.Before(now) {
+		responseMsg.Text = "Некорректное время дедлайна. Убедитесь, что вы ввели время момента в будущем в качестве дедлайна"
+		return
+	}
+	if err := b.storage.ChangeTaskDeadline(ctx, task.TeamID, task.ID, deadline); err != nil {
+		logger.WithError(err).Error("failed to change task deadline")
+		responseMsg.Text = errorReponse
+		return
+	}
+	task.Deadline = deadline
+
+	if err := b.NotifyTaskUpdate(ctx, task, message.Chat.ID); err != nil {
+		logger.WithError(err).Error("failed to notify about task update")
+	}
+	responseMsg.Text = fmt.Sprintf("Дедлайн задачи №%d перенесён на %s", task.ID, deadline.Format(domain.DeadlineLayout))
+}
+
+// handleFilesReply sends attachments of the task.
+func (b *Bot) handleFilesReply(ctx context.Context, message *tgbotapi.Message, task domain.Task, _ string) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	text := b.sendAttachments(ctx, logger, message.Chat.ID, task)
+	if text == "" {
+		return
+	}
+	if _, err := b.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text)); err != nil {
+		logger.WithError(err).Error("failed to send response")
+	}
+}
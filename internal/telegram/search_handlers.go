@@ -0,0 +1,123 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const searchPageSize = 5
+
+const searchUsage = "Использование: /search запрос, например /search сломался принтер\nИщет по названию и описанию задач с учётом словоформ"
+
+// the search message starts with the query in quotes, pages are switched by callback "search:<page>"
+// and the query is taken back from the message, callback data is too short for it
+const (
+	searchQueryOpen  = "«"
+	searchQueryClose = "»"
+)
+
+// searchPage returns text and keyboard of the page of search results, page numbers start with zero.
+func (b *Bot) searchPage(ctx context.Context, chatID int64, query string, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	filter, err := b.taskFilter(ctx, chatID, "")
+	if err != nil {
+		return "", nil, fmt.Errorf("b.taskFilter: %w", err)
+	}
+	// one more task tells whether there is the next page
+	tasks, err := b.storage.SearchTasks(ctx, filter, query, searchPageSize+1, page*searchPageSize)
+	if err != nil {
+		return "", nil, fmt.Errorf("b.storage.SearchTasks: %w", err)
+	}
+	hasNext := len(tasks) > searchPageSize
+	tasks = tasks[:min(len(tasks), searchPageSize)]
+	if err := b.fillTaskDetails(ctx, tasks); err != nil {
+		return "", nil, fmt.Errorf("b.fillTaskDetails: %w", err)
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("🔍 Поиск %s%s%s, страница %d", searchQueryOpen, html.EscapeString(query), searchQueryClose, page+1))
+	if len(tasks) == 0 {
+		builder.WriteString("\n\nНичего не найдено")
+	}
+	for _, task := range tasks {
+		builder.WriteString("\n\n")
+		builder.WriteString(task.String())
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", fmt.Sprintf("%s:%d", searchCallback, page-1)))
+	}
+	if hasNext {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("Далее ▶️", fmt.Sprintf("%s:%d", searchCallback, page+1)))
+	}
+	if len(buttons) == 0 {
+		return builder.String(), nil, nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(buttons)
+	return builder.String(), &keyboard, nil
+}
+
+// handleSearchCommand finds visible tasks by words of their title and description: /search query.
+func (b *Bot) handleSearchCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	// quotes delimit the query in the message, so they are not part of it
+	query := strings.Join(strings.Fields(strings.NewReplacer(searchQueryOpen, " ", searchQueryClose, " ").Replace(message.CommandArguments())), " ")
+	if len(domain.SearchTerms(query)) == 0 {
+		responseMsg.Text = searchUsage
+		return
+	}
+
+	text, keyboard, err := b.searchPage(ctx, message.Chat.ID, query, 0)
+	if err != nil {
+		logger.WithError(err).Error("failed to search tasks")
+		responseMsg.Text = errorReponse
+		return
+	}
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	responseMsg.Text = text
+	if keyboard != nil {
+		responseMsg.ReplyMarkup = keyboard
+	}
+}
+
+// handleSearchCallback switches the page of search results, payload is the page number.
+// Returns text of the answer to callback.
+func (b *Bot) handleSearchCallback(ctx context.Context, logger *log.Entry, message *tgbotapi.Message, payload string) string {
+	page, err := strconv.Atoi(payload)
+	if err != nil || page < 0 {
+		return errorReponse
+	}
+	_, rest, _ := strings.Cut(message.Text, searchQueryOpen)
+	query, _, found := strings.Cut(rest, searchQueryClose)
+	if !found || query == "" {
+		return errorReponse
+	}
+
+	text, keyboard, err := b.searchPage(ctx, message.Chat.ID, query, page)
+	if err != nil {
+		logger.WithError(err).Error("failed to search tasks")
+		return errorReponse
+	}
+	edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeHTML
+	edit.ReplyMarkup = keyboard
+	if _, err := b.bot.Send(edit); err != nil {
+		logger.WithError(err).Error("failed to update search message")
+	}
+	return fmt.Sprintf("Страница %d", page+1)
+}
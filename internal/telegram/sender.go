@@ -0,0 +1,324 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// sendAttempts is how many times the request is sent while Telegram answers with 429
+	sendAttempts = 3
+	// maxRetryAfter limits the pause asked by Telegram, longer pauses fail the request instead of blocking the handler
+	maxRetryAfter = time.Minute
+)
+
+// sendPriority orders requests waiting in the send queue, replies to the user go before broadcast notifications.
+type sendPriority int
+
+const (
+	interactivePriority sendPriority = iota
+	notificationPriority
+)
+
+// errSendQueueClosed fails requests waiting in the send queue after the bot is stopped.
+var errSendQueueClosed = errors.New("send queue is closed")
+
+type notificationKey struct{}
+
+// notificationContext marks requests sent with the context as broadcast notifications.
+func notificationContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, notificationKey{}, true)
+}
+
+func contextPriority(ctx context.Context) sendPriority {
+	if notification, _ := ctx.Value(notificationKey{}).(bool); notification {
+		return notificationPriority
+	}
+	return interactivePriority
+}
+
+// botAPI sends every request addressed to a chat through the send queue, so Telegram limits are not exceeded.
+// Send, Request and SendMediaGroup are replies to the user, notifications are sent with SendNotification.
+type botAPI struct {
+	*tgbotapi.BotAPI
+	queue *sendQueue
+}
+
+func newBotAPI(api *tgbotapi.BotAPI, queue *sendQueue) *botAPI {
+	return &botAPI{BotAPI: api, queue: queue}
+}
+
+func (api *botAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return api.send(interactivePriority, c)
+}
+
+// SendNotification sends the broadcast notification, it waits while replies to users are sent.
+func (api *botAPI) SendNotification(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return api.send(notificationPriority, c)
+}
+
+// SendContext sends the request with the priority of the context.
+func (api *botAPI) SendContext(ctx context.Context, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return api.send(contextPriority(ctx), c)
+}
+
+func (api *botAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return api.request(interactivePriority, c)
+}
+
+func (api *botAPI) SendMediaGroup(config tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	resp, err := api.request(interactivePriority, config)
+	if err != nil {
+		return nil, err
+	}
+	var messages []tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &messages); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return messages, nil
+}
+
+func (api *botAPI) send(priority sendPriority, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	resp, err := api.request(priority, c)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+	var message tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return message, nil
+}
+
+// request waits for its turn in the queue and repeats the request after the pause asked by Telegram with 429.
+// Requests which are not addressed to a chat, e.g. answers to callbacks, are not limited.
+func (api *botAPI) request(priority sendPriority, c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	chatID, limited := requestChatID(c)
+	var err error
+	for range sendAttempts {
+		if limited {
+			if err := api.queue.wait(chatID, priority); err != nil {
+				return nil, err
+			}
+		}
+		var resp *tgbotapi.APIResponse
+		resp, err = api.BotAPI.Request(c)
+		retryAfter, ok := tooManyRequests(err)
+		if !ok {
+			return resp, err
+		}
+		log.WithField("chatID", chatID).WithField("retryAfter", retryAfter).Warn("too many requests to telegram")
+		if retryAfter > maxRetryAfter {
+			break
+		}
+		if limited {
+			api.queue.pause(chatID, retryAfter)
+		} else {
+			time.Sleep(retryAfter)
+		}
+	}
+	return nil, err
+}
+
+// tooManyRequests returns the pause asked by Telegram when the request is rejected with 429.
+func tooManyRequests(err error) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter == 0 {
+		return 0, false
+	}
+	return time.Duration(apiErr.RetryAfter) * time.Second, true
+}
+
+// requestChatID returns the chat the request is addressed to, only such requests count against Telegram limits.
+func requestChatID(c tgbotapi.Chattable) (int64, bool) {
+	switch config := c.(type) {
+	case tgbotapi.MessageConfig:
+		return config.ChatID, true
+	case tgbotapi.PhotoConfig:
+		return config.ChatID, true
+	case tgbotapi.DocumentConfig:
+		return config.ChatID, true
+	case tgbotapi.LocationConfig:
+		return config.ChatID, true
+	case tgbotapi.MediaGroupConfig:
+		return config.ChatID, true
+	case tgbotapi.CopyMessageConfig:
+		return config.ChatID, true
+	case tgbotapi.ForwardConfig:
+		return config.ChatID, true
+	case tgbotapi.EditMessageTextConfig:
+		return config.ChatID, true
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return config.ChatID, true
+	case tgbotapi.DeleteMessageConfig:
+		return config.ChatID, true
+	case tgbotapi.PinChatMessageConfig:
+		return config.ChatID, true
+	case tgbotapi.UnpinChatMessageConfig:
+		return config.ChatID, true
+	default:
+		return 0, false
+	}
+}
+
+// sendTicket is the request waiting in the send queue.
+type sendTicket struct {
+	chatID   int64
+	priority sendPriority
+	// ready is closed when the request may be sent
+	ready chan struct{}
+}
+
+// sendQueue lets requests go one by one within the global rate and the interval between requests to one chat,
+// the waiting request of the higher priority goes first, requests of one priority go in order of arrival.
+type sendQueue struct {
+	interval     time.Duration
+	chatInterval time.Duration
+
+	mu      sync.Mutex
+	waiting []*sendTicket
+	next    time.Time
+	// chatNext is when the next request to the chat may be sent
+	chatNext map[int64]time.Time
+	wake     chan struct{}
+	// done is closed when the queue is stopped
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newSendQueue creates the queue which lets rate requests per second and one request to the chat per chatInterval.
+func newSendQueue(rate int, chatInterval time.Duration) *sendQueue {
+	queue := &sendQueue{
+		chatInterval: chatInterval,
+		chatNext:     make(map[int64]time.Time),
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	if rate > 0 {
+		queue.interval = time.Second / time.Duration(rate)
+	}
+	go queue.run()
+	return queue
+}
+
+// wait blocks until the request to the chat may be sent, it fails when the queue is closed.
+func (q *sendQueue) wait(chatID int64, priority sendPriority) error {
+	ticket := &sendTicket{chatID: chatID, priority: priority, ready: make(chan struct{})}
+	q.mu.Lock()
+	q.waiting = append(q.waiting, ticket)
+	q.mu.Unlock()
+	q.notify()
+	select {
+	case <-ticket.ready:
+		return nil
+	case <-q.done:
+		return errSendQueueClosed
+	}
+}
+
+// close stops the worker of the queue, waiting and later requests fail.
+func (q *sendQueue) close() {
+	q.closeOnce.Do(func() { close(q.done) })
+}
+
+// pause holds requests to the chat for the time asked by Telegram.
+func (q *sendQueue) pause(chatID int64, d time.Duration) {
+	q.mu.Lock()
+	if until := time.Now().Add(d); until.After(q.chatNext[chatID]) {
+		q.chatNext[chatID] = until
+	}
+	q.mu.Unlock()
+	q.notify()
+}
+
+func (q *sendQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *sendQueue) run() {
+	timer := time.NewTimer(0)
+	for {
+		delay := q.release(time.Now())
+		if delay > 0 {
+			timer.Reset(delay)
+		}
+		select {
+		case <-q.wake:
+		case <-timer.C:
+		case <-q.done:
+			timer.Stop()
+			return
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+// release lets waiting requests go while the limits allow it, returns how long to wait for the next one
+// or 0 when nothing waits.
+func (q *sendQueue) release(now time.Time) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.waiting) > 0 {
+		if now.Before(q.next) {
+			return q.next.Sub(now)
+		}
+		i := q.nextTicket(now)
+		if i < 0 {
+			return q.chatDelay(now)
+		}
+		ticket := q.waiting[i]
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+		q.next = now.Add(q.interval)
+		q.chatNext[ticket.chatID] = now.Add(q.chatInterval)
+		close(ticket.ready)
+	}
+
+	// chats which may get requests right away are not kept
+	for chatID, next := range q.chatNext {
+		if !next.After(now) {
+			delete(q.chatNext, chatID)
+		}
+	}
+	return 0
+}
+
+// nextTicket returns the index of the first waiting request of the highest priority whose chat may get it now.
+func (q *sendQueue) nextTicket(now time.Time) int {
+	best := -1
+	for i, ticket := range q.waiting {
+		if now.Before(q.chatNext[ticket.chatID]) {
+			continue
+		}
+		if best < 0 || ticket.priority < q.waiting[best].priority {
+			best = i
+		}
+	}
+	return best
+}
+
+// chatDelay returns when the first of the waiting chats may get the request.
+func (q *sendQueue) chatDelay(now time.Time) time.Duration {
+	var delay time.Duration
+	for _, ticket := range q.waiting {
+		if d := q.chatNext[ticket.chatID].Sub(now); delay == 0 || d < delay {
+			delay = d
+		}
+	}
+	return delay
+}
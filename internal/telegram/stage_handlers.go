@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"tasks_bot/internal/domain"
@@ -19,63 +20,108 @@ import (
 func (b *Bot) handleUnknownStage(ctx context.Context, message *tgbotapi.Message) {
 	logger := b.logger.WithField("chatID", message.Chat.ID)
 
-	var phone string
-	// if message.Contact == nil {
-	// 	contactButton := tgbotapi.KeyboardButton{
-	// 		Text:           "Поделиться номером телефона",
-	// 		RequestContact: true,
-	// 	}
-	// 	msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйста поделитесь своим номером телефона. Это можно сделать с помощью соответствующего пункта в меню")
-	// 	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
-	// 		tgbotapi.NewKeyboardButtonRow(contactButton),
-	// 	)
-	// 	if _, err := b.bot.Send(msg); err != nil {
-	// 		logger.WithError(err).Error("failed to send button request")
-	// 		return
-	// 	}
-	// } else {
-	// 	phone = message.Contact.PhoneNumber
-	// }
-
-	role, err := b.storage.GetRole(ctx, message.Chat.ID)
-	if err != nil && !errors.Is(err, errs.ErrNotFound) {
-		logger.WithError(err).Error("failed to get role: %w", err)
+	if err := b.storage.AddChat(ctx, message.Chat.ID, message.Chat.UserName, ""); err != nil {
+		logger.WithError(err).Error("failed to update chat info")
+		return
 	}
-	if err := b.storage.AddChat(ctx, message.Chat.ID, message.Chat.UserName, phone, role); err != nil {
-		logger.WithError(err).Error("failed to update chat info with phone number")
+
+	chat, err := b.storage.GetChatByID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get chat")
 		return
 	}
-	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+
+	// setting stage either to get phone number and save or default stage to continue work with bot
+	if !b.cfg.RequestContact || chat.Phone != "" {
+		if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to set default stage")
+		}
+		return
+	}
+
+	if err := b.requestContact(message.Chat.ID); err != nil {
+		logger.WithError(err).Error("failed to send button request")
+		return
+	}
+	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.ContactRequest); err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to set contact request stage")
 	}
+}
+
+// linkTasksByPhone assigns the chat to tasks of its teams created for its phone number,
+// it is called when the chat shares the phone and when it joins a team. Returns number of linked tasks.
+func (b *Bot) linkTasksByPhone(ctx context.Context, chatID int64) int {
+	logger := b.logger.WithField("chatID", chatID)
+
+	chat, err := b.storage.GetChatByID(ctx, chatID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get chat")
+		return 0
+	}
+	if chat.Phone == "" {
+		return 0
+	}
+	linked, err := b.storage.LinkTasksByPhone(ctx, chat.Phone, chatID)
+	if err != nil {
+		logger.WithError(err).Error("failed to link tasks by phone")
+		return 0
+	}
+	if linked > 0 {
+		// linked tasks may belong to any team of the chat
+		if err := b.RefreshDashboards(ctx); err != nil {
+			logger.WithError(err).Warn("failed to refresh dashboards")
+		}
+	}
+	return linked
+}
 
-	// // setting stage either to get phone number and save or default stage to continue work with bot
-	// if message.Contact == nil {
-	// 	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.ContactRequest); err != nil && !errors.Is(err, errs.ErrNotFound) {
-	// 		logger.WithError(err).Error("failed to set contact request stage")
-	// 	}
-	// } else {
-	// 	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
-	// 		logger.WithError(err).Error("failed to set contact request stage")
-	// 	}
-	// }
+func (b *Bot) requestContact(chatID int64) error {
+	contactButton := tgbotapi.NewKeyboardButtonContact("Поделиться номером телефона")
+	msg := tgbotapi.NewMessage(chatID, "Пожалуйста поделитесь своим номером телефона. Это можно сделать с помощью кнопки под полем ввода")
+	msg.ReplyMarkup = tgbotapi.NewOneTimeReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(contactButton),
+	)
+	if _, err := b.bot.Send(msg); err != nil {
+		return fmt.Errorf("b.bot.Send: %w", err)
+	}
+	return nil
 }
 
+// handleContactRequest saves sender's own phone number and links tasks that were given to this number.
 func (b *Bot) handleContactRequest(ctx context.Context, message *tgbotapi.Message) {
 	logger := b.logger.WithField("chatID", message.Chat.ID)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Спасибо!")
-	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
-	if _, err := b.bot.Send(msg); err != nil {
-		logger.WithError(err).Error("failed to remove keyboard")
+	if message.Contact == nil || message.From == nil || message.Contact.UserID != message.From.ID {
+		if err := b.requestContact(message.Chat.ID); err != nil {
+			logger.WithError(err).Error("failed to send button request")
+		}
 		return
 	}
-	if message.Contact != nil {
-		if err := b.storage.AddChat(ctx, message.Chat.ID, message.Chat.UserName, message.Contact.PhoneNumber, domain.UnknownRole); err != nil {
-			logger.WithError(err).Error("failed to update chat info with phone number")
-			return
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "Спасибо!")
+	responseMsg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
 		}
+	}()
+
+	phone, err := domain.NormalizePhone(message.Contact.PhoneNumber)
+	if err != nil {
+		logger.WithError(err).Warn("failed to normalize phone")
+		responseMsg.Text = "Не удалось распознать номер телефона"
+		return
 	}
+	if err := b.storage.AddChat(ctx, message.Chat.ID, message.Chat.UserName, phone); err != nil {
+		logger.WithError(err).Error("failed to update chat info with phone number")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if linked := b.linkTasksByPhone(ctx, message.Chat.ID); linked > 0 {
+		responseMsg.Text = fmt.Sprintf("Спасибо! Найдено задач, назначенных на ваш номер: %d", linked)
+	}
+
 	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil {
 		logger.WithError(err).Error("failed to set default stage")
 		return
@@ -96,41 +142,71 @@ func (b *Bot) handleBecomeStage(ctx context.Context, message *tgbotapi.Message,
 		logger.WithError(err).Error("failed to delete password message")
 	}
 
+	// the attempt is counted before the password is checked, so concurrent guesses can't outrun the lockout
+	now := time.Now()
+	attempts, err := b.storage.RegisterPasswordFailure(ctx, message.Chat.ID, now)
+	if err != nil {
+		logger.WithError(err).Error("failed to register password attempt")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if attempts.IsLocked(now) {
+		responseMsg.Text = lockedOutText(attempts)
+		if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to set stage")
+		}
+		return
+	}
+	if attempts.Failures > b.cfg.PasswordMaxAttempts {
+		// concurrent attempts have used up the limit, the password is not checked
+		responseMsg.Text = b.lockPasswordAttempts(ctx, message, attempts, now)
+		return
+	}
+
 	var role domain.Role
+	var passwordHash []byte
 	switch stage {
 	case domain.BecomeChief:
-		if bcrypt.CompareHashAndPassword(chiefPasswordHash, []byte(message.Text)) != nil {
-			responseMsg.Text = "Вы ввели неверный пароль. Попробуйте ещё"
-			return
-		}
-		role = domain.Chief
-
+		role, passwordHash = domain.Chief, chiefPasswordHash
 	case domain.BecomeExecutor:
-		if bcrypt.CompareHashAndPassword(executorPasswordHash, []byte(message.Text)) != nil {
-			responseMsg.Text = "Вы ввели неверный пароль. Попробуйте ещё"
-			return
-		}
-		role = domain.Executor
-
+		role, passwordHash = domain.Executor, executorPasswordHash
 	case domain.BecomeObserver:
-		if bcrypt.CompareHashAndPassword(observerPasswordHash, []byte(message.Text)) != nil {
-			responseMsg.Text = "Вы ввели неверный пароль. Попробуйте ещё"
-			return
-		}
-		role = domain.Observer
-
+		role, passwordHash = domain.Observer, observerPasswordHash
 	case domain.BecomeAdmin:
-		if bcrypt.CompareHashAndPassword(adminPasswordHash, []byte(message.Text)) != nil {
+		role, passwordHash = domain.Admin, adminPasswordHash
+	}
+
+	if bcrypt.CompareHashAndPassword(passwordHash, []byte(message.Text)) != nil {
+		if !attempts.ExceedsFailures(b.cfg.PasswordMaxAttempts) {
 			responseMsg.Text = "Вы ввели неверный пароль. Попробуйте ещё"
 			return
 		}
-		role = domain.Admin
+		responseMsg.Text = b.lockPasswordAttempts(ctx, message, attempts, now)
+		return
+	}
+	if err := b.storage.ResetPasswordAttempts(ctx, message.Chat.ID); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to reset password attempts")
 	}
 
-	if err := b.storage.SetRole(ctx, message.Chat.ID, role); err != nil && !errors.Is(err, errs.ErrNotFound) {
+	// passwords are common for the whole bot, so they grant roles only in the default team
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if teamID != domain.DefaultTeamID {
+		responseMsg.Text = passwordTeamText
+		if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("failed to set stage")
+		}
+		return
+	}
+	if err := b.storage.SetRole(ctx, teamID, message.Chat.ID, role); err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to set role with")
 		responseMsg.Text = errorReponse
 	}
+	b.linkTasksByPhone(ctx, message.Chat.ID)
 	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to set stage")
 		responseMsg.Text = errorReponse
@@ -140,6 +216,55 @@ func (b *Bot) handleBecomeStage(ctx context.Context, message *tgbotapi.Message,
 	}
 }
 
+// lockPasswordAttempts locks the chat after too many failed password attempts and alerts admin about it,
+// the chat locked by a concurrent attempt is not locked again. Returns response text for the user.
+func (b *Bot) lockPasswordAttempts(ctx context.Context, message *tgbotapi.Message, attempts domain.PasswordAttempts, now time.Time) string {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to set stage")
+	}
+
+	lockedUntil := now.Add(attempts.Lockout(b.cfg.PasswordLockout, b.cfg.PasswordMaxLockout))
+	locked, err := b.storage.LockPasswordAttempts(ctx, message.Chat.ID, attempts.Lockouts, lockedUntil)
+	if err != nil {
+		logger.WithError(err).Error("failed to lock password attempts")
+		return errorReponse
+	}
+	if !locked {
+		current, err := b.storage.GetPasswordAttempts(ctx, message.Chat.ID)
+		if err != nil {
+			logger.WithError(err).Error("failed to get password attempts")
+			return errorReponse
+		}
+		return lockedOutText(current)
+	}
+	attempts.Failures = 0
+	attempts.Lockouts++
+	attempts.LockedUntil = lockedUntil
+
+	logger.WithField("lockouts", attempts.Lockouts).Warn("chat is locked out after failed password attempts")
+	if b.cfg.AdminID != 0 {
+		alert := tgbotapi.NewMessage(b.cfg.AdminID, fmt.Sprintf(
+			"⚠️ Чат %d (@%s) заблокирован до %s после %d неверных попыток ввода пароля. Снять блокировку: /%s %d",
+			message.Chat.ID,
+			message.Chat.UserName,
+			attempts.LockedUntil.Format(domain.DeadlineLayout),
+			b.cfg.PasswordMaxAttempts,
+			unlockCmd,
+			message.Chat.ID,
+		))
+		if _, err := b.bot.SendNotification(alert); err != nil {
+			logger.WithError(err).Error("failed to alert admin")
+		}
+	}
+	return lockedOutText(attempts)
+}
+
+func lockedOutText(attempts domain.PasswordAttempts) string {
+	return fmt.Sprintf("Слишком много неверных попыток. Попробуйте снова после %s", attempts.LockedUntil.Format(domain.DeadlineLayout))
+}
+
 func (b *Bot) handleAddTaskStage(ctx context.Context, message *tgbotapi.Message, stage domain.Stage) {
 	logger := b.logger.WithField("chatID", message.Chat.ID)
 
@@ -162,25 +287,39 @@ func (b *Bot) handleAddTaskStage(ctx context.Context, message *tgbotapi.Message,
 		responseMsg.Text = "Введите ник исполнителя в формате @username"
 
 	case domain.AddTaskUser:
-		nextStage = domain.AddTaskDeadline
-		userContact := strings.Trim(message.Text, "@")
-
-		executorChat, err := b.storage.GetChat(ctx, userContact, userContact)
-		if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		nextStage = domain.AddTaskPriority
+		userContact, chatID, err := b.findExecutor(ctx, message.Text)
+		if err != nil {
 			responseMsg.Text = errorReponse
 			logger.WithError(err).Error("failed to get chat")
 			return
 		}
-		var chatID int64
-		if executorChat != nil {
-			chatID = executorChat.ID
-		}
 		if err = b.storage.SetTaskInProgressUser(ctx, message.Chat.ID, userContact, chatID); err != nil {
 			logger.WithError(err).Error("failed to set task in progress user for the chat")
 			responseMsg.Text = errorReponse
 			return
 		}
-		responseMsg.Text = "Введите дедлайн задачи в формате 21.12.2024 12:20:00"
+		responseMsg.Text = "Выберите приоритет задачи"
+		responseMsg.ReplyMarkup = priorityKeyboard()
+
+	case domain.AddTaskPriority:
+		nextStage = domain.AddTaskDeadline
+		priority, err := domain.ParsePriority(message.Text)
+		if err != nil {
+			responseMsg.Text = "Выберите приоритет кнопкой под полем ввода"
+			responseMsg.ReplyMarkup = priorityKeyboard()
+			return
+		}
+		if err = b.storage.SetTaskInProgressPriority(ctx, message.Chat.ID, priority); err != nil {
+			logger.WithError(err).Error("failed to set task in progress priority for the chat")
+			responseMsg.Text = errorReponse
+			return
+		}
+		responseMsg.Text = fmt.Sprintf(
+			"Введите дедлайн задачи в формате 21.12.2024 12:20:00 или \"%s\", чтобы поставить срок по SLA приоритета: %s",
+			slaDeadline, b.policy.Deadline(priority, time.Now()).Format(domain.DeadlineLayout),
+		)
+		responseMsg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
 
 	case domain.AddTaskDeadline:
 		nextStage = domain.Default
@@ -200,6 +339,37 @@ func (b *Bot) handleAddTaskStage(ctx context.Context, message *tgbotapi.Message,
 	}
 }
 
+// slaDeadline is entered instead of deadline to take it from SLA of the task priority
+const slaDeadline = "-"
+
+// priorityKeyboard offers priorities from the most important one.
+func priorityKeyboard() tgbotapi.ReplyKeyboardMarkup {
+	buttons := make([]tgbotapi.KeyboardButton, 0, len(domain.Priorities))
+	for _, priority := range domain.Priorities {
+		buttons = append(buttons, tgbotapi.NewKeyboardButton(priority.Label()))
+	}
+	return tgbotapi.NewOneTimeReplyKeyboard(tgbotapi.NewKeyboardButtonRow(buttons...))
+}
+
+// findExecutor normalizes executor's username or phone and returns chat id of the executor
+// if the executor has already started the bot, otherwise it is 0.
+func (b *Bot) findExecutor(ctx context.Context, raw string) (string, int64, error) {
+	userContact := strings.Trim(strings.TrimSpace(raw), "@")
+	if phone, err := domain.NormalizePhone(userContact); err == nil {
+		userContact = phone
+	}
+
+	executorChat, err := b.storage.GetChat(ctx, userContact, userContact)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return "", 0, fmt.Errorf("b.storage.GetChat: %w", err)
+	}
+	var chatID int64
+	if executorChat != nil {
+		chatID = executorChat.ID
+	}
+	return userContact, chatID, nil
+}
+
 // handleAddTaskDeadline parses deadline timestamp, creates task, notify observers and executor.
 // returns bool if external call should return after
 func (b *Bot) handleAddTaskDeadline(
@@ -210,6 +380,9 @@ func (b *Bot) handleAddTaskDeadline(
 	responseMsg *tgbotapi.MessageConfig,
 ) bool {
 	timestamp, err := time.ParseInLocation(domain.DeadlineLayout, message.Text, time.Local)
+	if strings.TrimSpace(message.Text) == slaDeadline {
+		timestamp, err = b.policy.Deadline(taskInProgress.Priority, time.Now()), nil
+	}
 	if err != nil {
 		responseMsg.Text = "Некорректный формат даты-времени, проверьте, что вы вводите дату и время в формате, похожем на 21.12.2024 12:20:00"
 		return true
@@ -220,27 +393,75 @@ func (b *Bot) handleAddTaskDeadline(
 	}
 	taskInProgress.Deadline = timestamp
 
-	taskID, err := b.storage.AddTask(ctx, *taskInProgress)
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return true
+	}
+	taskInProgress.TeamID = teamID
+
+	task, err := b.createTask(ctx, logger, *taskInProgress, nil, message.Chat.ID)
 	if err != nil {
 		logger.WithError(err).Error("failed to add task")
 		responseMsg.Text = errorReponse
 		return true
 	}
-	taskInProgress.ID = taskID
 
 	responseMsg.ParseMode = tgbotapi.ModeHTML
-	responseMsg.Text = fmt.Sprintf("Вы успешно добавили задачу: \n\n%s", taskInProgress)
+	responseMsg.Text = fmt.Sprintf("Вы успешно добавили задачу: \n\n%s", task)
+	return false
+}
+
+// createTask saves the task with its checklist and notifies observers and executor about it.
+func (b *Bot) createTask(ctx context.Context, logger *log.Entry, task domain.Task, checklist []string, creatorChatID int64) (domain.Task, error) {
+	task.CreatedBy = creatorChatID
+	taskID, err := b.storage.AddTask(ctx, task)
+	if err != nil {
+		return domain.Task{}, fmt.Errorf("b.storage.AddTask: %w", err)
+	}
+	task.ID = taskID
+
+	// files sent during the task creation dialog
+	if err := b.storage.BindAttachments(ctx, creatorChatID, taskID); err != nil {
+		logger.WithError(err).Error("failed to bind attachments")
+	}
+
+	// executor is the first participant of the task, others are added with /add_participant
+	if task.ExecutorChatID != 0 {
+		participant := domain.TaskParticipant{
+			TaskID: taskID,
+			ChatID: task.ExecutorChatID,
+			Role:   domain.ExecutorParticipant,
+		}
+		if err := b.storage.AddTaskParticipant(ctx, participant); err != nil {
+			logger.WithError(err).Error("failed to add executor to task participants")
+		}
+	}
+	// hashtags of the title become tags of the task, they may be replaced with /set_tags
+	if task.Tags = domain.ParseTags(task.Title); len(task.Tags) > 0 {
+		if err := b.storage.SetTaskTags(ctx, task.TeamID, taskID, task.Tags); err != nil {
+			logger.WithError(err).Error("failed to set task tags")
+		}
+	}
+	for _, title := range checklist {
+		if _, err := b.storage.AddChecklistItem(ctx, domain.ChecklistItem{TaskID: taskID, Title: title}); err != nil {
+			logger.WithError(err).Error("failed to add checklist item")
+			continue
+		}
+		task.Progress.Items++
+	}
 
-	if err := b.NotifyObservers(ctx, *taskInProgress, message.Chat.ID); err != nil {
+	if err := b.NotifyTaskCreated(ctx, task, creatorChatID, task.ExecutorChatID); err != nil {
 		logger.WithError(err).Error("failed to notify observers")
 	}
 	// if executor's chat id set - send a notification about created task
-	if taskInProgress.ExecutorChatID != 0 {
-		if err := b.NotifyExecutor(ctx, *taskInProgress, message.Chat.ID); err != nil {
+	if task.ExecutorChatID != 0 {
+		if err := b.NotifyExecutor(ctx, task, creatorChatID); err != nil {
 			logger.WithError(err).Error("failed to notify executor")
 		}
 	}
-	return false
+	return task, nil
 }
 
 func (b *Bot) handleMarkTaskStage(ctx context.Context, message *tgbotapi.Message, stage domain.Stage) {
@@ -248,50 +469,158 @@ func (b *Bot) handleMarkTaskStage(ctx context.Context, message *tgbotapi.Message
 
 	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
 	defer func() {
+		// request of the proof is sent as a message about the task, so replies to it are matched
+		if responseMsg.Text == "" {
+			return
+		}
 		if _, err := b.bot.Send(responseMsg); err != nil {
 			logger.WithError(err).Error("failed to send response")
 		}
 	}()
 
-	taskID, err := strconv.Atoi(message.Text)
+	// task with open subtasks is marked as done only with "force" after its number
+	rawTaskID, flag, _ := strings.Cut(strings.TrimSpace(message.Text), " ")
+	forced := slices.Contains(forceFlags, strings.ToLower(strings.TrimSpace(flag)))
+	taskID, err := strconv.Atoi(rawTaskID)
 	if err != nil {
 		responseMsg.Text = "Некорректный номер задачи, должно быть число"
 		return
 	}
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
 
-	var newTaskStatus domain.TaskStatus
+	task, err := b.storage.GetTask(ctx, teamID, taskID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = fmt.Sprintf("Задача с номером %d не найдена", taskID)
+			return
+		}
+		logger.WithError(err).Error("b.storage.GetTask")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	responseMsg.Text = b.markTask(ctx, logger, message.Chat.ID, task, stage, forced)
+}
+
+// markTask marks the task as done or closed by the chat, the task with open subtasks is marked as done only if forced.
+// Returns text of the response, it is empty when the proof of completion is requested instead.
+func (b *Bot) markTask(ctx context.Context, logger *log.Entry, chatID int64, task domain.Task, stage domain.Stage, forced bool) string {
 	switch stage {
 	case domain.MarkTaskAsClosed:
-		if err := b.storage.MarkTaskAsClosed(ctx, taskID); err != nil {
-			if errors.Is(err, errs.ErrNotFound) {
-				responseMsg.Text = fmt.Sprintf("Задача с номером %d не найдена", taskID)
-				return
-			}
+		if err := b.storage.MarkTaskAsClosed(ctx, task.TeamID, task.ID); err != nil {
 			logger.WithError(err).Error("b.storage.MarkTaskAsClosed")
-			responseMsg.Text = errorReponse
-			return
+			return errorReponse
 		}
-		newTaskStatus = domain.ClosedTask
+		task.Closed = true
+		return b.taskStatusChanged(ctx, logger, chatID, task)
 
 	case domain.MarkTaskAsDone:
-		if err := b.storage.MarkTaskAsDone(ctx, taskID); err != nil {
-			if errors.Is(err, errs.ErrNotFound) {
-				responseMsg.Text = fmt.Sprintf("Задача с номером %d не найдена", taskID)
-				return
+		allowed, err := b.mayFinishTask(ctx, task, chatID)
+		if err != nil {
+			logger.WithError(err).Error("failed to check task access")
+			return errorReponse
+		}
+		if !allowed {
+			return finishForbiddenText
+		}
+		if !forced {
+			progress, err := b.storage.GetTasksProgress(ctx, []int{task.ID})
+			if err != nil {
+				logger.WithError(err).Error("b.storage.GetTasksProgress")
+				return errorReponse
+			}
+			if taskProgress := progress[task.ID]; taskProgress.HasOpenSubtasks() {
+				return fmt.Sprintf(
+					"У задачи №%d не завершены подзадачи (%d/%d). Чтобы всё равно отметить её выполненной, введите \"%d %s\"",
+					task.ID, taskProgress.SubtasksDone, taskProgress.Subtasks, task.ID, forceFlags[0],
+				)
 			}
-			logger.WithError(err).Error("b.storage.MarkTaskAsDone")
-			responseMsg.Text = errorReponse
-			return
 		}
-		newTaskStatus = domain.DoneTask
+		if task.ProofRequired {
+			proofs, err := b.storage.GetProofs(ctx, task.ID)
+			if err != nil {
+				logger.WithError(err).Error("b.storage.GetProofs")
+				return errorReponse
+			}
+			if !slices.ContainsFunc(proofs, func(proof domain.Proof) bool { return proof.ChatID == chatID }) {
+				return b.requestProof(ctx, logger, chatID, task)
+			}
+		}
+		return b.finishTask(ctx, logger, chatID, task)
 	}
+	return ""
+}
 
-	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+// finishTask marks the task as done by the chat, with several executors the task may wait for the others.
+// Returns text of the response.
+func (b *Bot) finishTask(ctx context.Context, logger *log.Entry, chatID int64, task domain.Task) string {
+	if b.doneMode == domain.DoneByAllExecutors {
+		// chats which may finish the task, but are not its executors, e.g. chiefs, mark it as done at once
+		remaining, err := b.storage.MarkParticipantDone(ctx, task.ID, chatID)
+		if err != nil && !errors.Is(err, errs.ErrNotFound) {
+			logger.WithError(err).Error("b.storage.MarkParticipantDone")
+			return errorReponse
+		}
+		if err == nil && remaining > 0 {
+			b.taskChanged(ctx, task.TeamID)
+			if err := b.storage.SetStage(ctx, chatID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
+				logger.WithError(err).Error("failed to set next stage")
+			}
+			return fmt.Sprintf("Ваша часть задачи №%d отмечена выполненной. Задача будет выполнена, когда закончат остальные исполнители: %d", task.ID, remaining)
+		}
+	}
+	if err := b.storage.MarkTaskAsDone(ctx, task.TeamID, task.ID); err != nil {
+		logger.WithError(err).Error("b.storage.MarkTaskAsDone")
+		return errorReponse
+	}
+	task.Done = true
+	return b.taskStatusChanged(ctx, logger, chatID, task)
+}
+
+const finishForbiddenText = "Отметить задачу выполненной могут только её исполнители, автор, шеф и администратор"
+
+// mayFinishTask reports whether the chat may mark the task as done and tick off its checklist:
+// chiefs and admins may do it with any task, others only with tasks they created or execute.
+func (b *Bot) mayFinishTask(ctx context.Context, task domain.Task, chatID int64) (bool, error) {
+	if task.CreatedBy == chatID || task.ExecutorChatID == chatID {
+		return true, nil
+	}
+	role, err := b.storage.GetRole(ctx, task.TeamID, chatID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return false, fmt.Errorf("b.storage.GetRole: %w", err)
+	}
+	if role == domain.Chief || role == domain.Admin {
+		return true, nil
+	}
+	participants, err := b.storage.GetTaskParticipants(ctx, task.ID)
+	if err != nil {
+		return false, fmt.Errorf("b.storage.GetTaskParticipants: %w", err)
+	}
+	return slices.ContainsFunc(participants, func(participant domain.TaskParticipant) bool {
+		return participant.ChatID == chatID && participant.Role == domain.ExecutorParticipant
+	}), nil
+}
+
+// taskStatusChanged finishes the dialog of the chat and notifies about the new status of the task.
+// Returns text of the response.
+func (b *Bot) taskStatusChanged(ctx context.Context, logger *log.Entry, chatID int64, task domain.Task) string {
+	if err := b.storage.SetStage(ctx, chatID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to set next stage")
-		responseMsg.Text = errorReponse
-		return
+		return errorReponse
+	}
+
+	if err := b.NotifyTaskUpdate(ctx, task, chatID); err != nil {
+		logger.WithError(err).Error("failed to notify about task update")
 	}
-	responseMsg.Text = fmt.Sprintf("Статус задачи успешно изменен на \"%s\"", newTaskStatus)
+	if err := b.NotifyUnblocked(ctx, task); err != nil {
+		logger.WithError(err).Error("failed to notify about unblocked tasks")
+	}
+	return fmt.Sprintf("Статус задачи успешно изменен на \"%s\"", task.GetStatus())
 }
 
 func (b *Bot) handleChangeDeadlineStage(ctx context.Context, message *tgbotapi.Message) {
@@ -319,8 +648,18 @@ func (b *Bot) handleChangeDeadlineStage(ctx context.Context, message *tgbotapi.M
 		responseMsg.Text = "Некорректный формат даты-времени, проверьте, что вы вводите дату и время в формате, похожем на 21.12.2024 12:20:00"
 		return
 	}
+	if deadline.Before(time.Now()) {
+		responseMsg.Text = "Некорректное время дедлайна. Убедитесь, что вы ввели время момента в будущем в качестве дедлайна"
+		return
+	}
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
 
-	if err := b.storage.ChangeTaskDeadline(ctx, taskID, deadline); err != nil {
+	if err := b.storage.ChangeTaskDeadline(ctx, teamID, taskID, deadline); err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			responseMsg.Text = fmt.Sprintf("Задача с номером %d не найдена", taskID)
 			return
@@ -330,6 +669,13 @@ func (b *Bot) handleChangeDeadlineStage(ctx context.Context, message *tgbotapi.M
 		return
 	}
 
+	task, err := b.storage.GetTask(ctx, teamID, taskID)
+	if err != nil {
+		logger.WithError(err).Error("b.storage.GetTask")
+	} else if err := b.NotifyTaskUpdate(ctx, task, message.Chat.ID); err != nil {
+		logger.WithError(err).Error("failed to notify about task update")
+	}
+
 	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to set next stage")
 		responseMsg.Text = errorReponse
@@ -337,7 +683,7 @@ func (b *Bot) handleChangeDeadlineStage(ctx context.Context, message *tgbotapi.M
 	}
 
 	responseMsg.ParseMode = tgbotapi.ModeHTML
-	responseMsg.Text = fmt.Sprintf("Дедлайн задачи №%d успешно изменен на %s", taskID, deadline)
+	responseMsg.Text = fmt.Sprintf("Дедлайн задачи №%d успешно изменен на %s", taskID, deadline.Format(domain.DeadlineLayout))
 }
 
 func (b *Bot) handleDeleteTaskStage(ctx context.Context, message *tgbotapi.Message) {
@@ -355,14 +701,41 @@ func (b *Bot) handleDeleteTaskStage(ctx context.Context, message *tgbotapi.Messa
 		responseMsg.Text = "Некорректный номер задачи, должно быть число"
 		return
 	}
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
 
-	if err := b.storage.DeleteTask(ctx, taskID); err != nil {
-		logger.WithError(err).Error("b.storage.MarkTaskAsClosed")
+	task, err := b.storage.GetTask(ctx, teamID, taskID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = fmt.Sprintf("Задача с номером %d не найдена", taskID)
+			return
+		}
+		logger.WithError(err).Error("b.storage.GetTask")
+		responseMsg.Text = errorReponse
+		return
+	}
+	cards, err := b.storage.GetTaskCards(ctx, taskID)
+	if err != nil {
+		logger.WithError(err).Error("b.storage.GetTaskCards")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	if err := b.storage.DeleteTask(ctx, teamID, taskID); err != nil {
+		logger.WithError(err).Error("b.storage.DeleteTask")
 		responseMsg.Text = errorReponse
 		return
 	}
 	responseMsg.Text = "Задача успешно удалена"
 
+	if err := b.NotifyTaskDeleted(ctx, task, cards); err != nil {
+		logger.WithError(err).Error("failed to notify about deleted task")
+	}
+
 	if err := b.storage.SetStage(ctx, message.Chat.ID, domain.Default); err != nil && !errors.Is(err, errs.ErrNotFound) {
 		logger.WithError(err).Error("failed to set next stage")
 		responseMsg.Text = errorReponse
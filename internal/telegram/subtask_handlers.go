@@ -0,0 +1,286 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"tasks_bot/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const noParent = "-"
+
+// forceFlags follow task number to mark task with open subtasks as done
+var forceFlags = []string{"force", "принудительно"}
+
+// fillTaskDetails sets progress of subtasks and checklists and unfinished blockers to the tasks shown to user.
+func (b *Bot) fillTaskDetails(ctx context.Context, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	taskIDs := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	progress, err := b.storage.GetTasksProgress(ctx, taskIDs)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetTasksProgress: %w", err)
+	}
+	blockers, err := b.storage.GetBlockers(ctx, taskIDs)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetBlockers: %w", err)
+	}
+	tags, err := b.storage.GetTasksTags(ctx, taskIDs)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetTasksTags: %w", err)
+	}
+	for i := range tasks {
+		tasks[i].Progress = progress[tasks[i].ID]
+		tasks[i].BlockedBy = domain.OpenBlockers(blockers[tasks[i].ID])
+		tasks[i].Tags = tags[tasks[i].ID]
+	}
+	return nil
+}
+
+// handleSetParentCommand makes the task a subtask of another one: /set_parent task parent|-.
+func (b *Bot) handleSetParentCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		responseMsg.Text = setParentUsage
+		return
+	}
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, args[0])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	var parentID int
+	if args[1] != noParent {
+		parent, text := b.findTask(ctx, logger, message.Chat.ID, args[1])
+		if text != "" {
+			responseMsg.Text = text
+			return
+		}
+		// walk up from the new parent to be sure the task is not its ancestor
+		for ancestor := parent; ; {
+			if ancestor.ID == task.ID {
+				responseMsg.Text = fmt.Sprintf("Задача №%d не может стать подзадачей задачи №%d", task.ID, parent.ID)
+				return
+			}
+			if ancestor.ParentID == 0 {
+				break
+			}
+			var err error
+			if ancestor, err = b.storage.GetTask(ctx, task.TeamID, ancestor.ParentID); err != nil {
+				logger.WithError(err).Error("failed to get ancestor task")
+				responseMsg.Text = errorReponse
+				return
+			}
+		}
+		parentID = parent.ID
+	}
+
+	if err := b.storage.SetTaskParent(ctx, task.TeamID, task.ID, parentID); err != nil {
+		logger.WithError(err).Error("failed to set task parent")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	if parentID == 0 {
+		responseMsg.Text = fmt.Sprintf("Задача №%d больше не является подзадачей", task.ID)
+		return
+	}
+	responseMsg.Text = fmt.Sprintf("Задача №%d стала подзадачей задачи №%d", task.ID, parentID)
+}
+
+func (b *Bot) handleSubtasksCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, strings.TrimSpace(message.CommandArguments()))
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	subtasks, err := b.storage.GetSubtasks(ctx, task.TeamID, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get subtasks")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if len(subtasks) == 0 {
+		responseMsg.Text = fmt.Sprintf("У задачи №%d нет подзадач", task.ID)
+		return
+	}
+	if err := b.fillTaskDetails(ctx, subtasks); err != nil {
+		logger.WithError(err).Error("failed to get subtasks progress")
+		responseMsg.Text = errorReponse
+		return
+	}
+	domain.SortByPriority(subtasks)
+
+	builder := strings.Builder{}
+	for _, subtask := range subtasks {
+		builder.WriteString(subtask.String())
+		builder.WriteString("\n\n")
+	}
+	responseMsg.Text = builder.String()
+}
+
+// handleAddChecklistItemCommand adds item to checklist of the task: /add_item task text.
+func (b *Bot) handleAddChecklistItemCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	rawTaskID, title, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		responseMsg.Text = "Использование: /add_item номер_задачи текст пункта"
+		return
+	}
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, rawTaskID)
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	if _, err := b.storage.AddChecklistItem(ctx, domain.ChecklistItem{TaskID: task.ID, Title: title}); err != nil {
+		logger.WithError(err).Error("failed to add checklist item")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	responseMsg.Text = fmt.Sprintf("Пункт добавлен в чек-лист задачи №%d", task.ID)
+}
+
+func (b *Bot) handleChecklistCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, strings.TrimSpace(message.CommandArguments()))
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+
+	items, err := b.storage.GetChecklist(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get checklist")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if len(items) == 0 {
+		responseMsg.Text = fmt.Sprintf("У задачи №%d нет чек-листа", task.ID)
+		return
+	}
+	responseMsg.Text = checklistText(task, items)
+	responseMsg.ReplyMarkup = checklistKeyboard(task.ID, items)
+}
+
+// handleChecklistCallback ticks off checklist item, payload is "<task id>:<item id>".
+// Returns text of the answer to callback.
+func (b *Bot) handleChecklistCallback(ctx context.Context, logger *log.Entry, message *tgbotapi.Message, payload string) string {
+	rawTaskID, rawItemID, _ := strings.Cut(payload, ":")
+	itemID, err := strconv.Atoi(rawItemID)
+	if err != nil {
+		return errorReponse
+	}
+	task, text := b.callbackTask(ctx, logger, message.Chat.ID, rawTaskID)
+	if text != "" {
+		return text
+	}
+	allowed, err := b.mayFinishTask(ctx, task, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to check task access")
+		return errorReponse
+	}
+	if !allowed {
+		return "Отмечать пункты чек-листа могут только исполнители задачи, её автор, шеф и администратор"
+	}
+
+	items, err := b.storage.GetChecklist(ctx, task.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get checklist")
+		return errorReponse
+	}
+	i := slices.IndexFunc(items, func(item domain.ChecklistItem) bool { return item.ID == itemID })
+	if i < 0 {
+		return "Пункт чек-листа не найден"
+	}
+	items[i].Done = !items[i].Done
+	if err := b.storage.SetChecklistItemDone(ctx, task.ID, itemID, items[i].Done); err != nil {
+		logger.WithError(err).Error("failed to set checklist item done")
+		return errorReponse
+	}
+	b.taskChanged(ctx, task.TeamID)
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, checklistText(task, items), checklistKeyboard(task.ID, items))
+	edit.ParseMode = tgbotapi.ModeHTML
+	if _, err := b.bot.Send(edit); err != nil {
+		logger.WithError(err).Error("failed to update checklist message")
+	}
+
+	if items[i].Done {
+		return "Пункт отмечен выполненным"
+	}
+	return "Отметка снята"
+}
+
+func checklistText(task domain.Task, items []domain.ChecklistItem) string {
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return fmt.Sprintf("<b>Чек-лист задачи №%d</b>\n%s\n\nВыполнено: %d/%d", task.ID, html.EscapeString(task.Title), done, len(items))
+}
+
+func checklistKeyboard(taskID int, items []domain.ChecklistItem) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(items))
+	for _, item := range items {
+		data := fmt.Sprintf("%s:%d:%d", checklistCallback, taskID, item.ID)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(item.String(), data)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+const setParentUsage = "Использование: /set_parent номер_задачи номер_родительской_задачи\nЧтобы задача перестала быть подзадачей, укажите \"-\" вместо родительской задачи"
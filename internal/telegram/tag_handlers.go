@@ -0,0 +1,270 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const filterHelp = `Фильтр состоит из слов:
+#тег - задачи со всеми указанными тегами
+open, done, closed, overdue или открытые, выполненные, закрытые, просроченные
+low, normal, high, critical или низкий, обычный, высокий, критический
+my или мои - задачи, где вы исполнитель`
+
+const tasksUsage = `Использование: /tasks [фильтр], например /tasks #склад open overdue
+
+` + filterHelp
+
+const viewUsage = `Использование:
+/view - сохранённые фильтры
+/view имя - задачи по сохранённому фильтру
+/view save имя фильтр - сохранить или заменить фильтр
+/view delete имя - удалить фильтр
+
+` + filterHelp
+
+const setTagsUsage = "Использование: /set_tags номер_задачи #тег ... - заменить теги задачи, без тегов теги удаляются"
+
+// taskFilter parses the query and restricts it to the tasks the chat may see, like visibleTasks does:
+// chiefs see only tasks of their subordinates and executors only their own tasks.
+// Invalid query gives errs.ErrInvalidInput.
+func (b *Bot) taskFilter(ctx context.Context, chatID int64, query string) (domain.TaskFilter, error) {
+	chat, err := b.storage.GetChatByID(ctx, chatID)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			return domain.TaskFilter{}, fmt.Errorf("b.storage.GetChatByID: %w", err)
+		}
+		chat = &domain.Chat{ID: chatID, TeamID: domain.DefaultTeamID}
+	}
+	filter, err := domain.ParseTaskFilter(query, *chat)
+	if err != nil {
+		return domain.TaskFilter{}, fmt.Errorf("domain.ParseTaskFilter: %w", err)
+	}
+	filter.TeamID = chat.TeamID
+
+	role, err := b.storage.GetRole(ctx, chat.TeamID, chatID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return domain.TaskFilter{}, fmt.Errorf("b.storage.GetRole: %w", err)
+	}
+	switch role {
+	case domain.Chief:
+		filter.ManagerID = chatID
+	case domain.Executor:
+		filter.Executor = chat
+	}
+	return filter, nil
+}
+
+// filteredTasks returns visible tasks of the active team matching the query, the most important first.
+func (b *Bot) filteredTasks(ctx context.Context, chatID int64, query string) ([]domain.Task, error) {
+	filter, err := b.taskFilter(ctx, chatID, query)
+	if err != nil {
+		return nil, fmt.Errorf("b.taskFilter: %w", err)
+	}
+
+	tasks, err := b.storage.GetTasks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("b.storage.GetTasks: %w", err)
+	}
+	if err := b.fillTaskDetails(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("b.fillTaskDetails: %w", err)
+	}
+	domain.SortByPriority(tasks)
+	return tasks, nil
+}
+
+// sendFilteredTasks fills the response with tasks matching the query or returns false when the query is invalid.
+func (b *Bot) sendFilteredTasks(ctx context.Context, message *tgbotapi.Message, responseMsg *tgbotapi.MessageConfig, query string) bool {
+	tasks, err := b.filteredTasks(ctx, message.Chat.ID, query)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidInput) {
+			return false
+		}
+		b.logger.WithField("chatID", message.Chat.ID).WithError(err).Error("failed to get filtered tasks")
+		responseMsg.Text = errorReponse
+		return true
+	}
+	if len(tasks) == 0 {
+		responseMsg.Text = "Нет задач по фильтру"
+		return true
+	}
+
+	builder := strings.Builder{}
+	for _, task := range tasks {
+		builder.WriteString(task.String())
+		builder.WriteString("\n\n")
+	}
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	responseMsg.Text = builder.String()
+	return true
+}
+
+// handleTasksCommand lists tasks matching the filter: /tasks [#tag status priority my].
+func (b *Bot) handleTasksCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	if !b.sendFilteredTasks(ctx, message, &responseMsg, message.CommandArguments()) {
+		responseMsg.Text = tasksUsage
+	}
+}
+
+// handleViewCommand lists, runs and manages saved filters of the chat: /view [name|save name filter|delete name].
+func (b *Bot) handleViewCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		views, err := b.storage.GetViews(ctx, teamID, message.Chat.ID)
+		if err != nil {
+			logger.WithError(err).Error("failed to get views")
+			responseMsg.Text = errorReponse
+			return
+		}
+		if len(views) == 0 {
+			responseMsg.Text = "Сохранённых фильтров пока нет\n\n" + viewUsage
+			return
+		}
+		builder := strings.Builder{}
+		builder.WriteString("Сохранённые фильтры:\n")
+		for _, view := range views {
+			builder.WriteString(fmt.Sprintf("\n/%s %s - %s", viewCmd, view.Name, view.Query))
+		}
+		responseMsg.Text = builder.String()
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "save", "сохранить":
+		if len(args) < 3 {
+			responseMsg.Text = viewUsage
+			return
+		}
+		view := domain.SavedView{
+			TeamID: teamID,
+			ChatID: message.Chat.ID,
+			Name:   strings.ToLower(args[1]),
+			Query:  strings.Join(args[2:], " "),
+		}
+		if _, err := domain.ParseTaskFilter(view.Query, domain.Chat{ID: message.Chat.ID}); err != nil {
+			responseMsg.Text = viewUsage
+			return
+		}
+		if err := b.storage.SaveView(ctx, view); err != nil {
+			logger.WithError(err).Error("failed to save view")
+			responseMsg.Text = errorReponse
+			return
+		}
+		responseMsg.Text = fmt.Sprintf("Фильтр сохранён, задачи по нему: /%s %s", viewCmd, view.Name)
+
+	case "delete", "удалить":
+		if len(args) != 2 {
+			responseMsg.Text = viewUsage
+			return
+		}
+		name := strings.ToLower(args[1])
+		if err := b.storage.DeleteView(ctx, teamID, message.Chat.ID, name); err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				responseMsg.Text = fmt.Sprintf("Фильтр \"%s\" не найден", name)
+				return
+			}
+			logger.WithError(err).Error("failed to delete view")
+			responseMsg.Text = errorReponse
+			return
+		}
+		responseMsg.Text = fmt.Sprintf("Фильтр \"%s\" удалён", name)
+
+	default:
+		if len(args) != 1 {
+			responseMsg.Text = viewUsage
+			return
+		}
+		name := strings.ToLower(args[0])
+		view, err := b.storage.GetView(ctx, teamID, message.Chat.ID, name)
+		if err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				responseMsg.Text = fmt.Sprintf("Фильтр \"%s\" не найден, список фильтров: /%s", name, viewCmd)
+				return
+			}
+			logger.WithError(err).Error("failed to get view")
+			responseMsg.Text = errorReponse
+			return
+		}
+		if !b.sendFilteredTasks(ctx, message, &responseMsg, view.Query) {
+			responseMsg.Text = viewUsage
+		}
+	}
+}
+
+// handleSetTagsCommand replaces tags of the task: /set_tags task #tag ...
+func (b *Bot) handleSetTagsCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		responseMsg.Text = setTagsUsage
+		return
+	}
+	var tags []string
+	for _, arg := range args[1:] {
+		parsed := domain.ParseTags(arg)
+		if len(parsed) == 0 {
+			responseMsg.Text = fmt.Sprintf("Некорректный тег \"%s\"\n\n%s", arg, setTagsUsage)
+			return
+		}
+		if !slices.Contains(tags, parsed[0]) {
+			tags = append(tags, parsed[0])
+		}
+	}
+
+	task, text := b.findTask(ctx, logger, message.Chat.ID, args[0])
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	if err := b.storage.SetTaskTags(ctx, task.TeamID, task.ID, tags); err != nil {
+		logger.WithError(err).Error("failed to set task tags")
+		responseMsg.Text = errorReponse
+		return
+	}
+	b.taskChanged(ctx, task.TeamID)
+	if len(tags) == 0 {
+		responseMsg.Text = fmt.Sprintf("Теги задачи №%d удалены", task.ID)
+		return
+	}
+	responseMsg.Text = fmt.Sprintf("Теги задачи №%d: %s", task.ID, domain.FormatTags(tags))
+}
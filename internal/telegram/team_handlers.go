@@ -0,0 +1,152 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxTeamNameLength = 64
+
+// activeTeamID returns the team the chat works in. Chats unknown to the bot work in the default team.
+func (b *Bot) activeTeamID(ctx context.Context, chatID int64) (int64, error) {
+	chat, err := b.storage.GetChatByID(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.DefaultTeamID, nil
+		}
+		return 0, fmt.Errorf("b.storage.GetChatByID: %w", err)
+	}
+	return chat.TeamID, nil
+}
+
+// handleTeamCommand lists teams of the chat or switches active team: /team [name].
+func (b *Bot) handleTeamCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	memberships, err := b.storage.GetChatTeams(ctx, message.Chat.ID)
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get chat teams")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" {
+		responseMsg.Text = teamsText(memberships, teamID)
+		return
+	}
+
+	idx := -1
+	for i, membership := range memberships {
+		if membership.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		responseMsg.Text = fmt.Sprintf("Вы не состоите в команде \"%s\"", name)
+		return
+	}
+
+	membership := memberships[idx]
+	if err := b.storage.SetActiveTeam(ctx, message.Chat.ID, membership.ID); err != nil {
+		logger.WithError(err).Error("failed to set active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if err := b.setCommands(ctx, message.Chat.ID, membership.Role); err != nil {
+		logger.WithError(err).Error("failed to set commands")
+	}
+	responseMsg.Text = fmt.Sprintf("Вы перешли в команду <b>%s</b>. Ваша роль - %s", membership.Name, membership.Role)
+}
+
+// handleCreateTeamCommand creates new team with the creator as its admin: /create_team <name>.
+func (b *Bot) handleCreateTeamCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" || len([]rune(name)) > maxTeamNameLength {
+		responseMsg.Text = fmt.Sprintf("Использование: /%s название (не длиннее %d символов)", createTeamCmd, maxTeamNameLength)
+		return
+	}
+
+	if _, err := b.storage.GetTeamByName(ctx, name); err == nil {
+		responseMsg.Text = fmt.Sprintf("Команда \"%s\" уже существует", name)
+		return
+	} else if !errors.Is(err, errs.ErrNotFound) {
+		logger.WithError(err).Error("failed to get team")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	team, err := b.storage.AddTeam(ctx, name)
+	if err != nil {
+		logger.WithError(err).Error("failed to add team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if err := b.storage.SetRole(ctx, team.ID, message.Chat.ID, domain.Admin); err != nil {
+		logger.WithError(err).Error("failed to set role in the new team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if err := b.storage.SetActiveTeam(ctx, message.Chat.ID, team.ID); err != nil {
+		logger.WithError(err).Error("failed to set active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	if err := b.setCommands(ctx, message.Chat.ID, domain.Admin); err != nil {
+		logger.WithError(err).Error("failed to set commands")
+	}
+
+	responseMsg.Text = fmt.Sprintf(
+		"Команда <b>%s</b> создана, вы её администратор. Пригласите участников с помощью /%s",
+		team.Name, inviteCmd,
+	)
+}
+
+func teamsText(memberships []domain.TeamMembership, activeTeamID int64) string {
+	if len(memberships) == 0 {
+		return "Вы пока не состоите ни в одной команде"
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("<b>Ваши команды:</b>\n")
+	for _, membership := range memberships {
+		marker := ""
+		if membership.ID == activeTeamID {
+			marker = " ✅"
+		}
+		builder.WriteString(fmt.Sprintf("%s - %s%s\n", membership.Name, membership.Role, marker))
+	}
+	builder.WriteString(fmt.Sprintf("\nПерейти в другую команду: /%s название", teamCmd))
+	return builder.String()
+}
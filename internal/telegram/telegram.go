@@ -7,6 +7,7 @@ import (
 	"tasks_bot/internal/config"
 	"tasks_bot/internal/domain"
 	"tasks_bot/internal/repository"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	log "github.com/sirupsen/logrus"
@@ -25,20 +26,28 @@ var (
 )
 
 type Bot struct {
-	bot *tgbotapi.BotAPI
+	bot *botAPI
 
-	storage repository.Storage
-	cfg     *config.TelegramConfig
+	storage  repository.Storage
+	cfg      *config.TelegramConfig
+	commands *commandRegistry
+	doneMode domain.DoneMode
+	policy   domain.PriorityPolicy
+
+	// stopped is closed by Stop, it stops getting updates in both modes
+	stopped  chan struct{}
+	stopOnce sync.Once
 
 	logger *log.Entry
 }
 
 func NewBot(logger *log.Entry, storage repository.Storage, cfg *config.TelegramConfig) *Bot {
-	bot, err := tgbotapi.NewBotAPI(cfg.APIToken)
+	api, err := tgbotapi.NewBotAPI(cfg.APIToken)
 	if err != nil {
 		log.WithError(err).Fatal("can't create Bot API")
 	}
-	bot.Debug = cfg.Debug
+	api.Debug = cfg.Debug
+	bot := newBotAPI(api, newSendQueue(cfg.SendRate, cfg.ChatSendInterval))
 
 	if err := createAdminChat(storage, cfg); err != nil {
 		log.WithError(err).Warn("Failed to create admin chat. Entering no admin mode")
@@ -46,18 +55,49 @@ func NewBot(logger *log.Entry, storage repository.Storage, cfg *config.TelegramC
 	if err := setPasswords(cfg); err != nil {
 		log.WithError(err).Error("failed to set passwords")
 	}
+	commands, err := newCommandRegistry(defaultCommands(), cfg.CommandPermissions)
+	if err != nil {
+		log.WithError(err).Fatal("can't create commands registry")
+	}
+	doneMode, err := domain.ParseDoneMode(cfg.TaskDoneMode)
+	if err != nil {
+		log.WithError(err).Fatal("can't parse task done mode")
+	}
+	policy, err := domain.NewPriorityPolicy(cfg.PrioritySLA, cfg.ReminderIntervals, cfg.ExpiredDigestSchedule)
+	if err != nil {
+		log.WithError(err).Fatal("can't parse priority policy")
+	}
 
 	return &Bot{
-		bot:     bot,
-		storage: storage,
-		logger:  log.WithField("type", "telegram-bot"),
+		bot:      bot,
+		storage:  storage,
+		cfg:      cfg,
+		commands: commands,
+		doneMode: doneMode,
+		policy:   policy,
+		stopped:  make(chan struct{}),
+		logger:   log.WithField("type", "telegram-bot"),
 	}
 }
 
+// PriorityPolicy returns SLA, reminders and digest settings of task priorities.
+func (b *Bot) PriorityPolicy() domain.PriorityPolicy {
+	return b.policy
+}
+
+// DashboardInterval returns how often dashboards are refreshed besides task changes.
+func (b *Bot) DashboardInterval() time.Duration {
+	return b.cfg.DashboardInterval
+}
+
 func createAdminChat(db repository.Storage, cfg *config.TelegramConfig) error {
-	if err := db.AddChat(context.Background(), cfg.AdminID, cfg.AdminUsername, "-", domain.Admin); err != nil {
+	ctx := context.Background()
+	if err := db.AddChat(ctx, cfg.AdminID, cfg.AdminUsername, "-"); err != nil {
 		return fmt.Errorf("db.AddChat: %w", err)
 	}
+	if err := db.SetRole(ctx, domain.DefaultTeamID, cfg.AdminID, domain.Admin); err != nil {
+		return fmt.Errorf("db.SetRole: %w", err)
+	}
 	return nil
 }
 
@@ -82,18 +122,41 @@ func setPasswords(cfg *config.TelegramConfig) (err error) {
 }
 
 func (b *Bot) Start(ctx context.Context) error {
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = 60
+	// the queue is closed after the handlers in flight are finished
+	defer b.bot.queue.close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-b.stopped:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if b.cfg.WebhookURL != "" {
+		return b.startWebhook(ctx)
+	}
+
+	// getUpdates doesn't work while the webhook set in the webhook mode is registered
+	if _, err := b.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		b.logger.WithError(err).Warn("failed to delete webhook")
+	}
+	updates := make(chan tgbotapi.Update)
+	if err := b.pollUpdates(ctx, updates); err != nil {
+		return err
+	}
 
 	log.Info("Bot is handling updates")
-	b.handleUpdates(ctx, b.bot.GetUpdatesChan(updateConfig))
+	b.handleUpdates(ctx, updates)
 
 	return nil
 }
 
+// Stop stops getting updates, Start returns after the handlers in flight are finished and closes the send queue.
 func (b *Bot) Stop() {
 	log.Info("stopping bot")
-	b.bot.StopReceivingUpdates()
+	b.stopOnce.Do(func() { close(b.stopped) })
 }
 
 func (b *Bot) handleUpdates(ctx context.Context, updates tgbotapi.UpdatesChannel) {
@@ -119,9 +182,17 @@ func (b *Bot) handleUpdates(ctx context.Context, updates tgbotapi.UpdatesChannel
 			return
 
 		case update := <-updates:
+			if !b.claimUpdate(ctx, update) {
+				continue
+			}
+			if update.CallbackQuery != nil {
+				tasksCh <- func() { b.handleCallbackQuery(ctx, update.CallbackQuery) }
+				continue
+			}
 			if update.Message == nil {
 				continue
 			}
+			captionAsCommand(update.Message)
 
 			if update.Message.IsCommand() {
 				tasksCh <- func() { b.handleCommand(ctx, update.Message) }
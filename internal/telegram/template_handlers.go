@@ -0,0 +1,300 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tasks_bot/internal/domain"
+	"tasks_bot/internal/errs"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// template fields are written as "name:value", value lasts till the next field
+const (
+	templateField    = "tpl"
+	titleField       = "title"
+	executorField    = "executor"
+	deadlineField    = "deadline"
+	priorityField    = "priority"
+	descriptionField = "description"
+	checklistField   = "checklist"
+	proofField       = "proof"
+)
+
+var templateFieldAliases = map[string]string{
+	templateField:    templateField,
+	"шаблон":         templateField,
+	titleField:       titleField,
+	"название":       titleField,
+	executorField:    executorField,
+	"исполнитель":    executorField,
+	deadlineField:    deadlineField,
+	"срок":           deadlineField,
+	priorityField:    priorityField,
+	"приоритет":      priorityField,
+	descriptionField: descriptionField,
+	"описание":       descriptionField,
+	checklistField:   checklistField,
+	"чеклист":        checklistField,
+	proofField:       proofField,
+	"подтверждение":  proofField,
+}
+
+const templateFieldsHelp = `Поля:
+название:текст - можно использовать {date}, {week}, {month} и {year}
+исполнитель:@username
+срок:1d - дедлайн относительно создания задачи, без него берётся SLA приоритета
+приоритет:low|normal|high|critical или низкий|обычный|высокий|критический
+описание:текст
+чеклист:пункт; пункт
+подтверждение:да|нет - выполнение только с фото, документом, геопозицией или отчётом`
+
+const templatesUsage = `Использование:
+/templates - список шаблонов
+/templates add имя поле:значение ... - добавить или заменить шаблон, название обязательно
+/templates delete имя - удалить шаблон
+/add_task tpl:имя [поле:значение ...] - создать задачу по шаблону
+
+` + templateFieldsHelp
+
+// parseTemplateFields splits text like "tpl:report executor:@user title:Weekly report" into fields.
+func parseTemplateFields(raw string) (map[string]string, error) {
+	fields := make(map[string]string)
+	var current string
+	for _, word := range strings.Fields(raw) {
+		if name, value, ok := strings.Cut(word, ":"); ok {
+			if field, ok := templateFieldAliases[strings.ToLower(name)]; ok {
+				current = field
+				fields[current] = value
+				continue
+			}
+		}
+		if current == "" {
+			return nil, fmt.Errorf("text %q before the first field: %w", word, errs.ErrInvalidInput)
+		}
+		fields[current] = strings.TrimSpace(fields[current] + " " + word)
+	}
+	return fields, nil
+}
+
+func parseChecklist(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// handleAddTaskCommand starts task creation dialog or creates task from the template: /add_task [tpl:name field:value ...].
+func (b *Bot) handleAddTaskCommand(ctx context.Context, message *tgbotapi.Message) {
+	if strings.TrimSpace(message.CommandArguments()) == "" {
+		// files left from the previous unfinished dialog must not get into the new task
+		if err := b.storage.DeletePendingAttachments(ctx, message.Chat.ID); err != nil {
+			b.logger.WithField("chatID", message.Chat.ID).WithError(err).Error("failed to delete pending attachments")
+		}
+		b.setNextStageWithMessage(ctx, message, domain.AddTaskName, "Введите название задачи\n\nФото и документы, отправленные до ввода дедлайна, будут приложены к задаче")
+		return
+	}
+
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	fields, err := parseTemplateFields(message.CommandArguments())
+	if err != nil || fields[templateField] == "" {
+		responseMsg.Text = templatesUsage
+		return
+	}
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+	template, err := b.storage.GetTemplate(ctx, teamID, strings.ToLower(fields[templateField]))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			responseMsg.Text = fmt.Sprintf("Шаблон \"%s\" не найден, список шаблонов: /%s", fields[templateField], templatesCmd)
+			return
+		}
+		logger.WithError(err).Error("failed to get template")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	// fields given in the command override the template ones
+	now := time.Now()
+	template, text := applyTemplateFields(template, fields)
+	if text != "" {
+		responseMsg.Text = text
+		return
+	}
+	if template.ExecutorContact == "" {
+		responseMsg.Text = fmt.Sprintf("В шаблоне \"%s\" нет исполнителя, укажите его: /%s tpl:%s исполнитель:@username", template.Name, addTaskCmd, template.Name)
+		return
+	}
+	executorContact, executorChatID, err := b.findExecutor(ctx, template.ExecutorContact)
+	if err != nil {
+		logger.WithError(err).Error("failed to find executor")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	task := domain.Task{
+		TeamID:          teamID,
+		Title:           template.Title(now),
+		ExecutorContact: executorContact,
+		ExecutorChatID:  executorChatID,
+		Deadline:        b.policy.Deadline(template.Priority, now),
+		Description:     template.Description,
+		Priority:        template.Priority,
+		ProofRequired:   template.ProofRequired,
+	}
+	if template.DeadlineIn > 0 {
+		task.Deadline = now.Add(template.DeadlineIn)
+	}
+	if task, err = b.createTask(ctx, logger, task, template.Checklist, message.Chat.ID); err != nil {
+		logger.WithError(err).Error("failed to add task")
+		responseMsg.Text = errorReponse
+		return
+	}
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	responseMsg.Text = fmt.Sprintf("Вы успешно добавили задачу: \n\n%s", task)
+}
+
+// applyTemplateFields sets template fields from the parsed command or returns the text explaining what is wrong.
+func applyTemplateFields(template domain.TaskTemplate, fields map[string]string) (domain.TaskTemplate, string) {
+	if title, ok := fields[titleField]; ok {
+		template.TitlePattern = title
+	}
+	if executor, ok := fields[executorField]; ok {
+		template.ExecutorContact = strings.TrimPrefix(executor, "@")
+	}
+	if rawDeadline, ok := fields[deadlineField]; ok {
+		deadlineIn, err := parseTTL(rawDeadline)
+		if err != nil || deadlineIn == 0 {
+			return template, fmt.Sprintf("Некорректный срок \"%s\", укажите его как 4h или 2d", rawDeadline)
+		}
+		template.DeadlineIn = deadlineIn
+	}
+	if rawPriority, ok := fields[priorityField]; ok {
+		priority, err := domain.ParsePriority(rawPriority)
+		if err != nil {
+			return template, fmt.Sprintf("Некорректный приоритет \"%s\", укажите low, normal, high или critical", rawPriority)
+		}
+		template.Priority = priority
+	}
+	if description, ok := fields[descriptionField]; ok {
+		template.Description = description
+	}
+	if checklist, ok := fields[checklistField]; ok {
+		template.Checklist = parseChecklist(checklist)
+	}
+	if rawProof, ok := fields[proofField]; ok {
+		proofRequired, err := parseSwitch(rawProof)
+		if err != nil {
+			return template, fmt.Sprintf("Некорректное значение подтверждения \"%s\", укажите да или нет", rawProof)
+		}
+		template.ProofRequired = proofRequired
+	}
+	return template, ""
+}
+
+// handleTemplatesCommand lists and manages task templates: /templates [add|delete name ...].
+func (b *Bot) handleTemplatesCommand(ctx context.Context, message *tgbotapi.Message) {
+	logger := b.logger.WithField("chatID", message.Chat.ID)
+
+	responseMsg := tgbotapi.NewMessage(message.Chat.ID, "")
+	responseMsg.ParseMode = tgbotapi.ModeHTML
+	defer func() {
+		if _, err := b.bot.Send(responseMsg); err != nil {
+			logger.WithError(err).Error("failed to send response")
+		}
+	}()
+
+	teamID, err := b.activeTeamID(ctx, message.Chat.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get active team")
+		responseMsg.Text = errorReponse
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		templates, err := b.storage.GetTemplates(ctx, teamID)
+		if err != nil {
+			logger.WithError(err).Error("failed to get templates")
+			responseMsg.Text = errorReponse
+			return
+		}
+		if len(templates) == 0 {
+			responseMsg.ParseMode = ""
+			responseMsg.Text = "Шаблонов пока нет\n\n" + templatesUsage
+			return
+		}
+		texts := make([]string, 0, len(templates))
+		for _, template := range templates {
+			texts = append(texts, template.String())
+		}
+		responseMsg.Text = strings.Join(texts, "\n\n")
+		return
+	}
+
+	responseMsg.ParseMode = ""
+	if len(args) < 2 {
+		responseMsg.Text = templatesUsage
+		return
+	}
+	name := strings.ToLower(args[1])
+
+	switch strings.ToLower(args[0]) {
+	case "add", "добавить":
+		fields, err := parseTemplateFields(strings.Join(args[2:], " "))
+		if err != nil {
+			responseMsg.Text = templatesUsage
+			return
+		}
+		template, text := applyTemplateFields(domain.TaskTemplate{TeamID: teamID, Name: name, CreatedBy: message.Chat.ID}, fields)
+		if text != "" {
+			responseMsg.Text = text
+			return
+		}
+		// the deadline is optional since tasks get the SLA deadline of their priority
+		if template.TitlePattern == "" {
+			responseMsg.Text = "В шаблоне обязательно название\n\n" + templateFieldsHelp
+			return
+		}
+		if err := b.storage.SaveTemplate(ctx, template); err != nil {
+			logger.WithError(err).Error("failed to save template")
+			responseMsg.Text = errorReponse
+			return
+		}
+		responseMsg.ParseMode = tgbotapi.ModeHTML
+		responseMsg.Text = fmt.Sprintf("Шаблон сохранён, задача по нему: /%s tpl:%s\n\n%s", addTaskCmd, name, template)
+
+	case "delete", "удалить":
+		if err := b.storage.DeleteTemplate(ctx, teamID, name); err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				responseMsg.Text = fmt.Sprintf("Шаблон \"%s\" не найден", name)
+				return
+			}
+			logger.WithError(err).Error("failed to delete template")
+			responseMsg.Text = errorReponse
+			return
+		}
+		responseMsg.Text = fmt.Sprintf("Шаблон \"%s\" удалён", name)
+
+	default:
+		responseMsg.Text = templatesUsage
+	}
+}
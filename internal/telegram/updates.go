@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// pollTimeout is how long getUpdates waits for new updates, in seconds
+	pollTimeout = 60
+	// pollRetryDelay is the pause after the failed getUpdates
+	pollRetryDelay = 3 * time.Second
+)
+
+// pollUpdates gets updates starting after the last claimed one. The channel is unbuffered: the next getUpdates confirms
+// the received updates to Telegram, so it is called only after the previous updates are taken to be claimed.
+func (b *Bot) pollUpdates(ctx context.Context, updates chan<- tgbotapi.Update) error {
+	lastUpdateID, err := b.storage.GetLastUpdateID(ctx)
+	if err != nil {
+		return fmt.Errorf("b.storage.GetLastUpdateID: %w", err)
+	}
+	b.logger.WithField("offset", lastUpdateID+1).Info("resuming updates")
+
+	go func() {
+		offset := lastUpdateID + 1
+		for ctx.Err() == nil {
+			config := tgbotapi.NewUpdate(offset)
+			config.Timeout = pollTimeout
+			batch, err := b.bot.GetUpdates(config)
+			if err != nil {
+				b.logger.WithError(err).Warn("failed to get updates, retrying")
+				select {
+				case <-time.After(pollRetryDelay):
+				case <-ctx.Done():
+				}
+				continue
+			}
+			for _, update := range batch {
+				select {
+				case updates <- update:
+					offset = update.UpdateID + 1
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// claimUpdate reports whether the update should be handled. The update is claimed before it is handled,
+// so it is never handled twice, even when the bot stops in the middle of the handler or Telegram repeats the update.
+// The price is at most once delivery: the update whose handler was interrupted is lost.
+func (b *Bot) claimUpdate(ctx context.Context, update tgbotapi.Update) bool {
+	claimed, err := b.storage.ClaimUpdate(ctx, update.UpdateID)
+	if err != nil {
+		// the update is skipped rather than risk handling it twice
+		b.logger.WithError(err).WithField("updateID", update.UpdateID).Error("failed to claim update")
+		return false
+	}
+	if !claimed {
+		b.logger.WithField("updateID", update.UpdateID).Info("update is already handled")
+	}
+	return claimed
+}
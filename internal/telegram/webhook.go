@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// secretTokenHeader carries the secret token given to setWebhook in every webhook request
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+	// maxUpdateSize limits the body of the webhook request, updates are far smaller
+	maxUpdateSize = 1 << 20
+	// webhookShutdownTimeout is how long requests in flight are waited for on shutdown
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+// startWebhook runs the webhook server, registers the webhook and handles updates until the context is done.
+func (b *Bot) startWebhook(ctx context.Context) error {
+	if b.cfg.WebhookSecret == "" {
+		return errors.New("webhook secret is required in the webhook mode")
+	}
+	webhookURL, err := url.Parse(b.cfg.WebhookURL)
+	if err != nil {
+		return fmt.Errorf("url.Parse: %w", err)
+	}
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	updates := make(chan tgbotapi.Update, b.bot.Buffer)
+	mux := http.NewServeMux()
+	mux.Handle(path, webhookHandler(b.logger, b.cfg.WebhookSecret, updates))
+	server := &http.Server{
+		Addr:              b.cfg.WebhookListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	if err := b.setWebhook(webhookURL); err != nil {
+		shutdownWebhook(server)
+		return err
+	}
+
+	log.WithField("listen", b.cfg.WebhookListen).WithField("path", path).Info("Bot is handling updates from webhook")
+	handleCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// the bot can't get updates without the server, so it is stopped as well
+		if err := <-serverErr; err != nil {
+			b.logger.WithError(err).Error("webhook server failed")
+			cancel()
+		}
+	}()
+	b.handleUpdates(handleCtx, updates)
+
+	shutdownWebhook(server)
+	if ctx.Err() == nil {
+		return errors.New("webhook server stopped")
+	}
+	return nil
+}
+
+// setWebhook registers the webhook with the secret token, the library config has no field for the token.
+func (b *Bot) setWebhook(webhookURL *url.URL) error {
+	params := tgbotapi.Params{
+		"url":          webhookURL.String(),
+		"secret_token": b.cfg.WebhookSecret,
+	}
+	if _, err := b.bot.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("b.bot.MakeRequest (setWebhook): %w", err)
+	}
+	return nil
+}
+
+func shutdownWebhook(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.WithError(err).Warn("failed to shutdown webhook server")
+	}
+}
+
+// webhookHandler checks the secret token of the webhook request and passes the update to the channel.
+// Telegram repeats requests answered with an error, so only malformed requests are rejected.
+func webhookHandler(logger *log.Entry, secret string, updates chan<- tgbotapi.Update) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(secret)) != 1 {
+			logger.WithField("remote", r.RemoteAddr).Warn("webhook request with wrong secret token")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+			logger.WithError(err).Warn("failed to decode webhook update")
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+			// Telegram sends the update again later
+			http.Error(w, "update is not accepted", http.StatusServiceUnavailable)
+		}
+	}
+}
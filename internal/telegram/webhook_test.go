@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const testWebhookSecret = "secret-token"
+
+func TestWebhookHandler(t *testing.T) {
+	update := `{"update_id":42,"message":{"message_id":7,"date":1700000000,"chat":{"id":100,"type":"private"},"text":"/tasks","entities":[{"type":"bot_command","offset":0,"length":6}]}}`
+
+	tests := []struct {
+		name     string
+		method   string
+		secret   string
+		body     string
+		status   int
+		accepted bool
+	}{
+		{name: "valid update", method: http.MethodPost, secret: testWebhookSecret, body: update, status: http.StatusOK, accepted: true},
+		{name: "wrong secret", method: http.MethodPost, secret: "wrong", body: update, status: http.StatusUnauthorized},
+		{name: "missing secret", method: http.MethodPost, body: update, status: http.StatusUnauthorized},
+		{name: "not post", method: http.MethodGet, secret: testWebhookSecret, status: http.StatusMethodNotAllowed},
+		{name: "malformed update", method: http.MethodPost, secret: testWebhookSecret, body: `{"update_id":`, status: http.StatusBadRequest},
+	}
+
+	logger := log.NewEntry(log.New())
+	logger.Logger.SetOutput(io.Discard)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updates := make(chan tgbotapi.Update, 1)
+			server := httptest.NewServer(webhookHandler(logger, testWebhookSecret, updates))
+			defer server.Close()
+
+			request, err := http.NewRequest(test.method, server.URL, strings.NewReader(test.body))
+			if err != nil {
+				t.Fatalf("http.NewRequest: %v", err)
+			}
+			if test.secret != "" {
+				request.Header.Set(secretTokenHeader, test.secret)
+			}
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("server.Client().Do: %v", err)
+			}
+			response.Body.Close()
+
+			if response.StatusCode != test.status {
+				t.Errorf("status = %d, want %d", response.StatusCode, test.status)
+			}
+
+			select {
+			case got := <-updates:
+				if !test.accepted {
+					t.Fatalf("update %d is accepted, want rejected", got.UpdateID)
+				}
+				if got.UpdateID != 42 || got.Message == nil || got.Message.Chat.ID != 100 || got.Message.Text != "/tasks" {
+					t.Errorf("update = %+v, want update 42 with message /tasks from chat 100", got)
+				}
+				if !got.Message.IsCommand() || got.Message.Command() != "tasks" {
+					t.Errorf("message is not command /tasks")
+				}
+			default:
+				if test.accepted {
+					t.Fatalf("update is not accepted")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+-- Schema for invites table
+CREATE TABLE IF NOT EXISTS invites (
+    code TEXT PRIMARY KEY,
+    role INT NOT NULL,
+    username TEXT,
+    created_by BIGINT NOT NULL,
+    redeemed_by BIGINT,
+    redeemed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
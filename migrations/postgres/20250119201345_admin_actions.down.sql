@@ -0,0 +1,5 @@
+-- Drop admin_actions table
+DROP TABLE IF EXISTS admin_actions;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS last_activity_at;
+ALTER TABLE chats DROP COLUMN IF EXISTS banned;
@@ -0,0 +1,2 @@
+-- Drop password_attempts table
+DROP TABLE IF EXISTS password_attempts;
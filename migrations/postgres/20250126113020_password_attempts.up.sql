@@ -0,0 +1,8 @@
+-- Schema for password_attempts table
+CREATE TABLE IF NOT EXISTS password_attempts (
+    chat_id BIGINT PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    lockouts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- Roles from the default team become global again
+UPDATE chats c SET role = m.role FROM team_members m WHERE m.chat_id = c.chat_id AND m.team_id = 1;
+
+-- A chat banned in any team becomes banned globally
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS banned BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE chats SET banned = TRUE WHERE chat_id IN (SELECT chat_id FROM team_members WHERE banned);
+
+ALTER TABLE admin_actions DROP COLUMN IF EXISTS team_id;
+ALTER TABLE invites DROP COLUMN IF EXISTS team_id;
+DROP INDEX IF EXISTS tasks_team_id_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS team_id;
+ALTER TABLE chats DROP COLUMN IF EXISTS team_id;
+
+-- Drop team tables
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
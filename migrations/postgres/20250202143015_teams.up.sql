@@ -0,0 +1,35 @@
+-- Schema for teams table
+CREATE TABLE IF NOT EXISTS teams (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Everything created before teams belongs to the default team
+INSERT INTO teams (id, name) VALUES (1, 'default') ON CONFLICT DO NOTHING;
+SELECT setval('teams_id_seq', (SELECT MAX(id) FROM teams));
+
+-- Schema for team_members table
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id BIGINT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    role INT NOT NULL DEFAULT 0,
+    banned BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, chat_id)
+);
+
+-- Bans were global before teams, they are kept per team now
+INSERT INTO team_members (team_id, chat_id, role, banned)
+SELECT 1, chat_id, COALESCE(role, 0), banned FROM chats
+ON CONFLICT DO NOTHING;
+ALTER TABLE chats DROP COLUMN IF EXISTS banned;
+
+-- Active team of the chat
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS team_id BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS team_id BIGINT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS tasks_team_id_idx ON tasks (team_id);
+
+ALTER TABLE invites ADD COLUMN IF NOT EXISTS team_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE admin_actions ADD COLUMN IF NOT EXISTS team_id BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS team_members_manager_id_idx;
+ALTER TABLE team_members DROP COLUMN IF EXISTS manager_id;
@@ -0,0 +1,3 @@
+-- Chief responsible for the executor within the team
+ALTER TABLE team_members ADD COLUMN IF NOT EXISTS manager_id BIGINT;
+CREATE INDEX IF NOT EXISTS team_members_manager_id_idx ON team_members (team_id, manager_id);
@@ -0,0 +1,11 @@
+-- Schema for task_participants table, role 1 is executor and 2 is watcher
+CREATE TABLE IF NOT EXISTS task_participants (
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    role INT NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, chat_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_participants_chat_id_idx ON task_participants (chat_id);
@@ -0,0 +1,15 @@
+-- Schema for recurrences table
+CREATE TABLE IF NOT EXISTS recurrences (
+    id BIGSERIAL PRIMARY KEY,
+    team_id BIGINT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    executor_contact TEXT NOT NULL,
+    executor_chat_id BIGINT,
+    schedule TEXT NOT NULL,
+    deadline_in BIGINT NOT NULL,
+    next_run TIMESTAMP NOT NULL,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS recurrences_next_run_idx ON recurrences (next_run) WHERE NOT paused;
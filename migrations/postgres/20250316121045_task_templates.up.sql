@@ -0,0 +1,16 @@
+-- Tasks may have a description, e.g. taken from their template
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+
+-- Schema for task_templates table
+CREATE TABLE IF NOT EXISTS task_templates (
+    team_id BIGINT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    title_pattern TEXT NOT NULL,
+    executor_contact TEXT NOT NULL DEFAULT '',
+    deadline_in BIGINT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    checklist TEXT[] NOT NULL DEFAULT '{}',
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, name)
+);
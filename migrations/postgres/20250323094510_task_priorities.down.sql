@@ -0,0 +1,6 @@
+ALTER TABLE task_templates DROP COLUMN IF EXISTS priority;
+
+ALTER TABLE tasks_in_progress DROP COLUMN IF EXISTS priority;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS next_reminder_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
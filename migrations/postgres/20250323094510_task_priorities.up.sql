@@ -0,0 +1,8 @@
+-- Priority of the task: -1 low, 0 normal, 1 high, 2 critical
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+-- Executor is reminded about the open task when the time comes, NULL until the first reminder is scheduled
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS next_reminder_at TIMESTAMP;
+
+ALTER TABLE tasks_in_progress ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE task_templates ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS saved_views;
+
+DROP TABLE IF EXISTS task_tags;
+
+DROP TABLE IF EXISTS tags;
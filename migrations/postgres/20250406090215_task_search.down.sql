@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tasks_search_idx;
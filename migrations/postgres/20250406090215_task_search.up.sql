@@ -0,0 +1,2 @@
+-- Full-text index of tasks, SearchTasks uses the same expression to hit it
+CREATE INDEX IF NOT EXISTS tasks_search_idx ON tasks USING GIN (to_tsvector('russian', title || ' ' || description));
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS task_messages;
+
+DROP TABLE IF EXISTS task_comments;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS created_by;
@@ -0,0 +1,24 @@
+-- Chat which created the task, NULL for tasks created before it was stored
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS created_by BIGINT;
+
+-- Schema for task_comments table
+CREATE TABLE IF NOT EXISTS task_comments (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_comments_task_id_idx ON task_comments (task_id);
+-- comments are searched along with tasks
+CREATE INDEX IF NOT EXISTS task_comments_search_idx ON task_comments USING GIN (to_tsvector('russian', text));
+
+-- Schema for task_messages table, outgoing bot messages about tasks, replies to them are matched back to the task
+CREATE TABLE IF NOT EXISTS task_messages (
+    chat_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL,
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, message_id)
+);
@@ -0,0 +1,16 @@
+-- Schema for task_attachments table, task_id is NULL while the task is being created
+CREATE TABLE IF NOT EXISTS task_attachments (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT REFERENCES tasks (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    kind INTEGER NOT NULL,
+    file_id TEXT NOT NULL,
+    file_unique_id TEXT NOT NULL DEFAULT '',
+    file_name TEXT NOT NULL DEFAULT '',
+    mime_type TEXT NOT NULL DEFAULT '',
+    file_size BIGINT NOT NULL DEFAULT 0,
+    local_path TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_attachments_task_id_idx ON task_attachments (task_id);
+CREATE INDEX IF NOT EXISTS task_attachments_pending_idx ON task_attachments (chat_id) WHERE task_id IS NULL;
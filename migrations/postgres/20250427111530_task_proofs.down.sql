@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS task_proofs;
+
+ALTER TABLE task_templates DROP COLUMN IF EXISTS proof_required;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS proof_required;
@@ -0,0 +1,18 @@
+-- Tasks which are done only after the executor sends a proof of completion
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS proof_required BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE task_templates ADD COLUMN IF NOT EXISTS proof_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Schema for task_proofs table, proofs of completion kept for audit
+CREATE TABLE IF NOT EXISTS task_proofs (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    kind INTEGER NOT NULL,
+    file_id TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION NOT NULL DEFAULT 0,
+    longitude DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_proofs_task_id_idx ON task_proofs (task_id);
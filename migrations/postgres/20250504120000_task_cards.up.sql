@@ -0,0 +1,8 @@
+-- Schema for task_cards table, the last message with the task card in each chat, it is edited when the task changes
+CREATE TABLE IF NOT EXISTS task_cards (
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, chat_id)
+);
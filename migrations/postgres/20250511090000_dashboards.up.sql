@@ -0,0 +1,7 @@
+-- Schema for dashboards table, pinned messages with the summary of team tasks, one per chat
+CREATE TABLE IF NOT EXISTS dashboards (
+    chat_id BIGINT PRIMARY KEY,
+    team_id BIGINT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    message_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+-- Schema for inactive_chats table, chats which blocked the bot or were deleted are not notified until /start
+CREATE TABLE IF NOT EXISTS inactive_chats (
+    chat_id BIGINT PRIMARY KEY,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
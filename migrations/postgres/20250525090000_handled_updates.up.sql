@@ -0,0 +1,5 @@
+-- Schema for handled_updates table, claimed telegram updates are not handled again, the last one is the polling offset
+CREATE TABLE IF NOT EXISTS handled_updates (
+    update_id BIGINT PRIMARY KEY,
+    handled_at TIMESTAMP NOT NULL
+);